@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"flag"
 	"fmt"
@@ -23,6 +24,13 @@ var (
 	dataSourceName = flags.String("dsn", "", "full data source name")
 	configPath     = flags.String("config", "", "path to gomigrate config file")
 	sqlDialect     = flags.String("d", "", "your db sql dialect")
+	environment    = flags.String("env", "", "environment name used to select protection policy")
+	schemaFile     = flags.String("schema", "", "path to schema dump file (default schema.sql)")
+	schemaAutoDump = flags.Bool("auto-dump", false, "dump schema to schema file after up/down/redo")
+	seedsPath      = flags.String("seeds", "", "the directory containing seeds (default seeds)")
+	metricsFile    = flags.String("metrics-file", "", "write run metrics to node_exporter textfile")
+	metricsPushURL = flags.String("metrics-push", "", "push run metrics to Pushgateway-compatible endpoint")
+	waitForDB      = flags.Duration("wait-for-db", 0, "retry database connection with backoff up to this duration, e.g. 60s")
 
 	help = flags.Bool("h", false, "print help")
 )
@@ -55,7 +63,8 @@ func main() {
 	if *configPath != "" {
 		appConfig, err = config.BuildFromFile(*configPath)
 		if err != nil {
-			log.Fatal(err)
+			log.Println(err)
+			os.Exit(int(exitcode.ConfigError))
 		}
 	} else {
 		appConfig = config.BuildFromArgs(
@@ -63,35 +72,56 @@ func main() {
 			*migrationTable,
 			*compact,
 			*sqlDialect,
-			*dataSourceName)
+			*dataSourceName,
+			*environment,
+			*schemaFile,
+			*schemaAutoDump,
+			*seedsPath,
+			*metricsFile,
+			*metricsPushURL)
 	}
 
+	// lint is static check for CI, so it does not need database
+	if args[0] == "lint" {
+		if err := gomigrate.Lint(appConfig, args[1:]); err != nil {
+			log.Printf("gomigrate error: %v\n", err)
+			os.Exit(int(errors.ErrorExitCode(err)))
+		}
+
+		os.Exit(int(exitcode.OK))
+	}
+
+	initTracing()
+
 	db, err := sql.Open(appConfig.SQLDialect, appConfig.DataSourceName)
 	if err != nil {
-		log.Fatalf("-dsn=%q: %v\n", appConfig.DataSourceName, err)
+		log.Printf("-dsn=%q: %v\n", appConfig.DataSourceName, err)
+		stopTracing()
+		os.Exit(int(exitcode.ConfigError))
 	}
 
-	err = db.Ping()
+	err = gomigrate.WaitForDB(context.Background(), db, *waitForDB)
 	if err != nil {
-		log.Fatalf("gomigrate: database ping err: %v\n", err)
+		log.Printf("gomigrate: database ping err: %v\n", err)
+		shutdown(db, errors.ErrorExitCode(err))
 	}
 
 	err = config.Validate(appConfig, db)
 	if err != nil {
 		log.Printf("%v\n", err)
-		shutdown(db, exitcode.Unspecified)
+		shutdown(db, errors.ErrorExitCode(err))
 	}
 
 	switch args[0] {
-	case "create":
-		if err := gomigrate.Run("create", nil, appConfig, args[1:]); err != nil {
+	case "check", "create", "up", "down", "dump", "fresh", "history", "new", "redo", "seed", "squash", "to", "mark", "wait":
+		if err := gomigrate.Run(args[0], db, appConfig, args[1:]); err != nil {
 			log.Printf("gomigrate error: %v\n", err)
 			shutdown(db, errors.ErrorExitCode(err))
 		}
 
 		shutdown(db, exitcode.OK)
-	case "up", "down", "fresh", "history", "new", "redo", "to", "mark":
-		if err := gomigrate.Run(args[0], db, appConfig, args[1:]); err != nil {
+	case "serve":
+		if err := gomigrate.Serve(db, appConfig, args[1:]); err != nil {
 			log.Printf("gomigrate error: %v\n", err)
 			shutdown(db, errors.ErrorExitCode(err))
 		}
@@ -103,6 +133,7 @@ func main() {
 }
 
 func shutdown(db io.Closer, exitCode exitcode.ExitCode) {
+	stopTracing()
 	db.Close()
 	os.Exit(int(exitCode))
 }
@@ -120,7 +151,9 @@ var usagePrefix = `Usage: gomigrate [OPTIONS] ACTION [ACTION PARAMS]
 
 var usageActions = `
 Actions:
-	create [name:string] [type:enum[sql|go,default:go]] [safe:bool,default:true] - Creates a new migration
+	check - Checks that database is in sync with migrations, exits with code 4 on pending, missing, dirty or changed repeatable migrations
+
+	create [name:string] [type:enum[sql|sql.tmpl|go,default:go]] [safe:bool,default:true] - Creates a new migration
 	  create add_new_table           #create new m000000_000000_add_new_table.go file (will be executed in transaction)
 	  create add_new_table go        #create new m000000_000000_add_new_table.go file (will be executed in transaction)
 	  create add_new_table go true   #create new m000000_000000_add_new_table.go file (will be executed in transaction)
@@ -128,19 +161,30 @@ Actions:
 	  create add_new_table sql       #create new m000000_000000_add_new_table.sql file (will be executed in transaction)
 	  create add_new_table sql true  #create new m000000_000000_add_new_table.sql file (will be executed in transaction)
 	  create add_new_table sql false #create new m000000_000000_add_new_table.sql file (will be executed without transaction)
+	  create add_new_table sql.tmpl  #create new m000000_000000_add_new_table.sql.tmpl file rendered with text/template before parsing
+	  create add_new_table --from-diff desired.sql #create new .sql migration with up/down statements turning current schema into desired.sql one
 
-	down [limit:int|all,default:1] - Downgrades the application by reverting old migrations
+	down [limit:int|all|--batch,default:1] [--atomic] - Downgrades the application by reverting old migrations
 	  down     #revert last applied migration
 	  down 3   #revert last 3 applied migrations
 	  down all #revert all applied migrations
+	  down 3 --atomic #revert last 3 applied migrations in a single transaction
+	  down --batch #revert migrations applied by the last up
+
+	dump [path:string,default:schema file] - Dumps the database schema into a deterministic sorted SQL file
+	  dump            #dump schema to the configured schema file (schema.sql by default)
+	  dump schema.sql #dump schema to schema.sql file
 
 	fresh - Truncates the whole database and starts the migration from the beginning
 
-	history [limit:int|all,default:10] - Displays the migration history
+	history [limit:int|all,default:10] - Displays the migration history grouped by batches
 	  history     #show last 10 applied versions
 	  history 3   #show last 3 applied versions
 	  history all #show all applied versions
 
+	lint - Checks SQL migrations for risky patterns (database connection is not required)
+	  lint #lint all migrations, exits with code 3 if errors found
+
 	mark [version:string] - Modifies the migration history to the specified version
 	  mark m000000_000000_add_new_table #modify migrations history to m000000_000000_add_new_table version
 
@@ -149,16 +193,37 @@ Actions:
 	  new 3   #show last 3 not applied migrations
 	  new all #show all not applied migrations
 
-	redo [limit:int|all,default:1] - Redoes the last few migrations
+	redo [limit:int|all|--batch,default:1] [--atomic] - Redoes the last few migrations
 	  redo     #redo last applied migration
 	  redo 3   #redo last 3 applied migrations
 	  redo all #redo all applied migrations
+	  redo 3 --atomic #redo last 3 applied migrations in a single transaction
+	  redo --batch #redo migrations applied by the last up
+
+	serve [addr:string,default::8080] - Runs HTTP server: GET /status, GET /healthz and POST /up streaming progress as server-sent events
+	  serve       #listen on :8080, POST /up requires "Authorization: Bearer <token>" header with gomigrate_serve_token or GOMIGRATE_SERVE_TOKEN
+	  serve :9000 #listen on :9000
 
-	to [version:string] - Upgrades or downgrades till the specified version
+	seed [--reset] - Applies new seeds from the seeds directory and its environment subdirectory
+	  seed         #apply not applied seeds
+	  seed --reset #revert all applied seeds and apply them again (for dev databases)
+
+	squash [version:string] [archive_path:string] - Replaces all migrations till the specified version with a single baseline migration
+	  squash m000000_000000_add_new_table         #replace migrations with schema dump, squashed files are removed
+	  squash m000000_000000_add_new_table archive #replace migrations with schema dump, squashed files are moved to archive dir
+
+	to [version:string] [--atomic] - Upgrades or downgrades till the specified version
 	  to m000000_000000_add_new_table #apply\revert all migrations to m000000_000000_add_new_table version
+	  to m000000_000000_add_new_table --atomic #apply\revert all migrations to m000000_000000_add_new_table version in a single transaction
 
-	up [limit:int,default:0] - Upgrades the application by applying new migrations
+	up [limit:int,default:0] [--atomic] - Upgrades the application by applying new migrations
 	  up   #apply all new migrations
 	  up 3 #apply the first 3 new migrations
+	  up --atomic #apply all new migrations in a single transaction, nothing is applied if one of them fails
+
+	wait [version:string|latest,default:latest] [--timeout duration,default:0(no limit)] - Waits until the database reaches the specified version (polls migrations table)
+	  wait                               #wait until all migrations from migrations path are applied
+	  wait m000000_000000_add_new_table  #wait until m000000_000000_add_new_table version is applied
+	  wait latest --timeout 60s          #exit with code 8 after 60s, or with code 9 if dirty version is left
 
 `
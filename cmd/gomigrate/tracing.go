@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/tweety53/gomigrate/pkg/gomigrate"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+const tracingShutdownTimeout = 5 * time.Second
+
+// stopTracing flushes spans before exit.
+var stopTracing = func() {}
+
+// initTracing exports spans with OTLP over HTTP if OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set, the rest of OTEL_EXPORTER_OTLP_* variables
+// are read by exporter and OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES by resource.
+func initTracing() {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return
+	}
+
+	exporter, err := otlptracehttp.New(context.Background())
+	if err != nil {
+		log.Printf("gomigrate: tracing disabled, cannot create OTLP exporter: %v\n", err)
+
+		return
+	}
+
+	res, err := resource.Merge(
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String("gomigrate")),
+		resource.Environment())
+	if err != nil {
+		log.Printf("gomigrate: tracing disabled, bad OTEL_RESOURCE_ATTRIBUTES: %v\n", err)
+
+		return
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	gomigrate.SetTracerProvider(tp)
+
+	stopTracing = func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+
+		if err := tp.Shutdown(ctx); err != nil {
+			log.Printf("gomigrate: cannot flush spans: %v\n", err)
+		}
+	}
+}
@@ -1,10 +1,90 @@
 package action
 
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/service"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+const batchOption = "--batch"
+
+var ErrBatchWithLimit = errors.New("--batch option cannot be used with limit")
+
 type Action interface {
-	Run(params interface{}) error
+	Run(ctx context.Context, params interface{}) error
 }
 
 type Params interface {
 	ValidateAndFill(args []string) error
 	Get() interface{}
 }
+
+// cancelled is returned when user has not confirmed the action.
+func cancelled() error {
+	log.Info("Action was cancelled by user. Nothing has been performed.\n")
+
+	return &errorsInternal.GoMigrateError{Err: errorsInternal.ErrCancelled, ExitCode: exitcode.UserCancelled}
+}
+
+// versionNotFound is returned when requested version is neither applied nor collected.
+func versionNotFound() error {
+	return &errorsInternal.GoMigrateError{Err: ErrUnableToFindVersion, ExitCode: exitcode.VersionNotFound}
+}
+
+// migrationFailed marks err with MigrationFailed exit code unless it already has more specific one.
+func migrationFailed(err error) error {
+	if err == nil || errorsInternal.ErrorExitCode(err) != exitcode.Unspecified {
+		return err
+	}
+
+	return &errorsInternal.GoMigrateError{Err: err, ExitCode: exitcode.MigrationFailed}
+}
+
+// extractFlag removes flag from args and reports whether it was passed.
+func extractFlag(args []string, flag string) ([]string, bool) {
+	var (
+		rest  []string
+		found bool
+	)
+
+	for _, arg := range args {
+		if arg == flag {
+			found = true
+
+			continue
+		}
+
+		rest = append(rest, arg)
+	}
+
+	return rest, found
+}
+
+// historyRecords returns limit last history records or records of the last batch.
+func historyRecords(svc *service.MigrationService, limit int, batch bool) (repo.MigrationRecords, error) {
+	if batch {
+		return svc.GetLastBatch()
+	}
+
+	return svc.MigrationsRepo.GetMigrationsHistory(limit)
+}
+
+// setBatch sets batch number of the next up invocation to migrations.
+func setBatch(svc *service.MigrationService, migrations migration.Migrations) error {
+	batch, err := svc.NextBatch()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		m.Batch = batch
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package action
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/service"
+)
+
+const atomicOption = "--atomic"
+
+// extractAtomicOption removes --atomic option from args and reports whether it was passed.
+func extractAtomicOption(args []string) ([]string, bool) {
+	return extractFlag(args, atomicOption)
+}
+
+// runAtomic reverts down and applies up migrations in a single transaction.
+func runAtomic(ctx context.Context, svc *service.MigrationService, down, up migration.Migrations) error {
+	r, ok := svc.MigrationsRepo.(*repo.MigrationsRepository)
+	if !ok {
+		return errors.New("MigrationRepo type assertion err")
+	}
+
+	return migration.RunAtomic(ctx, r, svc.Hooks, svc.Observers, down, up)
+}
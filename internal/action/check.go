@@ -0,0 +1,111 @@
+package action
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/helpers"
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/service"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+var ErrCheckFailed = errors.New("database is not in sync with migrations")
+
+// CheckAction verifies that database is in sync with migration files without changing anything.
+// Versioned migrations checksums are not stored, so only repeatable migrations may be reported as modified.
+type CheckAction struct {
+	svc *service.MigrationService
+}
+
+func NewCheckAction(migrationsSvc *service.MigrationService) *CheckAction {
+	return &CheckAction{svc: migrationsSvc}
+}
+
+type CheckActionParams struct{}
+
+func (p *CheckActionParams) ValidateAndFill(_ []string) error {
+	return nil
+}
+
+func (p *CheckActionParams) Get() interface{} {
+	return &CheckActionParams{}
+}
+
+func (a *CheckAction) Run(_ context.Context, params interface{}) error {
+	if _, ok := params.(*CheckActionParams); !ok {
+		return errorsInternal.ErrInvalidActionParamsType
+	}
+
+	pending, err := a.svc.GetPendingMigrations()
+	if err != nil {
+		return err
+	}
+
+	missing, err := a.svc.GetUnknownAppliedVersions()
+	if err != nil {
+		return err
+	}
+
+	dirty, err := a.dirtyVersions()
+	if err != nil {
+		return err
+	}
+
+	modified, err := a.svc.GetChangedRepeatableMigrations()
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		log.Warnf("Total %d new %s to be applied:\n", len(pending), helpers.ChooseLogText(len(pending), true))
+		log.Infof("%s", pending)
+	}
+
+	logVersions("applied but missing in migrations path", missing)
+	logVersions("dirty, not finished or crashed", dirty)
+
+	if len(modified) > 0 {
+		log.Warnf("Total %d changed repeatable %s to be applied:\n",
+			len(modified), helpers.ChooseLogText(len(modified), true))
+		log.Infof("%s", modified)
+	}
+
+	if len(pending)+len(missing)+len(dirty)+len(modified) > 0 {
+		return &errorsInternal.GoMigrateError{Err: ErrCheckFailed, ExitCode: exitcode.CheckFailed}
+	}
+
+	log.Info("Database is in sync with migrations.\n")
+
+	return nil
+}
+
+// dirtyVersions returns versions inserted by migration which has not finished yet or crashed.
+func (a *CheckAction) dirtyVersions() ([]string, error) {
+	records, err := a.svc.MigrationsRepo.GetMigrationsHistory(0)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get migrations history from db")
+	}
+
+	var dirty []string
+	for _, record := range records {
+		if record.Version != migration.BaseMigrationVersion && record.ApplyTime == 0 {
+			dirty = append(dirty, record.Version)
+		}
+	}
+
+	return dirty, nil
+}
+
+func logVersions(problem string, versions []string) {
+	if len(versions) == 0 {
+		return
+	}
+
+	log.Warnf("Total %d %s %s:\n", len(versions), helpers.ChooseLogText(len(versions), true), problem)
+	for _, v := range versions {
+		log.Infof("\t%s\n", v)
+	}
+}
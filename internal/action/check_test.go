@@ -0,0 +1,156 @@
+package action
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gojuno/minimock/v3"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/service"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+func TestCheckAction_Run(t *testing.T) {
+	svc := func(records repo.MigrationRecords, migrations migration.Migrations) *service.MigrationService {
+		mc := minimock.NewController(t)
+
+		return &service.MigrationService{
+			MigrationsRepo: repo.NewMigrationRepoMock(mc).
+				GetMigrationsHistoryMock.Return(records, nil),
+			MigrationsCollector: migration.NewMigrationsCollectorInterfaceMock(mc).
+				CollectMigrationsMock.Return(migrations, nil),
+		}
+	}
+	migrations := migration.Migrations{
+		&migration.Migration{Version: "m200101_000000_test"},
+		&migration.Migration{Version: "m200101_000001_test"},
+	}
+
+	type fields struct {
+		svc *service.MigrationService
+	}
+	type args struct {
+		params interface{}
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		args         args
+		wantErr      error
+		wantExitCode exitcode.ExitCode
+	}{
+		{
+			name:         "invalid action params type passed",
+			fields:       fields{},
+			args:         args{params: struct{}{}},
+			wantErr:      errorsInternal.ErrInvalidActionParamsType,
+			wantExitCode: exitcode.Unspecified,
+		},
+		{
+			name: "in sync",
+			fields: fields{svc: svc(repo.MigrationRecords{
+				&repo.MigrationRecord{Version: "m200101_000001_test", ApplyTime: 2},
+				&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+				&repo.MigrationRecord{Version: migration.BaseMigrationVersion, ApplyTime: 1},
+			}, migrations)},
+			args:         args{params: &CheckActionParams{}},
+			wantExitCode: exitcode.OK,
+		},
+		{
+			name: "pending migration",
+			fields: fields{svc: svc(repo.MigrationRecords{
+				&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+			}, migrations)},
+			args:         args{params: &CheckActionParams{}},
+			wantErr:      ErrCheckFailed,
+			wantExitCode: exitcode.CheckFailed,
+		},
+		{
+			name: "missing migration file",
+			fields: fields{svc: svc(repo.MigrationRecords{
+				&repo.MigrationRecord{Version: "m200101_000002_test", ApplyTime: 3},
+				&repo.MigrationRecord{Version: "m200101_000001_test", ApplyTime: 2},
+				&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+			}, migrations)},
+			args:         args{params: &CheckActionParams{}},
+			wantErr:      ErrCheckFailed,
+			wantExitCode: exitcode.CheckFailed,
+		},
+		{
+			name: "dirty migration",
+			fields: fields{svc: svc(repo.MigrationRecords{
+				&repo.MigrationRecord{Version: "m200101_000001_test", ApplyTime: 0},
+				&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+			}, migrations)},
+			args:         args{params: &CheckActionParams{}},
+			wantErr:      ErrCheckFailed,
+			wantExitCode: exitcode.CheckFailed,
+		},
+		{
+			name: "squashed versions are known",
+			fields: fields{svc: svc(repo.MigrationRecords{
+				&repo.MigrationRecord{Version: "m200101_000001_test", ApplyTime: 2},
+				&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+			}, migration.Migrations{
+				&migration.Migration{
+					Version:  "m200101_000001_baseline",
+					Squashed: []string{"m200101_000000_test", "m200101_000001_test"},
+				},
+			})},
+			args:         args{params: &CheckActionParams{}},
+			wantExitCode: exitcode.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &CheckAction{
+				svc: tt.fields.svc,
+			}
+			err := a.Run(context.Background(), tt.args.params)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.wantExitCode, errorsInternal.ErrorExitCode(err))
+		})
+	}
+}
+
+func TestCheckAction_Run_ReadOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	dialect, err := sqldialect.InitDialect("postgres", "migration")
+	require.NoError(t, err)
+
+	mc := minimock.NewController(t)
+	svc := &service.MigrationService{
+		DB:             db,
+		MigrationsRepo: repo.NewMigrationsRepository(db, dialect),
+		RepeatableRepo: repo.NewRepeatableMigrationsRepository(db, dialect),
+		MigrationsCollector: migration.NewMigrationsCollectorInterfaceMock(mc).
+			CollectMigrationsMock.Return(migration.Migrations{&migration.Migration{Version: "m200101_000000_test"}}, nil).
+			CollectRepeatableMigrationsMock.Return(migration.RepeatableMigrations{
+			&migration.RepeatableMigration{Name: "r_views", Checksum: "checksum"},
+		}, nil),
+	}
+
+	// any statement which is not expected fails the run, so CREATE statements are not executed
+	history := regexp.QuoteMeta("SELECT version, COALESCE(apply_time, 0) FROM migration")
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"version", "apply_time"}).AddRow("m200101_000000_test", 1)
+	}
+	mock.ExpectQuery(history).WillReturnRows(rows())
+	mock.ExpectQuery(history).WillReturnRows(rows())
+	mock.ExpectQuery(history).WillReturnRows(rows())
+	// repeatable migrations table is not created before the first repeatable migration
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, checksum FROM migration_repeatable;")).
+		WillReturnError(&pq.Error{Code: "42P01"})
+
+	err = NewCheckAction(svc).Run(context.Background(), &CheckActionParams{})
+	require.ErrorIs(t, err, ErrCheckFailed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
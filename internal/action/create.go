@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -21,27 +22,43 @@ var (
 	ErrEmptyName             = errors.New("name cannot be empty")
 	ErrUnknownMigrationType  = errors.New("unknown migration type passed")
 	ErrUnknownSafeParamValue = errors.New("create action 'safe' param must be true or false")
+	ErrFromDiffNotSQL        = errors.New("migration from diff can be created with sql type only")
+	ErrFromDiffNoPath        = errors.New("--from-diff option requires desired schema file path")
+	ErrSchemaDifferNotSet    = errors.New("schema differ not initialized")
 )
 
+const fromDiffOption = "--from-diff"
+
 type tmplVars struct {
-	CamelName string
+	CamelName      string
+	NoTransaction  bool
+	UpStatements   []string
+	DownStatements []string
+}
+
+// SchemaDiffer builds statements turning current db schema into the desired one.
+type SchemaDiffer interface {
+	DiffSchema(desiredPath string) (up []string, down []string, err error)
 }
 
 type CreateAction struct {
 	migrationsPath string
+	differ         SchemaDiffer
 }
 
 type CreateActionParams struct {
-	name  string
-	mType migration.Type
-	safe  bool
+	name     string
+	mType    migration.Type
+	safe     bool
+	fromDiff string
 }
 
 func (p *CreateActionParams) Get() interface{} {
 	return &CreateActionParams{
-		name:  p.name,
-		mType: p.mType,
-		safe:  p.safe,
+		name:     p.name,
+		mType:    p.mType,
+		safe:     p.safe,
+		fromDiff: p.fromDiff,
 	}
 }
 
@@ -49,6 +66,11 @@ func (p *CreateActionParams) Get() interface{} {
 var migrationNameRegex = regexp.MustCompile(`^[\w\\]+$`)
 
 func (p *CreateActionParams) ValidateAndFill(args []string) error {
+	args, fromDiff, err := extractFromDiffOption(args)
+	if err != nil {
+		return err
+	}
+
 	if len(args) < 1 {
 		return errorsInternal.ErrNotEnoughArgs
 	}
@@ -64,13 +86,20 @@ func (p *CreateActionParams) ValidateAndFill(args []string) error {
 	var mType migration.Type
 	if len(args) == 1 {
 		mType = migration.TypeGo
+		if fromDiff != "" {
+			mType = migration.TypeSQL
+		}
 	} else {
 		mType = migration.Type(args[1])
-		if mType != migration.TypeGo && mType != migration.TypeSQL {
+		if mType != migration.TypeGo && mType != migration.TypeSQL && mType != migration.TypeSQLTemplate {
 			return ErrUnknownMigrationType
 		}
 	}
 
+	if fromDiff != "" && mType != migration.TypeSQL {
+		return ErrFromDiffNotSQL
+	}
+
 	var safe bool
 	if len(args) <= 2 {
 		safe = true
@@ -88,22 +117,56 @@ func (p *CreateActionParams) ValidateAndFill(args []string) error {
 	p.name = name
 	p.mType = mType
 	p.safe = safe
+	p.fromDiff = fromDiff
 
 	return nil
 }
 
-func NewCreateAction(migrationsPath string) *CreateAction {
+// extractFromDiffOption cuts `--from-diff path` (or `--from-diff=path`) out of args.
+func extractFromDiffOption(args []string) ([]string, string, error) {
+	var (
+		rest     = make([]string, 0, len(args))
+		fromDiff string
+	)
+
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == fromDiffOption:
+			if i+1 >= len(args) || args[i+1] == "" {
+				return nil, "", ErrFromDiffNoPath
+			}
+			fromDiff = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], fromDiffOption+"="):
+			fromDiff = strings.TrimPrefix(args[i], fromDiffOption+"=")
+			if fromDiff == "" {
+				return nil, "", ErrFromDiffNoPath
+			}
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	return rest, fromDiff, nil
+}
+
+func NewCreateAction(migrationsPath string, differ SchemaDiffer) *CreateAction {
 	return &CreateAction{
 		migrationsPath: migrationsPath,
+		differ:         differ,
 	}
 }
 
-func (a *CreateAction) Run(params interface{}) error {
+func (a *CreateAction) Run(_ context.Context, params interface{}) error {
 	p, ok := params.(*CreateActionParams)
 	if !ok {
 		return errorsInternal.ErrInvalidActionParamsType
 	}
 
+	vars := tmplVars{
+		CamelName: nameToCamelCase(p.name),
+	}
+
 	var tmpl *template.Template
 
 	if p.mType == migration.TypeGo {
@@ -113,13 +176,28 @@ func (a *CreateAction) Run(params interface{}) error {
 			tmpl = MigrationTemplateGo
 		}
 	}
-	if p.mType == migration.TypeSQL {
+	// template skeleton has no actions, so it is the same as SQL one
+	if p.mType == migration.TypeSQL || p.mType == migration.TypeSQLTemplate {
 		if p.safe {
 			tmpl = MigrationTemplateSQLSafe
 		} else {
 			tmpl = MigrationTemplateSQL
 		}
 	}
+	if p.fromDiff != "" {
+		changed, err := a.fillDiffVars(p, &vars)
+		if err != nil {
+			return err
+		}
+
+		if !changed {
+			log.Info("No schema changes found. Nothing has been created.\n")
+
+			return nil
+		}
+
+		tmpl = MigrationTemplateSQLFromDiff
+	}
 	if tmpl == nil {
 		return ErrCannotSelectTmpl
 	}
@@ -145,9 +223,6 @@ func (a *CreateAction) Run(params interface{}) error {
 	}
 	defer f.Close()
 
-	vars := tmplVars{
-		CamelName: nameToCamelCase(p.name),
-	}
 	if err := tmpl.Execute(f, vars); err != nil {
 		return err
 	}
@@ -157,6 +232,28 @@ func (a *CreateAction) Run(params interface{}) error {
 	return nil
 }
 
+// fillDiffVars fills template vars with statements diff, returns false if there are no changes.
+func (a *CreateAction) fillDiffVars(p *CreateActionParams, vars *tmplVars) (bool, error) {
+	if a.differ == nil {
+		return false, ErrSchemaDifferNotSet
+	}
+
+	up, down, err := a.differ.DiffSchema(p.fromDiff)
+	if err != nil {
+		return false, err
+	}
+
+	if len(up) == 0 {
+		return false, nil
+	}
+
+	vars.NoTransaction = !p.safe
+	vars.UpStatements = up
+	vars.DownStatements = down
+
+	return true, nil
+}
+
 var nameToCamelRegex = regexp.MustCompile("(^[A-Za-z])|_([A-Za-z])")
 
 func nameToCamelCase(name string) string {
@@ -188,6 +285,14 @@ var MigrationTemplateSQL = template.Must(template.New("gomigrate.sql-migration")
 -- +gomigrate StatementEnd
 `))
 
+var MigrationTemplateSQLFromDiff = template.Must(template.New("gomigrate.sql-migration-from-diff").Parse(`{{if .NoTransaction}}-- +gomigrate NO TRANSACTION
+{{end}}-- +gomigrate Up
+{{range .UpStatements}}{{.}}
+{{end}}
+-- +gomigrate Down
+{{range .DownStatements}}{{.}}
+{{end}}`))
+
 var MigrationTemplateGo = template.Must(template.New("gomigrate.go-migration").Parse(`package migrations
 
 import (
@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"io/ioutil"
 	"log"
 	"os"
@@ -95,12 +96,56 @@ func TestCreateActionParams_ValidateAndFill(t *testing.T) {
 			args:    args{args: []string{"create_some_table", "sql", "false"}},
 			wantErr: nil,
 		},
+		{
+			name: "success validate .sql.tmpl",
+			expectedParams: &CreateActionParams{
+				name:  "create_some_table",
+				mType: migration.TypeSQLTemplate,
+				safe:  true,
+			},
+			args:    args{args: []string{"create_some_table", "sql.tmpl"}},
+			wantErr: nil,
+		},
 		{
 			name:           "error validate .sql safe=kek",
 			expectedParams: &CreateActionParams{},
 			args:           args{args: []string{"create_some_table", "sql", "kek"}},
 			wantErr:        ErrUnknownSafeParamValue,
 		},
+		{
+			name: "from diff defaults to .sql",
+			expectedParams: &CreateActionParams{
+				name:     "create_some_table",
+				mType:    migration.TypeSQL,
+				safe:     true,
+				fromDiff: "desired.sql",
+			},
+			args:    args{args: []string{"create_some_table", "--from-diff", "desired.sql"}},
+			wantErr: nil,
+		},
+		{
+			name: "from diff with equal sign and safe=false",
+			expectedParams: &CreateActionParams{
+				name:     "create_some_table",
+				mType:    migration.TypeSQL,
+				safe:     false,
+				fromDiff: "desired.sql",
+			},
+			args:    args{args: []string{"--from-diff=desired.sql", "create_some_table", "sql", "false"}},
+			wantErr: nil,
+		},
+		{
+			name:           "from diff without path",
+			expectedParams: &CreateActionParams{},
+			args:           args{args: []string{"create_some_table", "--from-diff"}},
+			wantErr:        ErrFromDiffNoPath,
+		},
+		{
+			name:           "from diff with .go type",
+			expectedParams: &CreateActionParams{},
+			args:           args{args: []string{"create_some_table", "go", "--from-diff", "desired.sql"}},
+			wantErr:        ErrFromDiffNotSQL,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -114,9 +159,19 @@ func TestCreateActionParams_ValidateAndFill(t *testing.T) {
 	}
 }
 
+type schemaDifferStub struct {
+	up   []string
+	down []string
+}
+
+func (d *schemaDifferStub) DiffSchema(_ string) ([]string, []string, error) {
+	return d.up, d.down, nil
+}
+
 func TestCreateAction_Run(t *testing.T) {
 	type fields struct {
 		migrationsPath string
+		differ         SchemaDiffer
 	}
 	type args struct {
 		params interface{}
@@ -149,13 +204,38 @@ func TestCreateAction_Run(t *testing.T) {
 			}},
 			wantErr: nil,
 		},
+		{
+			name:   "from diff without differ",
+			fields: fields{migrationsPath: dir},
+			args: args{params: &CreateActionParams{
+				name:     "some_diff_name",
+				mType:    migration.TypeSQL,
+				fromDiff: "desired.sql",
+			}},
+			wantErr: ErrSchemaDifferNotSet,
+		},
+		{
+			name: "from diff success case",
+			fields: fields{migrationsPath: dir, differ: &schemaDifferStub{
+				up:   []string{"CREATE TABLE t (\n    id integer\n);"},
+				down: []string{"DROP TABLE t;"},
+			}},
+			args: args{params: &CreateActionParams{
+				name:     "some_diff_name",
+				mType:    migration.TypeSQL,
+				safe:     true,
+				fromDiff: "desired.sql",
+			}},
+			wantErr: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			a := &CreateAction{
 				migrationsPath: tt.fields.migrationsPath,
+				differ:         tt.fields.differ,
 			}
-			if err := a.Run(tt.args.params); err != nil && err != tt.wantErr {
+			if err := a.Run(context.Background(), tt.args.params); err != nil && err != tt.wantErr {
 				require.Error(t, tt.wantErr, err)
 			}
 		})
@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -10,6 +11,7 @@ import (
 	"github.com/tweety53/gomigrate/internal/repo"
 	"github.com/tweety53/gomigrate/internal/service"
 	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
 )
 
 var ErrInconsistentMigrationsData = errors.New("migrations data in repo and migration files path inconsistent, please check")
@@ -23,14 +25,27 @@ func NewDownAction(migrationsSvc *service.MigrationService) *DownAction {
 }
 
 type DownActionParams struct {
-	limit int
+	limit  int
+	atomic bool
+	// batch reverts versions applied by the last up invocation.
+	batch bool
 }
 
 func (p *DownActionParams) Get() interface{} {
-	return &DownActionParams{limit: p.limit}
+	return &DownActionParams{limit: p.limit, atomic: p.atomic, batch: p.batch}
 }
 
 func (p *DownActionParams) ValidateAndFill(args []string) error {
+	args, p.atomic = extractAtomicOption(args)
+	args, p.batch = extractFlag(args, batchOption)
+	if p.batch {
+		if len(args) > 0 {
+			return ErrBatchWithLimit
+		}
+
+		return nil
+	}
+
 	if len(args) > 0 {
 		if args[0] == helpers.LimitAll {
 			p.limit = 0
@@ -52,18 +67,21 @@ func (p *DownActionParams) ValidateAndFill(args []string) error {
 	return nil
 }
 
-func (a *DownAction) Run(params interface{}) error {
+func (a *DownAction) Run(ctx context.Context, params interface{}) error {
 	p, ok := params.(*DownActionParams)
 	if !ok {
 		return errorsInternal.ErrInvalidActionParamsType
 	}
 
-	migrationHistoryRecords, err := a.svc.MigrationsRepo.GetMigrationsHistory(p.limit)
+	migrationHistoryRecords, err := historyRecords(a.svc, p.limit, p.batch)
 	if err != nil {
 		return err
 	}
 
-	downMigrations := migration.Convert(migrationHistoryRecords)
+	downMigrations, err := a.svc.GetDownMigrations(migrationHistoryRecords)
+	if err != nil {
+		return err
+	}
 
 	if len(downMigrations) == 0 {
 		log.Warn("No migration has been done before.\n")
@@ -71,23 +89,56 @@ func (a *DownAction) Run(params interface{}) error {
 		return nil
 	}
 
-	downMigrations, err = a.svc.MigrationsCollector.CollectMigrations(
-		a.svc.MigrationsPath,
-		migration.GetComparableVersion(downMigrations[0].Version),
-		migration.GetComparableVersion(downMigrations[len(downMigrations)-1].Version))
+	n := len(downMigrations)
+
+	if err := ensureReversible(downMigrations); err != nil {
+		return err
+	}
+
+	log.Warnf("Total %d %s to be reverted:\n", n, helpers.ChooseLogText(n, true))
+	log.Infof("%s", downMigrations)
+
+	return a.svc.ObserveRun(ctx, "down", migration.DirectionDown, func(ctx context.Context) error {
+		return migrationFailed(a.migrate(ctx, p, downMigrations))
+	})
+}
+
+// migrate reverts migrations.
+func (a *DownAction) migrate(ctx context.Context, p *DownActionParams, downMigrations migration.Migrations) error {
+	if err := a.svc.EnsureDataTables(); err != nil {
+		return err
+	}
+
+	err := a.svc.RunWithHooks(ctx, migration.DirectionDown, migration.DirectionDown, func() error {
+		if p.atomic {
+			if err := runAtomic(ctx, a.svc, downMigrations, nil); err != nil {
+				log.Err("\nAtomic migration failed. Nothing has been reverted.\n")
+
+				return irreversibleError(err)
+			}
+
+			return nil
+		}
+
+		return a.downOneByOne(ctx, downMigrations)
+	})
 	if err != nil {
 		return err
 	}
 
-	if len(downMigrations) == 0 {
-		return ErrInconsistentMigrationsData
+	log.Infof("\n%d reverted.\n", len(downMigrations))
+	log.Info("\nMigrated down successfully.\n")
+
+	if err := a.svc.AutoDumpSchema(); err != nil {
+		return err
 	}
 
-	downMigrations = downMigrations.Reverse()
-	n := len(downMigrations)
+	return nil
+}
 
-	log.Warnf("Total %d %s to be reverted:\n", n, helpers.ChooseLogText(n, true))
-	log.Infof("%s", downMigrations)
+func (a *DownAction) downOneByOne(ctx context.Context, downMigrations migration.Migrations) error {
+	n := len(downMigrations)
+	runner := migration.NewRunner(a.svc.Hooks, a.svc.Observers...)
 
 	var reverted int
 	for i := range downMigrations {
@@ -96,17 +147,41 @@ func (a *DownAction) Run(params interface{}) error {
 			return errors.New("MigrationRepo type assertion err")
 		}
 
-		if err = downMigrations[i].Down(r, &migration.Runner{}); err != nil {
+		if err := downMigrations[i].Down(ctx, r, runner); err != nil {
 			log.Errf("\n%d from %d %s reverted.\n", reverted, n, helpers.ChooseLogText(reverted, false))
 
-			return err
+			return irreversibleError(err)
 		}
 
 		reverted++
 	}
 
-	log.Infof("\n%d reverted.\n", n)
-	log.Info("\nMigrated down successfully.\n")
+	return nil
+}
+
+// ensureReversible refuses to revert anything if one of migrations is irreversible.
+func ensureReversible(migrations migration.Migrations) error {
+	for _, m := range migrations {
+		irreversible, err := m.IsIrreversible()
+		if err != nil {
+			return err
+		}
+
+		if irreversible {
+			log.Errf("Migration %s is irreversible. Nothing has been reverted.\n", m.Version)
+
+			return irreversibleError(errors.Wrapf(migration.ErrIrreversible, "cannot revert %s", m.Version))
+		}
+	}
 
 	return nil
 }
+
+// irreversibleError sets exit code to errors of irreversible migrations, e.g. returned by Go down func.
+func irreversibleError(err error) error {
+	if !errors.Is(err, migration.ErrIrreversible) {
+		return err
+	}
+
+	return &errorsInternal.GoMigrateError{Err: err, ExitCode: exitcode.Irreversible}
+}
@@ -1,6 +1,8 @@
 package action
 
 import (
+	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/gojuno/minimock/v3"
@@ -9,6 +11,8 @@ import (
 	"github.com/tweety53/gomigrate/internal/migration"
 	"github.com/tweety53/gomigrate/internal/repo"
 	"github.com/tweety53/gomigrate/internal/service"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
 )
 
 func TestDownActionParams_ValidateAndFill(t *testing.T) {
@@ -39,6 +43,24 @@ func TestDownActionParams_ValidateAndFill(t *testing.T) {
 			expectedParams: &DownActionParams{limit: 3},
 			wantErr:        false,
 		},
+		{
+			name:           "atomic with limit",
+			args:           args{args: []string{"3", atomicOption}},
+			expectedParams: &DownActionParams{limit: 3, atomic: true},
+			wantErr:        false,
+		},
+		{
+			name:           "last batch",
+			args:           args{args: []string{batchOption, atomicOption}},
+			expectedParams: &DownActionParams{atomic: true, batch: true},
+			wantErr:        false,
+		},
+		{
+			name:           "batch with limit",
+			args:           args{args: []string{batchOption, "3"}},
+			expectedParams: &DownActionParams{batch: true},
+			wantErr:        true,
+		},
 		{
 			name:           "non-numeric limit",
 			args:           args{args: []string{"kek"}},
@@ -93,6 +115,30 @@ func TestDownAction_Run(t *testing.T) {
 			args:    args{params: &DownActionParams{limit: 0}},
 			wantErr: false,
 		},
+		{
+			name: "irreversible migration",
+			fields: fields{
+				svc: func() *service.MigrationService {
+					mc := minimock.NewController(t)
+					mRepoMock := repo.NewMigrationRepoMock(mc).
+						GetMigrationsHistoryMock.Return(repo.MigrationRecords{
+						&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+					}, nil)
+					cMock := migration.NewMigrationsCollectorInterfaceMock(mc).
+						CollectMigrationsMock.Return(migration.Migrations{
+						&migration.Migration{
+							Version:    "m200101_000000_test",
+							Source:     "m200101_000000_test.go",
+							Registered: true,
+						},
+					}, nil)
+
+					return service.NewMigrationService(nil, mRepoMock, nil, cMock, "")
+				}(),
+			},
+			args:    args{params: &DownActionParams{limit: 1}},
+			wantErr: true,
+		},
 		//{
 		//	name: "success case",
 		//	fields: fields{
@@ -138,9 +184,61 @@ func TestDownAction_Run(t *testing.T) {
 			a := &DownAction{
 				svc: tt.fields.svc,
 			}
-			if err := a.Run(tt.args.params); (err != nil) != tt.wantErr {
+			if err := a.Run(context.Background(), tt.args.params); (err != nil) != tt.wantErr {
 				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestEnsureReversible(t *testing.T) {
+	goMigration := func(safeDown func(*sql.Tx) error, down func(*sql.DB) error) *migration.Migration {
+		return &migration.Migration{
+			Version:    "m200101_000000_test",
+			Source:     "m200101_000000_test.go",
+			Registered: true,
+			SafeDownFn: safeDown,
+			DownFn:     down,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		migrations migration.Migrations
+		wantErr    bool
+	}{
+		{
+			name:       "reversible",
+			migrations: migration.Migrations{goMigration(func(*sql.Tx) error { return nil }, nil)},
+			wantErr:    false,
+		},
+		{
+			name:       "nil down",
+			migrations: migration.Migrations{goMigration(nil, nil)},
+			wantErr:    true,
+		},
+		{
+			name:       "irreversible safe down",
+			migrations: migration.Migrations{goMigration(migration.IrreversibleSafeDown, nil)},
+			wantErr:    true,
+		},
+		{
+			name:       "irreversible down",
+			migrations: migration.Migrations{goMigration(nil, migration.IrreversibleDown)},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ensureReversible(tt.migrations)
+			if !tt.wantErr {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, migration.ErrIrreversible)
+			require.Equal(t, exitcode.Irreversible, errorsInternal.ErrorExitCode(err))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package action
+
+import (
+	"context"
+
+	"github.com/tweety53/gomigrate/internal/service"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+)
+
+type DumpAction struct {
+	svc        *service.MigrationService
+	schemaFile string
+}
+
+func NewDumpAction(migrationsSvc *service.MigrationService, schemaFile string) *DumpAction {
+	if schemaFile == "" {
+		schemaFile = service.DefaultSchemaFile
+	}
+
+	return &DumpAction{svc: migrationsSvc, schemaFile: schemaFile}
+}
+
+type DumpActionParams struct {
+	path string
+}
+
+func (p *DumpActionParams) ValidateAndFill(args []string) error {
+	if len(args) > 0 {
+		p.path = args[0]
+	}
+
+	return nil
+}
+
+func (p *DumpActionParams) Get() interface{} {
+	return &DumpActionParams{path: p.path}
+}
+
+func (a *DumpAction) Run(_ context.Context, params interface{}) error {
+	p, ok := params.(*DumpActionParams)
+	if !ok {
+		return errorsInternal.ErrInvalidActionParamsType
+	}
+
+	path := p.path
+	if path == "" {
+		path = a.schemaFile
+	}
+
+	return a.svc.DumpSchema(path)
+}
@@ -0,0 +1,78 @@
+package action
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/service"
+)
+
+func TestDumpActionParams_ValidateAndFill(t *testing.T) {
+	type args struct {
+		args []string
+	}
+	tests := []struct {
+		name           string
+		args           args
+		expectedParams *DumpActionParams
+		wantErr        bool
+	}{
+		{
+			name:           "no args",
+			args:           args{args: []string{}},
+			expectedParams: &DumpActionParams{},
+			wantErr:        false,
+		},
+		{
+			name:           "path passed",
+			args:           args{args: []string{"db/schema.sql"}},
+			expectedParams: &DumpActionParams{path: "db/schema.sql"},
+			wantErr:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &DumpActionParams{}
+			err := p.ValidateAndFill(tt.args.args)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.expectedParams, p)
+		})
+	}
+}
+
+func TestDumpAction_Run(t *testing.T) {
+	type fields struct {
+		svc *service.MigrationService
+	}
+	type args struct {
+		params interface{}
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "invalid action params type passed",
+			fields:  fields{},
+			args:    args{params: struct{}{}},
+			wantErr: true,
+		},
+		{
+			name:    "schema repo not initialized",
+			fields:  fields{svc: &service.MigrationService{}},
+			args:    args{params: &DumpActionParams{}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewDumpAction(tt.fields.svc, "")
+			if err := a.Run(context.Background(), tt.args.params); (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
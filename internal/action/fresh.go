@@ -1,8 +1,9 @@
 package action
 
 import (
+	"context"
+
 	"github.com/tweety53/gomigrate/internal/helpers"
-	"github.com/tweety53/gomigrate/internal/log"
 	"github.com/tweety53/gomigrate/internal/service"
 )
 
@@ -16,13 +17,10 @@ func NewFreshAction(migrationsSvc *service.MigrationService) *FreshAction {
 
 type FreshActionParams struct{}
 
-func (a *FreshAction) Run(_ interface{}) error {
-	// todo: restrict action also for local env only
+func (a *FreshAction) Run(ctx context.Context, _ interface{}) error {
 	res := helpers.AskForConfirmation("Are you sure you want to drop all tables and related constraints and start the migration from the beginning?\nAll data will be lost irreversibly!")
 	if !res {
-		log.Info("Action was cancelled by user. Nothing has been performed.")
-
-		return nil
+		return cancelled()
 	}
 
 	// truncate repo
@@ -36,7 +34,7 @@ func (a *FreshAction) Run(_ interface{}) error {
 	if err := params.ValidateAndFill([]string{}); err != nil {
 		return err
 	}
-	if err := upAction.Run(params); err != nil {
+	if err := upAction.Run(ctx, params); err != nil {
 		return err
 	}
 
@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"strconv"
 	"time"
 
@@ -48,13 +49,13 @@ func (p *HistoryActionParams) Get() interface{} {
 	return &HistoryActionParams{limit: p.limit}
 }
 
-func (a *HistoryAction) Run(params interface{}) error {
+func (a *HistoryAction) Run(_ context.Context, params interface{}) error {
 	p, ok := params.(*HistoryActionParams)
 	if !ok {
 		return errorsInternal.ErrInvalidActionParamsType
 	}
 
-	migrationRecords, err := a.svc.MigrationsRepo.GetMigrationsHistory(p.limit)
+	migrationRecords, err := a.svc.GetBatchedHistory(p.limit)
 	if err != nil {
 		return err
 	}
@@ -73,10 +74,25 @@ func (a *HistoryAction) Run(params interface{}) error {
 	}
 
 	const timeFormat = "06-01-02 15:04:05"
-	for _, record := range migrationRecords {
+	for i, record := range migrationRecords {
+		if i == 0 || record.Batch != migrationRecords[i-1].Batch {
+			logBatch(record.Batch)
+		}
+
 		t := time.Unix(int64(record.ApplyTime), 0)
 		log.Printf("\t(%s) %s\n", t.Format(timeFormat), record.Version)
 	}
 
 	return nil
 }
+
+// logBatch prints header of versions applied by the same up invocation.
+func logBatch(batch int) {
+	if batch == 0 {
+		log.Info("Without batch:\n")
+
+		return
+	}
+
+	log.Infof("Batch %d:\n", batch)
+}
@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -78,7 +79,7 @@ func TestHistoryAction_Run(t *testing.T) {
 			a := &DownAction{
 				svc: tt.fields.svc,
 			}
-			if err := a.Run(tt.args.params); (err != nil) != tt.wantErr {
+			if err := a.Run(context.Background(), tt.args.params); (err != nil) != tt.wantErr {
 				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
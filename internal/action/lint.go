@@ -0,0 +1,104 @@
+package action
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/helpers"
+	"github.com/tweety53/gomigrate/internal/lint"
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/migration"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+var ErrLintFailed = errors.New("lint found errors in migrations")
+
+// LintAction checks SQL migrations for risky patterns, it needs no database connection.
+type LintAction struct {
+	migrationsPath string
+	collector      migration.MigrationsCollectorInterface
+	linter         *lint.Linter
+}
+
+func NewLintAction(migrationsPath string, collector migration.MigrationsCollectorInterface, linter *lint.Linter) *LintAction {
+	return &LintAction{migrationsPath: migrationsPath, collector: collector, linter: linter}
+}
+
+type LintActionParams struct{}
+
+func (p *LintActionParams) ValidateAndFill(_ []string) error {
+	return nil
+}
+
+func (p *LintActionParams) Get() interface{} {
+	return &LintActionParams{}
+}
+
+func (a *LintAction) Run(_ context.Context, params interface{}) error {
+	if _, ok := params.(*LintActionParams); !ok {
+		return errorsInternal.ErrInvalidActionParamsType
+	}
+
+	migrations, err := a.collector.CollectMigrations(a.migrationsPath, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	var (
+		findings lint.Findings
+		linted   int
+	)
+
+	for _, m := range migrations {
+		if !m.IsSQL() {
+			continue
+		}
+
+		findings = append(findings, a.lintMigration(m)...)
+		linted++
+	}
+
+	var errorsCount int
+	for _, f := range findings {
+		if f.Severity == lint.SeverityError {
+			errorsCount++
+			log.Errf("%s\n", f)
+
+			continue
+		}
+
+		log.Warnf("%s\n", f)
+	}
+
+	log.Infof("\n%d %s linted, %d errors, %d warnings.\n",
+		linted, helpers.ChooseLogText(linted, true), errorsCount, len(findings)-errorsCount)
+
+	if findings.HasErrors() {
+		return &errorsInternal.GoMigrateError{Err: ErrLintFailed, ExitCode: exitcode.LintFailed}
+	}
+
+	return nil
+}
+
+func (a *LintAction) lintMigration(m *migration.Migration) lint.Findings {
+	up, upTx, err := m.Statements(migration.DirectionUp)
+	if err != nil {
+		return a.linter.ParseError(m.Version, err)
+	}
+
+	down, downTx, err := m.Statements(migration.DirectionDown)
+	irreversible := errors.Is(err, migration.ErrIrreversible)
+	if err != nil && !irreversible {
+		return a.linter.ParseError(m.Version, err)
+	}
+
+	return a.linter.Lint(&lint.Migration{
+		Name:         m.Version,
+		Up:           up,
+		Down:         down,
+		UpTx:         upTx,
+		DownTx:       downTx,
+		Irreversible: irreversible,
+	})
+}
@@ -0,0 +1,60 @@
+package action
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/lint"
+	"github.com/tweety53/gomigrate/internal/migration"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+func TestLintAction_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    map[string]string
+		params   interface{}
+		wantErr  error
+		wantCode exitcode.ExitCode
+	}{
+		{
+			name:    "invalid action params type passed",
+			params:  struct{}{},
+			wantErr: errorsInternal.ErrInvalidActionParamsType,
+		},
+		{
+			name:     "errors found",
+			params:   &LintActionParams{},
+			wantErr:  ErrLintFailed,
+			wantCode: exitcode.LintFailed,
+		},
+		{
+			name: "errors disabled",
+			rules: map[string]string{
+				string(lint.RuleCreateIndexNotConcurrently):     string(lint.SeverityWarning),
+				string(lint.RuleAddColumnNotNullWithoutDefault): string(lint.SeverityOff),
+			},
+			params: &LintActionParams{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linter, err := lint.NewLinter(tt.rules)
+			require.NoError(t, err)
+
+			err = NewLintAction("testdata/lint_test", &migration.MigrationsCollector{}, linter).Run(context.Background(), tt.params)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantCode != exitcode.OK {
+				require.Equal(t, tt.wantCode, errorsInternal.ErrorExitCode(err))
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/tweety53/gomigrate/internal/helpers"
@@ -42,7 +43,7 @@ func (p *MarkActionParams) Get() interface{} {
 	return &MarkActionParams{version: p.version}
 }
 
-func (a *MarkAction) Run(params interface{}) error {
+func (a *MarkAction) Run(_ context.Context, params interface{}) error {
 	p, ok := params.(*MarkActionParams)
 	if !ok {
 		return errorsInternal.ErrInvalidActionParamsType
@@ -50,9 +51,7 @@ func (a *MarkAction) Run(params interface{}) error {
 
 	resp := helpers.AskForConfirmation(fmt.Sprintf("Set migration history at %s?", p.version))
 	if !resp {
-		log.Info("Action was cancelled by user. Nothing has been performed.\n")
-
-		return nil
+		return cancelled()
 	}
 
 	// try mark up
@@ -85,7 +84,7 @@ func (a *MarkAction) Run(params interface{}) error {
 		return markToBaseVersion(migrations, a, p)
 	}
 
-	return ErrUnableToFindVersion
+	return versionNotFound()
 }
 
 func markDown(i int, a *MarkAction, migrations migration.Migrations, p *MarkActionParams) error {
@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/tweety53/gomigrate/internal/helpers"
@@ -47,7 +48,7 @@ func (p *NewActionParams) Get() interface{} {
 	return &NewActionParams{limit: p.limit}
 }
 
-func (a *NewAction) Run(params interface{}) error {
+func (a *NewAction) Run(_ context.Context, params interface{}) error {
 	p, ok := params.(*NewActionParams)
 	if !ok {
 		return errorsInternal.ErrInvalidActionParamsType
@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
@@ -22,10 +23,23 @@ func NewRedoAction(migrationsSvc *service.MigrationService) *RedoAction {
 }
 
 type RedoActionParams struct {
-	limit int
+	limit  int
+	atomic bool
+	// batch reverts versions applied by the last up invocation.
+	batch bool
 }
 
 func (p *RedoActionParams) ValidateAndFill(args []string) error {
+	args, p.atomic = extractAtomicOption(args)
+	args, p.batch = extractFlag(args, batchOption)
+	if p.batch {
+		if len(args) > 0 {
+			return ErrBatchWithLimit
+		}
+
+		return nil
+	}
+
 	if len(args) > 0 {
 		if args[0] == helpers.LimitAll {
 			p.limit = 0
@@ -48,40 +62,42 @@ func (p *RedoActionParams) ValidateAndFill(args []string) error {
 }
 
 func (p *RedoActionParams) Get() interface{} {
-	return &RedoActionParams{limit: p.limit}
+	return &RedoActionParams{limit: p.limit, atomic: p.atomic, batch: p.batch}
 }
 
-func (a *RedoAction) Run(params interface{}) error {
+func (a *RedoAction) Run(ctx context.Context, params interface{}) error {
 	p, ok := params.(*RedoActionParams)
 	if !ok {
 		return errorsInternal.ErrInvalidActionParamsType
 	}
 
-	migrationHistoryRecords, err := a.svc.MigrationsRepo.GetMigrationsHistory(p.limit)
+	migrationHistoryRecords, err := historyRecords(a.svc, p.limit, p.batch)
 	if err != nil {
 		return err
 	}
 
-	redoMigrations := migration.Convert(migrationHistoryRecords)
+	downMigrations, err := a.svc.GetDownMigrations(migrationHistoryRecords)
+	if err != nil {
+		return err
+	}
 
-	if len(redoMigrations) == 0 {
+	if len(downMigrations) == 0 {
 		log.Warn("No migration has been done before.\n")
 
 		return nil
 	}
 
-	redoMigrations, err = a.svc.MigrationsCollector.CollectMigrations(
-		a.svc.MigrationsPath,
-		migration.GetComparableVersion(redoMigrations[0].Version),
-		migration.GetComparableVersion(redoMigrations[len(redoMigrations)-1].Version))
-	if err != nil {
+	if err := ensureReversible(downMigrations); err != nil {
 		return err
 	}
 
-	if len(redoMigrations) == 0 {
-		return ErrInconsistentMigrationsData
+	if err := ensureReapplicable(downMigrations); err != nil {
+		return err
 	}
 
+	// migrations are applied again in the order they were applied
+	redoMigrations := downMigrations.Reverse()
+
 	var logText string
 	n := len(redoMigrations)
 
@@ -92,9 +108,7 @@ func (a *RedoAction) Run(params interface{}) error {
 
 	resp := helpers.AskForConfirmation(fmt.Sprintf("Redo the above %s?", logText))
 	if !resp {
-		log.Info("Action was cancelled by user. Nothing has been performed.\n")
-
-		return nil
+		return cancelled()
 	}
 
 	r, ok := a.svc.MigrationsRepo.(*repo.MigrationsRepository)
@@ -102,28 +116,86 @@ func (a *RedoAction) Run(params interface{}) error {
 		return errors.New("MigrationRepo type assertion err")
 	}
 
+	return a.svc.ObserveRun(ctx, "redo", migration.DirectionDown, func(ctx context.Context) error {
+		return migrationFailed(a.migrate(ctx, p, r, redoMigrations))
+	})
+}
+
+// migrate reverts migrations and applies them again.
+func (a *RedoAction) migrate(ctx context.Context, p *RedoActionParams, r *repo.MigrationsRepository, redoMigrations migration.Migrations) error {
+	if err := a.svc.EnsureDataTables(); err != nil {
+		return err
+	}
+
+	// migrations applied again make up a new batch
+	if err := setBatch(a.svc, redoMigrations); err != nil {
+		return err
+	}
+
+	err := a.svc.RunWithHooks(ctx, migration.DirectionDown, migration.DirectionUp, func() error {
+		if p.atomic {
+			down := append(migration.Migrations{}, redoMigrations...).Reverse()
+			if err := runAtomic(ctx, a.svc, down, redoMigrations); err != nil {
+				log.Err("\nAtomic migration failed. Nothing has been redone.\n")
+
+				return irreversibleError(err)
+			}
+
+			return nil
+		}
+
+		return redo(ctx, r, migration.NewRunner(a.svc.Hooks, a.svc.Observers...), redoMigrations)
+	})
+	if err != nil {
+		return err
+	}
+
+	n := len(redoMigrations)
+	log.Infof("\n%d %s redone.\n", n, helpers.ChooseLogText(n, false))
+	log.Info("\nMigration redone successfully.\n")
+
+	if err := a.svc.AutoDumpSchema(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ensureReapplicable refuses to redo anything if one of migrations is reverted with its stored down script,
+// its file is missing, so it cannot be applied again.
+func ensureReapplicable(migrations migration.Migrations) error {
+	for _, m := range migrations {
+		if m.Stored != nil {
+			log.Errf("Migration file of %s is missing. Nothing has been redone.\n", m.Version)
+
+			return errors.Wrapf(ErrInconsistentMigrationsData, "cannot apply %s again", m.Version)
+		}
+	}
+
+	return nil
+}
+
+// redo reverts migrations and applies them again one by one.
+func redo(ctx context.Context, r repo.MigrationRepo, runner migration.RunnerInterface, migrations migration.Migrations) error {
 	// reverse for down
-	redoMigrations = redoMigrations.Reverse()
-	for i := range redoMigrations {
-		if err := redoMigrations[i].Down(r, &migration.Runner{}); err != nil {
+	migrations = migrations.Reverse()
+	for i := range migrations {
+		if err := migrations[i].Down(ctx, r, runner); err != nil {
 			log.Err("\nMigration failed. The rest of the migrations are canceled.\n")
 
-			return err
+			return irreversibleError(err)
 		}
 	}
 
 	// reverse for up
-	redoMigrations = redoMigrations.Reverse()
-	for i := range redoMigrations {
-		if err := redoMigrations[i].Up(r, &migration.Runner{}); err != nil {
+	migrations = migrations.Reverse()
+	for i := range migrations {
+		if err := migrations[i].Up(ctx, r, runner); err != nil {
 			log.Err("\nMigration failed. The rest of the migrations are canceled.\n")
 
 			return err
 		}
 	}
 
-	log.Infof("\n%d %s redone.\n", n, helpers.ChooseLogText(n, false))
-	log.Info("\nMigration redone successfully.\n")
-
 	return nil
 }
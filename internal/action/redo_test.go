@@ -1,10 +1,13 @@
 package action
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"github.com/tweety53/gomigrate/internal/helpers"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/repo"
 	"github.com/tweety53/gomigrate/internal/service"
 )
 
@@ -36,6 +39,24 @@ func TestRedoActionParams_ValidateAndFill(t *testing.T) {
 			expectedParams: &RedoActionParams{limit: 3},
 			wantErr:        false,
 		},
+		{
+			name:           "atomic with limit",
+			args:           args{args: []string{"3", atomicOption}},
+			expectedParams: &RedoActionParams{limit: 3, atomic: true},
+			wantErr:        false,
+		},
+		{
+			name:           "last batch",
+			args:           args{args: []string{batchOption, atomicOption}},
+			expectedParams: &RedoActionParams{atomic: true, batch: true},
+			wantErr:        false,
+		},
+		{
+			name:           "batch with limit",
+			args:           args{args: []string{batchOption, "3"}},
+			expectedParams: &RedoActionParams{batch: true},
+			wantErr:        true,
+		},
 		{
 			name:           "non-numeric limit",
 			args:           args{args: []string{"kek"}},
@@ -78,9 +99,17 @@ func TestRedoAction_Run(t *testing.T) {
 			a := &RedoAction{
 				svc: tt.fields.svc,
 			}
-			if err := a.Run(tt.args.params); (err != nil) != tt.wantErr {
+			if err := a.Run(context.Background(), tt.args.params); (err != nil) != tt.wantErr {
 				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestEnsureReapplicable(t *testing.T) {
+	stored := migration.NewStoredMigration("m200101_000001_test", &repo.DownScript{Statements: []string{"DROP TABLE a;"}})
+	collected := &migration.Migration{Version: "m200101_000000_test", Source: "m200101_000000_test.sql"}
+
+	require.NoError(t, ensureReapplicable(migration.Migrations{collected}))
+	require.ErrorIs(t, ensureReapplicable(migration.Migrations{stored, collected}), ErrInconsistentMigrationsData)
+}
@@ -0,0 +1,73 @@
+package action
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/helpers"
+	"github.com/tweety53/gomigrate/internal/service"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+)
+
+const resetOption = "--reset"
+
+var ErrUnknownSeedOption = errors.New("unknown seed option")
+
+// SeedAction applies new seeds, svc must be built on seeds path, collector and tracking table.
+type SeedAction struct {
+	svc *service.MigrationService
+}
+
+func NewSeedAction(seedsSvc *service.MigrationService) *SeedAction {
+	return &SeedAction{svc: seedsSvc}
+}
+
+type SeedActionParams struct {
+	reset bool
+	// confirmed is set if reset is confirmed by caller, e.g. with typed database name, so it is not asked again.
+	confirmed bool
+}
+
+// Confirm marks reset as confirmed by caller.
+func (p *SeedActionParams) Confirm() {
+	p.confirmed = true
+}
+
+func (p *SeedActionParams) ValidateAndFill(args []string) error {
+	for _, arg := range args {
+		if arg != resetOption {
+			return errors.Wrap(ErrUnknownSeedOption, arg)
+		}
+
+		p.reset = true
+	}
+
+	return nil
+}
+
+func (p *SeedActionParams) Get() interface{} {
+	return &SeedActionParams{reset: p.reset, confirmed: p.confirmed}
+}
+
+func (a *SeedAction) Run(ctx context.Context, params interface{}) error {
+	p, ok := params.(*SeedActionParams)
+	if !ok {
+		return errorsInternal.ErrInvalidActionParamsType
+	}
+
+	if _, err := a.svc.MigrationsRepo.EnsureDBVersion(); err != nil {
+		return errors.Wrap(err, "cannot check/create seeds table in DB")
+	}
+
+	if p.reset {
+		if !p.confirmed && !helpers.AskForConfirmation("Revert all applied seeds and apply them again?") {
+			return cancelled()
+		}
+
+		if err := NewDownAction(a.svc).Run(ctx, &DownActionParams{limit: 0}); err != nil {
+			return err
+		}
+	}
+
+	return NewUpAction(a.svc).Run(ctx, &UpActionParams{})
+}
@@ -0,0 +1,51 @@
+package action
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/service"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+)
+
+func TestSeedActionParams_ValidateAndFill(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		expectedParams *SeedActionParams
+		wantErr        bool
+	}{
+		{
+			name:           "no args",
+			args:           []string{},
+			expectedParams: &SeedActionParams{},
+			wantErr:        false,
+		},
+		{
+			name:           "reset",
+			args:           []string{"--reset"},
+			expectedParams: &SeedActionParams{reset: true},
+			wantErr:        false,
+		},
+		{
+			name:           "unknown option",
+			args:           []string{"--force"},
+			expectedParams: &SeedActionParams{},
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &SeedActionParams{}
+			err := p.ValidateAndFill(tt.args)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.expectedParams, p)
+		})
+	}
+}
+
+func TestSeedAction_Run(t *testing.T) {
+	a := NewSeedAction(&service.MigrationService{})
+	require.ErrorIs(t, a.Run(context.Background(), struct{}{}), errorsInternal.ErrInvalidActionParamsType)
+}
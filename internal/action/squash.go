@@ -0,0 +1,232 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/helpers"
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/service"
+	"github.com/tweety53/gomigrate/internal/version"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+var (
+	ErrSquashVersionNotCurrent = errors.New("database must be migrated exactly to the squash version, run 'to' action first")
+	ErrSchemaRepoNotSet        = errors.New("schema repo not initialized")
+)
+
+const baselineVersionSuffix = "baseline"
+
+type squashTmplVars struct {
+	Squashed       []string
+	UpStatements   []string
+	DownStatements []string
+}
+
+type SquashAction struct {
+	svc *service.MigrationService
+}
+
+func NewSquashAction(migrationsSvc *service.MigrationService) *SquashAction {
+	return &SquashAction{svc: migrationsSvc}
+}
+
+type SquashActionParams struct {
+	version     string
+	archivePath string
+}
+
+func (p *SquashActionParams) ValidateAndFill(args []string) error {
+	if len(args) == 0 {
+		return errorsInternal.ErrNotEnoughArgs
+	}
+
+	if !version.ValidMigrationVersion(args[0]) {
+		return errorsInternal.ErrInvalidVersionFormat
+	}
+
+	p.version = args[0]
+
+	if len(args) > 1 {
+		p.archivePath = args[1]
+	}
+
+	return nil
+}
+
+func (p *SquashActionParams) Get() interface{} {
+	return &SquashActionParams{version: p.version, archivePath: p.archivePath}
+}
+
+func (a *SquashAction) Run(_ context.Context, params interface{}) error {
+	p, ok := params.(*SquashActionParams)
+	if !ok {
+		return errorsInternal.ErrInvalidActionParamsType
+	}
+
+	if a.svc.SchemaRepo == nil {
+		return ErrSchemaRepoNotSet
+	}
+
+	history, err := a.svc.MigrationsRepo.GetMigrationsHistory(1)
+	if err != nil {
+		return err
+	}
+
+	if len(migration.Convert(history)) == 0 || history[0].Version != p.version {
+		return ErrSquashVersionNotCurrent
+	}
+
+	allMigrations, err := a.svc.MigrationsCollector.CollectMigrations(a.svc.MigrationsPath, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	squashMigrations := migrationsUpTo(allMigrations, p.version)
+	if len(squashMigrations) == 0 {
+		return versionNotFound()
+	}
+
+	n := len(squashMigrations)
+	logText := helpers.ChooseLogText(n, true)
+	log.Warnf("Total %d %s to be squashed:\n", n, logText)
+	log.Infof("%s", squashMigrations)
+
+	resp := helpers.AskForConfirmation(fmt.Sprintf("Squash the above %s into baseline?", logText))
+	if !resp {
+		return cancelled()
+	}
+
+	dbSchema, err := a.svc.SchemaRepo.GetSchema()
+	if err != nil {
+		return errors.Wrap(err, "cannot get db schema")
+	}
+
+	var buf bytes.Buffer
+	err = MigrationTemplateSQLBaseline.Execute(&buf, squashTmplVars{
+		Squashed:       squashedVersions(squashMigrations),
+		UpStatements:   dbSchema.Statements(),
+		DownStatements: dbSchema.DropStatements(),
+	})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(a.svc.MigrationsPath, baselineVersion(p.version)+"."+string(migration.TypeSQL))
+	if err := writeBaseline(path, buf.Bytes(), squashMigrations, p.archivePath); err != nil {
+		log.Err("Failed to squash migrations.")
+
+		return &errorsInternal.GoMigrateError{
+			Err:      err,
+			ExitCode: exitcode.IoErr,
+		}
+	}
+
+	log.Infof("\n%d %s squashed into %s\n", n, helpers.ChooseLogText(n, false), path)
+
+	return nil
+}
+
+// migrationsUpTo returns sorted migrations till the version inclusive or nil if there is no such version.
+func migrationsUpTo(migrations migration.Migrations, v string) migration.Migrations {
+	for i := range migrations {
+		if migrations[i].Version == v {
+			return migrations[:i+1]
+		}
+	}
+
+	return nil
+}
+
+// squashedVersions returns versions of migrations including ones squashed into previous baselines.
+func squashedVersions(migrations migration.Migrations) []string {
+	var versions []string
+	for _, m := range migrations {
+		versions = append(versions, m.Squashed...)
+		versions = append(versions, m.Version)
+	}
+
+	return versions
+}
+
+func baselineVersion(v string) string {
+	parts := strings.SplitN(v, "_", 3)
+
+	return parts[0] + "_" + parts[1] + "_" + baselineVersionSuffix
+}
+
+// writeBaseline places baseline via temporary file and only then removes squashed migrations
+// (or moves them to archivePath), so originals are kept if baseline can not be written.
+func writeBaseline(path string, content []byte, squashed migration.Migrations, archivePath string) error {
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, content, 0644); err != nil { //nolint:gosec
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+
+		return err
+	}
+
+	if archivePath != "" {
+		if err := os.MkdirAll(archivePath, 0755); err != nil { //nolint:gosec
+			return err
+		}
+	}
+
+	for _, m := range squashed {
+		sources := []string{m.Source}
+		if m.DownSource != "" {
+			sources = append(sources, m.DownSource)
+		}
+
+		for _, source := range sources {
+			if source == path {
+				continue
+			}
+
+			if _, err := os.Stat(source); os.IsNotExist(err) {
+				log.Warnf("Migration file %s not found, please remove it manually.\n", source)
+
+				continue
+			}
+
+			if archivePath != "" {
+				if err := os.Rename(source, filepath.Join(archivePath, filepath.Base(source))); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if err := os.Remove(source); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+var MigrationTemplateSQLBaseline = template.Must(template.New("gomigrate.sql-migration-baseline").Parse(`{{range .Squashed}}-- +gomigrate Squashed {{.}}
+{{end}}-- +gomigrate Up
+{{range .UpStatements}}-- +gomigrate StatementBegin
+{{.}}
+-- +gomigrate StatementEnd
+{{end}}
+-- +gomigrate Down
+{{range .DownStatements}}-- +gomigrate StatementBegin
+{{.}}
+-- +gomigrate StatementEnd
+{{end}}`))
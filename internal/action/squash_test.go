@@ -0,0 +1,161 @@
+package action
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gojuno/minimock/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/service"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+)
+
+func TestSquashActionParams_ValidateAndFill(t *testing.T) {
+	type args struct {
+		args []string
+	}
+	tests := []struct {
+		name           string
+		args           args
+		expectedParams *SquashActionParams
+		wantErr        bool
+	}{
+		{
+			name: "not enough args err",
+			args: args{
+				args: []string{},
+			},
+			expectedParams: &SquashActionParams{},
+			wantErr:        true,
+		},
+		{
+			name: "invalid version pattern",
+			args: args{
+				args: []string{"m200101_000000_+`"},
+			},
+			expectedParams: &SquashActionParams{},
+			wantErr:        true,
+		},
+		{
+			name: "version only",
+			args: args{
+				args: []string{"m200101_000000_test"},
+			},
+			expectedParams: &SquashActionParams{
+				version: "m200101_000000_test",
+			},
+			wantErr: false,
+		},
+		{
+			name: "version with archive path",
+			args: args{
+				args: []string{"m200101_000000_test", "migrations/archive"},
+			},
+			expectedParams: &SquashActionParams{
+				version:     "m200101_000000_test",
+				archivePath: "migrations/archive",
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &SquashActionParams{}
+			err := p.ValidateAndFill(tt.args.args)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.expectedParams, p)
+		})
+	}
+}
+
+func TestSquashAction_Run(t *testing.T) {
+	type fields struct {
+		svc *service.MigrationService
+	}
+	type args struct {
+		params interface{}
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "invalid action params type passed",
+			fields:  fields{},
+			args:    args{params: struct{}{}},
+			wantErr: errorsInternal.ErrInvalidActionParamsType,
+		},
+		{
+			name:    "schema repo not set",
+			fields:  fields{svc: &service.MigrationService{}},
+			args:    args{params: &SquashActionParams{version: "m200101_000000_test"}},
+			wantErr: ErrSchemaRepoNotSet,
+		},
+		{
+			name: "version is not current",
+			fields: fields{svc: &service.MigrationService{
+				SchemaRepo: &repo.SchemaRepository{},
+				MigrationsRepo: repo.NewMigrationRepoMock(minimock.NewController(t)).
+					GetMigrationsHistoryMock.Return(repo.MigrationRecords{
+					&repo.MigrationRecord{Version: "m200101_000001_test"},
+				}, nil),
+			}},
+			args:    args{params: &SquashActionParams{version: "m200101_000000_test"}},
+			wantErr: ErrSquashVersionNotCurrent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &SquashAction{
+				svc: tt.fields.svc,
+			}
+			err := a.Run(context.Background(), tt.args.params)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBaselineVersion(t *testing.T) {
+	require.Equal(t, "m200101_000002_baseline", baselineVersion("m200101_000002_add_users_table"))
+}
+
+func TestMigrationsUpTo(t *testing.T) {
+	migrations := migration.Migrations{
+		&migration.Migration{Version: "m200101_000000_test"},
+		&migration.Migration{Version: "m200101_000001_test1"},
+		&migration.Migration{Version: "m200101_000002_test2"},
+	}
+
+	require.Equal(t, migrations[:2], migrationsUpTo(migrations, "m200101_000001_test1"))
+	require.Nil(t, migrationsUpTo(migrations, "m200101_000003_test3"))
+}
+
+func TestWriteBaseline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomigrate_squash")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "m200101_000000_test.sql")
+	require.NoError(t, ioutil.WriteFile(source, []byte("-- +gomigrate Up\n"), 0600))
+	squashed := migration.Migrations{&migration.Migration{Version: "m200101_000000_test", Source: source}}
+
+	// baseline can not be placed, originals are kept
+	blocked := filepath.Join(dir, "blocked")
+	require.NoError(t, os.MkdirAll(filepath.Join(blocked, "dir"), 0755))
+	require.Error(t, writeBaseline(blocked, []byte("baseline"), squashed, ""))
+	require.FileExists(t, source)
+	require.NoFileExists(t, blocked+".tmp")
+
+	path := filepath.Join(dir, "m200101_000000_baseline.sql")
+	require.NoError(t, writeBaseline(path, []byte("baseline"), squashed, filepath.Join(dir, "archive")))
+	require.FileExists(t, path)
+	require.NoFileExists(t, source)
+	require.FileExists(t, filepath.Join(dir, "archive", "m200101_000000_test.sql"))
+}
@@ -0,0 +1,6 @@
+-- +gomigrate Up
+CREATE TABLE users (id int NOT NULL);
+CREATE INDEX users_id_idx ON users (id);
+
+-- +gomigrate Down
+DROP TABLE users;
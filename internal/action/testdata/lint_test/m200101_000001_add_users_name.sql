@@ -0,0 +1,6 @@
+-- +gomigrate Up
+ALTER TABLE users ADD COLUMN name text NOT NULL;
+CREATE INDEX users_name_idx ON users (name);
+
+-- +gomigrate Down
+ALTER TABLE users DROP COLUMN name;
@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -23,9 +24,11 @@ func NewToAction(migrationsSvc *service.MigrationService) *ToAction {
 
 type ToActionParams struct {
 	version string
+	atomic  bool
 }
 
 func (p *ToActionParams) ValidateAndFill(args []string) error {
+	args, p.atomic = extractAtomicOption(args)
 	if len(args) == 0 {
 		return errorsInternal.ErrNotEnoughArgs
 	}
@@ -41,10 +44,10 @@ func (p *ToActionParams) ValidateAndFill(args []string) error {
 }
 
 func (p *ToActionParams) Get() interface{} {
-	return &ToActionParams{version: p.version}
+	return &ToActionParams{version: p.version, atomic: p.atomic}
 }
 
-func (a *ToAction) Run(params interface{}) error {
+func (a *ToAction) Run(ctx context.Context, params interface{}) error {
 	p, ok := params.(*ToActionParams)
 	if !ok {
 		return errorsInternal.ErrInvalidActionParamsType
@@ -60,10 +63,10 @@ func (a *ToAction) Run(params interface{}) error {
 		if p.version == migrations[i].Version {
 			upAction := NewUpAction(a.svc)
 			params := new(UpActionParams)
-			if err := params.ValidateAndFill([]string{strconv.Itoa(i + 1)}); err != nil {
+			if err := params.ValidateAndFill(p.limitArgs(i + 1)); err != nil {
 				return err
 			}
-			if err := upAction.Run(params); err != nil {
+			if err := upAction.Run(ctx, params); err != nil {
 				return err
 			}
 
@@ -87,10 +90,10 @@ func (a *ToAction) Run(params interface{}) error {
 		if i != 0 {
 			downAction := NewDownAction(a.svc)
 			params := new(DownActionParams)
-			if err := params.ValidateAndFill([]string{strconv.Itoa(i)}); err != nil {
+			if err := params.ValidateAndFill(p.limitArgs(i)); err != nil {
 				return err
 			}
-			if err := downAction.Run(params); err != nil {
+			if err := downAction.Run(ctx, params); err != nil {
 				return err
 			}
 
@@ -102,5 +105,15 @@ func (a *ToAction) Run(params interface{}) error {
 		return nil
 	}
 
-	return ErrUnableToFindVersion
+	return versionNotFound()
+}
+
+// limitArgs returns up/down args to migrate limit migrations keeping --atomic option.
+func (p *ToActionParams) limitArgs(limit int) []string {
+	args := []string{strconv.Itoa(limit)}
+	if p.atomic {
+		args = append(args, atomicOption)
+	}
+
+	return args
 }
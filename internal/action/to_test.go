@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -43,6 +44,17 @@ func TestToActionParams_ValidateAndFill(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "atomic",
+			args: args{
+				args: []string{atomicOption, "m200101_000000_test"},
+			},
+			expectedParams: &ToActionParams{
+				version: "m200101_000000_test",
+				atomic:  true,
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -79,7 +91,7 @@ func TestToAction_Run(t *testing.T) {
 			a := &ToAction{
 				svc: tt.fields.svc,
 			}
-			if err := a.Run(tt.args.params); (err != nil) != tt.wantErr {
+			if err := a.Run(context.Background(), tt.args.params); (err != nil) != tt.wantErr {
 				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/tweety53/gomigrate/internal/helpers"
@@ -19,14 +20,16 @@ func NewUpAction(migrationsSvc *service.MigrationService) *UpAction {
 }
 
 type UpActionParams struct {
-	limit int
+	limit  int
+	atomic bool
 }
 
 func (p *UpActionParams) Get() interface{} {
-	return &UpActionParams{limit: p.limit}
+	return &UpActionParams{limit: p.limit, atomic: p.atomic}
 }
 
 func (p *UpActionParams) ValidateAndFill(args []string) error {
+	args, p.atomic = extractAtomicOption(args)
 	if len(args) > 0 {
 		var err error
 		p.limit, err = strconv.Atoi(args[0])
@@ -38,7 +41,7 @@ func (p *UpActionParams) ValidateAndFill(args []string) error {
 	return nil
 }
 
-func (a *UpAction) Run(params interface{}) error {
+func (a *UpAction) Run(ctx context.Context, params interface{}) error {
 	p, ok := params.(*UpActionParams)
 	if !ok {
 		return errorsInternal.ErrInvalidActionParamsType
@@ -49,36 +52,116 @@ func (a *UpAction) Run(params interface{}) error {
 		return err
 	}
 
-	if len(migrations) == 0 {
+	repeatableMigrations, err := a.svc.GetChangedRepeatableMigrations()
+	if err != nil {
+		return err
+	}
+
+	if len(migrations) == 0 && len(repeatableMigrations) == 0 {
 		log.Info("No new migrations found. Your system is up-to-date.\n")
 
 		return nil
 	}
 
 	total := len(migrations)
-	if p.limit > 0 {
-		if p.limit > len(migrations) {
-			p.limit = len(migrations)
-		}
+	if p.limit > 0 && p.limit < total {
 		migrations = migrations[0:p.limit]
+		// repeatable migrations run only after all versioned ones
+		repeatableMigrations = nil
 	}
 
 	var logText string
 
 	n := len(migrations)
-	if n == total {
-		logText = helpers.ChooseLogText(n, true)
-		log.Warnf("Total %d new %s to be applied:\n", n, logText)
-	} else {
-		logText = helpers.ChooseLogText(total, true)
-		log.Warnf("Total %d out of %d new %s to be applied:\n", n, total, logText)
+	if n > 0 {
+		if n == total {
+			logText = helpers.ChooseLogText(n, true)
+			log.Warnf("Total %d new %s to be applied:\n", n, logText)
+		} else {
+			logText = helpers.ChooseLogText(total, true)
+			log.Warnf("Total %d out of %d new %s to be applied:\n", n, total, logText)
+		}
+
+		log.Infof("%s", migrations)
+	}
+
+	if len(repeatableMigrations) > 0 {
+		log.Warnf("Total %d changed repeatable %s to be applied:\n",
+			len(repeatableMigrations), helpers.ChooseLogText(len(repeatableMigrations), true))
+		log.Infof("%s", repeatableMigrations)
 	}
 
-	log.Infof("%s", migrations)
+	return a.svc.ObserveRun(ctx, "up", migration.DirectionUp, func(ctx context.Context) error {
+		return migrationFailed(a.migrate(ctx, p, migrations, repeatableMigrations, logText))
+	})
+}
+
+// migrate applies new migrations and then changed repeatable ones.
+func (a *UpAction) migrate(
+	ctx context.Context,
+	p *UpActionParams,
+	migrations migration.Migrations,
+	repeatableMigrations migration.RepeatableMigrations,
+	logText string,
+) error {
+	if len(migrations) > 0 {
+		if err := a.svc.EnsureDataTables(); err != nil {
+			return err
+		}
+
+		if err := setBatch(a.svc, migrations); err != nil {
+			return err
+		}
+	}
+
+	if len(repeatableMigrations) > 0 {
+		if err := a.svc.RepeatableRepo.EnsureTable(); err != nil {
+			return err
+		}
+	}
+
+	err := a.svc.RunWithHooks(ctx, migration.DirectionUp, migration.DirectionUp, func() error {
+		if p.atomic {
+			if err := runAtomic(ctx, a.svc, nil, migrations); err != nil {
+				log.Err("\nAtomic migration failed. Nothing has been applied.\n")
+
+				return err
+			}
+		} else if err := a.upOneByOne(ctx, migrations, logText); err != nil {
+			return err
+		}
+
+		for i := range repeatableMigrations {
+			if err := repeatableMigrations[i].Apply(ctx, a.svc.DB, a.svc.RepeatableRepo); err != nil {
+				log.Err("\nRepeatable migration failed. The rest of the migrations are canceled.\n")
+
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("\n%d applied.\n", len(migrations)+len(repeatableMigrations))
+	log.Info("\nMigrated up successfully.\n")
+
+	if err := a.svc.AutoDumpSchema(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *UpAction) upOneByOne(ctx context.Context, migrations migration.Migrations, logText string) error {
+	n := len(migrations)
+	runner := migration.NewRunner(a.svc.Hooks, a.svc.Observers...)
 
 	var applied int
 	for i := range migrations {
-		if err = migrations[i].Up(a.svc.MigrationsRepo, &migration.Runner{}); err != nil {
+		if err := migrations[i].Up(ctx, a.svc.MigrationsRepo, runner); err != nil {
 			log.Errf("\n%d from %d %s applied.\n", applied, n, logText)
 			log.Err("\nMigration failed. The rest of the migrations are canceled.\n")
 
@@ -90,8 +173,5 @@ func (a *UpAction) Run(params interface{}) error {
 		applied++
 	}
 
-	log.Infof("\n%d applied.\n", n)
-	log.Info("\nMigrated up successfully.\n")
-
 	return nil
 }
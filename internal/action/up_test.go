@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -29,6 +30,12 @@ func TestUpActionParams_ValidateAndFill(t *testing.T) {
 			expectedParams: &UpActionParams{limit: 3},
 			wantErr:        false,
 		},
+		{
+			name:           "atomic with limit",
+			args:           args{args: []string{"3", atomicOption}},
+			expectedParams: &UpActionParams{limit: 3, atomic: true},
+			wantErr:        false,
+		},
 		{
 			name:           "non-numeric limit",
 			args:           args{args: []string{"kek"}},
@@ -71,7 +78,7 @@ func TestUpAction_Run(t *testing.T) {
 			a := &UpAction{
 				svc: tt.fields.svc,
 			}
-			if err := a.Run(tt.args.params); (err != nil) != tt.wantErr {
+			if err := a.Run(context.Background(), tt.args.params); (err != nil) != tt.wantErr {
 				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
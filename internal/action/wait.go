@@ -0,0 +1,189 @@
+package action
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/service"
+	"github.com/tweety53/gomigrate/internal/version"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+var (
+	ErrWaitTimeout     = errors.New("timed out waiting for migrations")
+	ErrDirtyVersion    = errors.New("dirty migration version, migration has not finished or crashed")
+	ErrTimeoutNoValue  = errors.New("--timeout option requires duration, e.g. 60s")
+	ErrInvalidDuration = errors.New("invalid --timeout duration")
+)
+
+const (
+	// WaitLatest waits for all migrations known from migrations path.
+	WaitLatest    = "latest"
+	timeoutOption = "--timeout"
+)
+
+// waitPollInterval is the interval of migrations table polling.
+//
+//nolint:gochecknoglobals // overridden in tests
+var waitPollInterval = time.Second
+
+type WaitAction struct {
+	svc *service.MigrationService
+}
+
+func NewWaitAction(migrationsSvc *service.MigrationService) *WaitAction {
+	return &WaitAction{svc: migrationsSvc}
+}
+
+type WaitActionParams struct {
+	version string
+	timeout time.Duration
+}
+
+func (p *WaitActionParams) ValidateAndFill(args []string) error {
+	args, timeout, err := extractTimeoutOption(args)
+	if err != nil {
+		return err
+	}
+
+	v := WaitLatest
+	if len(args) > 0 && args[0] != WaitLatest {
+		if !version.ValidMigrationVersion(args[0]) {
+			return errorsInternal.ErrInvalidVersionFormat
+		}
+
+		v = args[0]
+	}
+
+	p.version = v
+	p.timeout = timeout
+
+	return nil
+}
+
+func (p *WaitActionParams) Get() interface{} {
+	return &WaitActionParams{version: p.version, timeout: p.timeout}
+}
+
+// extractTimeoutOption cuts `--timeout 60s` (or `--timeout=60s`) out of args.
+func extractTimeoutOption(args []string) ([]string, time.Duration, error) {
+	var (
+		rest  = make([]string, 0, len(args))
+		value string
+	)
+
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == timeoutOption:
+			if i+1 >= len(args) {
+				return nil, 0, ErrTimeoutNoValue
+			}
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], timeoutOption+"="):
+			value = strings.TrimPrefix(args[i], timeoutOption+"=")
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	if value == "" {
+		return rest, 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return nil, 0, errors.Wrap(ErrInvalidDuration, value)
+	}
+
+	return rest, timeout, nil
+}
+
+func (a *WaitAction) Run(ctx context.Context, params interface{}) error {
+	p, ok := params.(*WaitActionParams)
+	if !ok {
+		return errorsInternal.ErrInvalidActionParamsType
+	}
+
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	return a.Wait(ctx, p.version)
+}
+
+// Wait polls migrations table until version (or all known migrations for WaitLatest) is applied.
+// When ctx is done, error with DirtyVersion exit code is returned if dirty versions are left,
+// otherwise with WaitTimeout one.
+func (a *WaitAction) Wait(ctx context.Context, v string) error {
+	log.Infof("Waiting for %s migration version to be applied...\n", v)
+
+	for {
+		reached, dirty, err := a.reached(v)
+		if err != nil {
+			return err
+		}
+
+		if reached {
+			log.Infof("Migration version %s has been applied.\n", v)
+
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if len(dirty) > 0 {
+				return &errorsInternal.GoMigrateError{
+					Err:      errors.Wrap(ErrDirtyVersion, strings.Join(dirty, ", ")),
+					ExitCode: exitcode.DirtyVersion,
+				}
+			}
+
+			return &errorsInternal.GoMigrateError{Err: errors.Wrap(ErrWaitTimeout, v), ExitCode: exitcode.WaitTimeout}
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+// reached reports whether version v is applied, dirty versions are returned too.
+func (a *WaitAction) reached(v string) (bool, []string, error) {
+	records, err := a.svc.MigrationsRepo.GetMigrationsHistory(0)
+	if err != nil {
+		return false, nil, errors.Wrap(err, "cannot get migrations history from db")
+	}
+
+	var (
+		dirty   []string
+		applied bool
+	)
+
+	for _, record := range records {
+		if record.Version == migration.BaseMigrationVersion {
+			continue
+		}
+
+		if record.ApplyTime == 0 {
+			dirty = append(dirty, record.Version)
+		} else if record.Version == v {
+			applied = true
+		}
+	}
+
+	if v != WaitLatest {
+		return applied, dirty, nil
+	}
+
+	pending, err := a.svc.GetNewMigrations()
+	if err != nil {
+		return false, nil, err
+	}
+
+	return len(pending) == 0 && len(dirty) == 0, dirty, nil
+}
@@ -0,0 +1,166 @@
+package action
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gojuno/minimock/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/service"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+func TestWaitActionParams_ValidateAndFill(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		expectedParams *WaitActionParams
+		wantErr        error
+	}{
+		{
+			name:           "latest by default",
+			args:           []string{},
+			expectedParams: &WaitActionParams{version: WaitLatest},
+		},
+		{
+			name:           "version with timeout",
+			args:           []string{"m200101_000000_test", "--timeout", "60s"},
+			expectedParams: &WaitActionParams{version: "m200101_000000_test", timeout: time.Minute},
+		},
+		{
+			name:           "latest with timeout",
+			args:           []string{"--timeout=5m", "latest"},
+			expectedParams: &WaitActionParams{version: WaitLatest, timeout: 5 * time.Minute},
+		},
+		{
+			name:           "invalid version pattern",
+			args:           []string{"m200101_000000_+1"},
+			expectedParams: &WaitActionParams{},
+			wantErr:        errorsInternal.ErrInvalidVersionFormat,
+		},
+		{
+			name:           "timeout without value",
+			args:           []string{"--timeout"},
+			expectedParams: &WaitActionParams{},
+			wantErr:        ErrTimeoutNoValue,
+		},
+		{
+			name:           "invalid timeout",
+			args:           []string{"--timeout", "soon"},
+			expectedParams: &WaitActionParams{},
+			wantErr:        ErrInvalidDuration,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &WaitActionParams{}
+			err := p.ValidateAndFill(tt.args)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.expectedParams, p)
+		})
+	}
+}
+
+func TestWaitAction_Run(t *testing.T) {
+	defer func(interval time.Duration) { waitPollInterval = interval }(waitPollInterval)
+	waitPollInterval = time.Millisecond
+
+	svc := func(history ...repo.MigrationRecords) *service.MigrationService {
+		mc := minimock.NewController(t)
+		mRepo := repo.NewMigrationRepoMock(mc).GetDBVersionMock.Return("m200101_000001_test", nil)
+		calls := 0
+		mRepo.GetMigrationsHistoryMock.Set(func(_ int) (repo.MigrationRecords, error) {
+			records := history[len(history)-1]
+			if calls/2 < len(history) {
+				records = history[calls/2]
+			}
+			calls++
+
+			return records, nil
+		})
+
+		return &service.MigrationService{
+			MigrationsRepo: mRepo,
+			MigrationsCollector: migration.NewMigrationsCollectorInterfaceMock(mc).
+				CollectMigrationsMock.Return(migration.Migrations{
+				&migration.Migration{Version: "m200101_000000_test"},
+				&migration.Migration{Version: "m200101_000001_test"},
+			}, nil),
+		}
+	}
+	applied := repo.MigrationRecords{
+		&repo.MigrationRecord{Version: "m200101_000001_test", ApplyTime: 2},
+		&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+	}
+	dirty := repo.MigrationRecords{
+		&repo.MigrationRecord{Version: "m200101_000001_test", ApplyTime: 0},
+		&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+	}
+	pending := repo.MigrationRecords{
+		&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+	}
+
+	tests := []struct {
+		name         string
+		svc          *service.MigrationService
+		params       interface{}
+		wantErr      error
+		wantExitCode exitcode.ExitCode
+	}{
+		{
+			name:         "invalid action params type passed",
+			params:       struct{}{},
+			wantErr:      errorsInternal.ErrInvalidActionParamsType,
+			wantExitCode: exitcode.Unspecified,
+		},
+		{
+			name:         "latest reached after polls",
+			svc:          svc(pending, dirty, applied),
+			params:       &WaitActionParams{version: WaitLatest, timeout: time.Second},
+			wantExitCode: exitcode.OK,
+		},
+		{
+			name:         "version reached",
+			svc:          svc(pending),
+			params:       &WaitActionParams{version: "m200101_000000_test", timeout: time.Second},
+			wantExitCode: exitcode.OK,
+		},
+		{
+			name:         "timeout",
+			svc:          svc(pending),
+			params:       &WaitActionParams{version: WaitLatest, timeout: 10 * time.Millisecond},
+			wantErr:      ErrWaitTimeout,
+			wantExitCode: exitcode.WaitTimeout,
+		},
+		{
+			name:         "timeout with dirty version",
+			svc:          svc(dirty),
+			params:       &WaitActionParams{version: "m200101_000001_test", timeout: 10 * time.Millisecond},
+			wantErr:      ErrDirtyVersion,
+			wantExitCode: exitcode.DirtyVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewWaitAction(tt.svc).Run(context.Background(), tt.params)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.wantExitCode, errorsInternal.ErrorExitCode(err))
+		})
+	}
+}
+
+func TestWaitAction_WaitCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	a := NewWaitAction(&service.MigrationService{
+		MigrationsRepo: repo.NewMigrationRepoMock(minimock.NewController(t)).
+			GetMigrationsHistoryMock.Return(repo.MigrationRecords{}, nil),
+	})
+	err := a.Wait(ctx, "m200101_000000_test")
+	require.ErrorIs(t, err, ErrWaitTimeout)
+}
@@ -28,6 +28,23 @@ func AskForConfirmation(text string) bool {
 	return processResponse(response)
 }
 
+// AskForDatabaseName asks user to type the database name and reports whether it matches dbName.
+func AskForDatabaseName(dbName string) bool {
+	internalLog.Warnf("This action is destructive. Please type the database name (%s) to confirm:", dbName)
+
+	var response string
+
+	if _, err := fmt.Scanln(&response); err != nil {
+		return false
+	}
+
+	return processDatabaseName(response, dbName)
+}
+
+func processDatabaseName(response string, dbName string) bool {
+	return dbName != "" && strings.TrimSpace(response) == dbName
+}
+
 func processResponse(response string) bool {
 	switch strings.ToLower(response) {
 	case "y", "yes":
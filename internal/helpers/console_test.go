@@ -76,6 +76,51 @@ func Test_processResponse(t *testing.T) {
 	}
 }
 
+func Test_processDatabaseName(t *testing.T) {
+	type args struct {
+		response string
+		dbName   string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "matched",
+			args: args{response: "gomigrate_prod", dbName: "gomigrate_prod"},
+			want: true,
+		},
+		{
+			name: "matched with spaces",
+			args: args{response: " gomigrate_prod ", dbName: "gomigrate_prod"},
+			want: true,
+		},
+		{
+			name: "case mismatch",
+			args: args{response: "GOMIGRATE_PROD", dbName: "gomigrate_prod"},
+			want: false,
+		},
+		{
+			name: "yes is not enough",
+			args: args{response: "y", dbName: "gomigrate_prod"},
+			want: false,
+		},
+		{
+			name: "empty db name",
+			args: args{response: "", dbName: ""},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := processDatabaseName(tt.args.response, tt.args.dbName); got != tt.want {
+				t.Errorf("processDatabaseName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestChooseLogText(t *testing.T) {
 	type args struct {
 		n         int
@@ -0,0 +1,205 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type Severity string
+
+const (
+	SeverityOff     Severity = "off"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+type Rule string
+
+const (
+	RuleParseError                     Rule = "parse-error"
+	RuleMissingDown                    Rule = "missing-down"
+	RuleCreateIndexNotConcurrently     Rule = "create-index-not-concurrently"
+	RuleConcurrentlyInTransaction      Rule = "concurrently-in-transaction"
+	RuleAddColumnNotNullWithoutDefault Rule = "add-column-not-null-without-default"
+	RuleAlterColumnType                Rule = "alter-column-type"
+	RuleDropColumn                     Rule = "drop-column"
+	RuleDropTable                      Rule = "drop-table"
+	RuleTemplatePlaceholder            Rule = "template-placeholder"
+)
+
+var (
+	ErrUnknownRule     = errors.New("unknown lint rule")
+	ErrUnknownSeverity = errors.New("unknown lint severity, must be one of off, warning, error")
+)
+
+// defaultSeverities contains all the rules, rules may be reconfigured with gomigrate_lint_rules.
+var defaultSeverities = map[Rule]Severity{
+	RuleParseError:                     SeverityError,
+	RuleMissingDown:                    SeverityWarning,
+	RuleCreateIndexNotConcurrently:     SeverityError,
+	RuleConcurrentlyInTransaction:      SeverityError,
+	RuleAddColumnNotNullWithoutDefault: SeverityError,
+	RuleAlterColumnType:                SeverityWarning,
+	RuleDropColumn:                     SeverityWarning,
+	RuleDropTable:                      SeverityWarning,
+	RuleTemplatePlaceholder:            SeverityError,
+}
+
+// Migration is parsed SQL migration to be linted.
+type Migration struct {
+	Name         string
+	Up           []string
+	Down         []string
+	UpTx         bool
+	DownTx       bool
+	Irreversible bool
+}
+
+type Finding struct {
+	Rule      Rule
+	Severity  Severity
+	Migration string
+	Direction string
+	Statement string
+	Message   string
+}
+
+func (f *Finding) String() string {
+	location := f.Migration
+	if f.Direction != "" {
+		location += " (" + f.Direction + ")"
+	}
+
+	text := fmt.Sprintf("%s: %s [%s] %s", f.Severity, location, f.Rule, f.Message)
+	if f.Statement != "" {
+		text += ":\n\t" + f.Statement
+	}
+
+	return text
+}
+
+type Findings []*Finding
+
+// HasErrors reports whether findings contain error severity ones.
+func (fs Findings) HasErrors() bool {
+	for _, f := range fs {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Linter struct {
+	severities map[Rule]Severity
+}
+
+// NewLinter returns linter with default rules severities overridden by given ones, e.g. {"drop-table": "error"}.
+func NewLinter(rules map[string]string) (*Linter, error) {
+	severities := make(map[Rule]Severity, len(defaultSeverities))
+	for rule, severity := range defaultSeverities {
+		severities[rule] = severity
+	}
+
+	for rule, severity := range rules {
+		if _, ok := defaultSeverities[Rule(rule)]; !ok {
+			return nil, errors.Wrap(ErrUnknownRule, rule)
+		}
+
+		switch s := Severity(severity); s {
+		case SeverityOff, SeverityWarning, SeverityError:
+			severities[Rule(rule)] = s
+		default:
+			return nil, errors.Wrapf(ErrUnknownSeverity, "%s: %s", rule, severity)
+		}
+	}
+
+	return &Linter{severities: severities}, nil
+}
+
+// ParseError returns finding for migration which can not be parsed.
+func (l *Linter) ParseError(name string, err error) Findings {
+	return l.report(nil, &Finding{Rule: RuleParseError, Migration: name, Message: err.Error()})
+}
+
+// Lint checks migration statements against enabled rules.
+func (l *Linter) Lint(m *Migration) Findings {
+	var findings Findings
+
+	if !m.Irreversible && len(m.Up) > 0 && len(m.Down) == 0 {
+		findings = l.report(findings, &Finding{
+			Rule:      RuleMissingDown,
+			Migration: m.Name,
+			Message:   "migration has no Down statements, add them or mark migration with '-- +gomigrate Irreversible'",
+		})
+	}
+
+	findings = append(findings, l.lintStatements(m.Name, "up", m.Up, m.UpTx)...)
+	findings = append(findings, l.lintStatements(m.Name, "down", m.Down, m.DownTx)...)
+
+	return findings
+}
+
+func (l *Linter) lintStatements(name, direction string, statements []string, useTx bool) Findings {
+	var findings Findings
+
+	created := createdTables(statements)
+	for _, stmt := range statements {
+		ignored := ignoredRules(stmt)
+		for _, f := range checkStatement(normalize(stmt), direction, useTx, created) {
+			if ignored[f.Rule] || ignored["all"] {
+				continue
+			}
+
+			f.Migration = name
+			f.Direction = direction
+			f.Statement = strings.TrimSpace(stmt)
+			findings = l.report(findings, f)
+		}
+	}
+
+	return findings
+}
+
+func (l *Linter) report(findings Findings, f *Finding) Findings {
+	severity := l.severities[f.Rule]
+	if severity == SeverityOff {
+		return findings
+	}
+
+	f.Severity = severity
+
+	return append(findings, f)
+}
+
+var (
+	matchIgnore      = regexp.MustCompile(`--\s*gomigrate:ignore\s+([^\n]+)`)
+	matchLineComment = regexp.MustCompile(`--[^\n]*`)
+	matchBlock       = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	matchSpaces      = regexp.MustCompile(`\s+`)
+)
+
+// ignoredRules returns rules listed in '-- gomigrate:ignore rule[, rule]' directives of the statement.
+func ignoredRules(stmt string) map[Rule]bool {
+	ignored := map[Rule]bool{}
+	for _, m := range matchIgnore.FindAllStringSubmatch(stmt, -1) {
+		for _, rule := range strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			ignored[Rule(rule)] = true
+		}
+	}
+
+	return ignored
+}
+
+// normalize strips comments, collapses whitespaces and uppercases the statement.
+func normalize(stmt string) string {
+	stmt = matchBlock.ReplaceAllString(stmt, " ")
+	stmt = matchLineComment.ReplaceAllString(stmt, " ")
+	stmt = matchSpaces.ReplaceAllString(stmt, " ")
+
+	return strings.ToUpper(strings.TrimSpace(stmt))
+}
@@ -0,0 +1,132 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinter_Lint(t *testing.T) {
+	tests := []struct {
+		name string
+		m    *Migration
+		want []Rule
+	}{
+		{
+			name: "safe migration",
+			m: &Migration{
+				Up: []string{
+					"CREATE TABLE users (id int NOT NULL);\n",
+					"CREATE INDEX users_id_idx ON users (id);\n",
+					"ALTER TABLE users ADD COLUMN name text NOT NULL;\n",
+				},
+				Down: []string{"DROP TABLE users;\n"},
+				UpTx: true,
+			},
+		},
+		{
+			name: "missing down",
+			m:    &Migration{Up: []string{"CREATE TABLE users (id int);\n"}},
+			want: []Rule{RuleMissingDown},
+		},
+		{
+			name: "irreversible without down",
+			m:    &Migration{Up: []string{"DELETE FROM users;\n"}, Irreversible: true},
+		},
+		{
+			name: "create index on existing table",
+			m: &Migration{
+				Up:   []string{"CREATE UNIQUE INDEX users_name_idx ON users (name);\n"},
+				Down: []string{"DROP INDEX users_name_idx;\n"},
+			},
+			want: []Rule{RuleCreateIndexNotConcurrently},
+		},
+		{
+			name: "concurrently in transaction",
+			m: &Migration{
+				Up:     []string{"CREATE INDEX CONCURRENTLY users_name_idx ON users (name);\n"},
+				Down:   []string{"DROP INDEX CONCURRENTLY users_name_idx;\n"},
+				UpTx:   true,
+				DownTx: false,
+			},
+			want: []Rule{RuleConcurrentlyInTransaction},
+		},
+		{
+			name: "add column not null",
+			m: &Migration{
+				Up: []string{
+					"ALTER TABLE users ADD COLUMN age int NOT NULL, ADD COLUMN rank int NOT NULL DEFAULT 0, ADD CONSTRAINT users_uniq UNIQUE (name, age);\n",
+				},
+				Down: []string{"ALTER TABLE users DROP COLUMN age, DROP COLUMN rank;\n"},
+			},
+			want: []Rule{RuleAddColumnNotNullWithoutDefault},
+		},
+		{
+			name: "alter column type",
+			m: &Migration{
+				Up:   []string{"ALTER TABLE users ALTER COLUMN age TYPE bigint;\n"},
+				Down: []string{"ALTER TABLE users ALTER COLUMN age SET DATA TYPE int;\n"},
+			},
+			want: []Rule{RuleAlterColumnType, RuleAlterColumnType},
+		},
+		{
+			name: "drop in up",
+			m: &Migration{
+				Up:   []string{"ALTER TABLE users DROP COLUMN age, ALTER COLUMN name DROP DEFAULT;\n", "DROP TABLE posts;\n"},
+				Down: []string{"ALTER TABLE users ADD COLUMN age int;\n", "CREATE TABLE posts (id int);\n"},
+			},
+			want: []Rule{RuleDropColumn, RuleDropTable},
+		},
+		{
+			name: "template placeholder",
+			m: &Migration{
+				Up:   []string{"-- +gomigrate StatementBegin\n// write down up SQL here\n-- +gomigrate StatementEnd\n"},
+				Down: []string{"-- +gomigrate StatementBegin\n// write down down SQL here\n-- +gomigrate StatementEnd\n"},
+			},
+			want: []Rule{RuleTemplatePlaceholder, RuleTemplatePlaceholder},
+		},
+		{
+			name: "ignore directives",
+			m: &Migration{
+				Up: []string{
+					"-- gomigrate:ignore drop-table\nDROP TABLE posts;\n",
+					"ALTER TABLE users DROP COLUMN age; -- gomigrate:ignore drop-column, alter-column-type\n",
+					"-- gomigrate:ignore all\nDROP TABLE comments;\n",
+					"-- gomigrate:ignore drop-column\nDROP TABLE tags;\n",
+				},
+				Down: []string{"SELECT 1;\n"},
+			},
+			want: []Rule{RuleDropTable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewLinter(nil)
+			require.NoError(t, err)
+
+			var got []Rule
+			for _, f := range l.Lint(tt.m) {
+				got = append(got, f.Rule)
+			}
+
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewLinter(t *testing.T) {
+	l, err := NewLinter(map[string]string{"drop-table": "error", "missing-down": "off"})
+	require.NoError(t, err)
+
+	findings := l.Lint(&Migration{Up: []string{"DROP TABLE posts;\n"}})
+	require.Len(t, findings, 1)
+	require.Equal(t, RuleDropTable, findings[0].Rule)
+	require.Equal(t, SeverityError, findings[0].Severity)
+	require.True(t, findings.HasErrors())
+
+	_, err = NewLinter(map[string]string{"no-such-rule": "error"})
+	require.ErrorIs(t, err, ErrUnknownRule)
+
+	_, err = NewLinter(map[string]string{"drop-table": "fatal"})
+	require.ErrorIs(t, err, ErrUnknownSeverity)
+}
@@ -0,0 +1,125 @@
+package lint
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	matchCreateTable  = regexp.MustCompile(`^CREATE (?:UNLOGGED |TEMP |TEMPORARY )?TABLE (?:IF NOT EXISTS )?([^\s(]+)`)
+	matchCreateIndex  = regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX\b`)
+	matchIndexTable   = regexp.MustCompile(`\bON (?:ONLY )?([^\s(]+)`)
+	matchConcurrently = regexp.MustCompile(`^(?:CREATE (?:UNIQUE )?INDEX|DROP INDEX|REINDEX\b.*) CONCURRENTLY\b`)
+	matchAlterTable   = regexp.MustCompile(`^ALTER TABLE (?:IF EXISTS )?(?:ONLY )?([^\s]+) (.*)$`)
+	matchAddColumn    = regexp.MustCompile(`^ADD (?:COLUMN )?(?:IF NOT EXISTS )?`)
+	matchAddNotColumn = regexp.MustCompile(`^ADD (?:CONSTRAINT|PRIMARY|UNIQUE|FOREIGN|CHECK|EXCLUDE)\b`)
+	matchAlterType    = regexp.MustCompile(`^ALTER (?:COLUMN )?\S+ (?:SET DATA )?TYPE\b`)
+	matchDropColumn   = regexp.MustCompile(`^DROP (?:COLUMN )?(?:IF EXISTS )?\S+`)
+	matchDropNotCol   = regexp.MustCompile(`^DROP (?:CONSTRAINT|DEFAULT|NOT NULL)\b`)
+	matchDropTable    = regexp.MustCompile(`^DROP TABLE\b`)
+	// matchPlaceholder matches text of create action SQL skeletons.
+	matchPlaceholder = regexp.MustCompile(`WRITE DOWN (?:UP|DOWN) SQL HERE`)
+)
+
+// checkStatement returns rules violated by normalized statement, tables created in the same
+// migration are new ones and may be changed freely.
+func checkStatement(stmt, direction string, useTx bool, created map[string]bool) Findings {
+	var findings Findings
+
+	if matchPlaceholder.MatchString(stmt) {
+		findings = append(findings, &Finding{
+			Rule:    RuleTemplatePlaceholder,
+			Message: "migration template placeholder text is left in the file",
+		})
+	}
+
+	if useTx && matchConcurrently.MatchString(stmt) {
+		findings = append(findings, &Finding{
+			Rule:    RuleConcurrentlyInTransaction,
+			Message: "CONCURRENTLY can not run inside a transaction, add '-- +gomigrate NO TRANSACTION' annotation",
+		})
+	}
+
+	if matchCreateIndex.MatchString(stmt) && !strings.Contains(stmt, " CONCURRENTLY ") {
+		if m := matchIndexTable.FindStringSubmatch(stmt); m != nil && !created[tableName(m[1])] {
+			findings = append(findings, &Finding{
+				Rule:    RuleCreateIndexNotConcurrently,
+				Message: "CREATE INDEX without CONCURRENTLY locks writes to existing table",
+			})
+		}
+	}
+
+	if direction == "up" && matchDropTable.MatchString(stmt) {
+		findings = append(findings, &Finding{Rule: RuleDropTable, Message: "DROP TABLE loses data"})
+	}
+
+	m := matchAlterTable.FindStringSubmatch(stmt)
+	if m == nil || created[tableName(m[1])] {
+		return findings
+	}
+
+	for _, clause := range splitTopLevel(m[2]) {
+		switch {
+		case matchAddColumn.MatchString(clause) && !matchAddNotColumn.MatchString(clause):
+			if strings.Contains(clause, " NOT NULL") && !strings.Contains(clause, " DEFAULT ") {
+				findings = append(findings, &Finding{
+					Rule:    RuleAddColumnNotNullWithoutDefault,
+					Message: "ADD COLUMN NOT NULL without DEFAULT fails on non-empty table",
+				})
+			}
+		case matchAlterType.MatchString(clause):
+			findings = append(findings, &Finding{
+				Rule:    RuleAlterColumnType,
+				Message: "ALTER COLUMN TYPE may rewrite the whole table under exclusive lock",
+			})
+		case direction == "up" && matchDropColumn.MatchString(clause) && !matchDropNotCol.MatchString(clause):
+			findings = append(findings, &Finding{
+				Rule:    RuleDropColumn,
+				Message: "DROP COLUMN loses data and breaks running application instances still using it",
+			})
+		}
+	}
+
+	return findings
+}
+
+// createdTables returns tables created by statements.
+func createdTables(statements []string) map[string]bool {
+	created := map[string]bool{}
+	for _, stmt := range statements {
+		if m := matchCreateTable.FindStringSubmatch(normalize(stmt)); m != nil {
+			created[tableName(m[1])] = true
+		}
+	}
+
+	return created
+}
+
+func tableName(name string) string {
+	return strings.Trim(strings.TrimSuffix(name, ";"), `"`)
+}
+
+// splitTopLevel splits ALTER TABLE actions by commas outside of parentheses.
+func splitTopLevel(s string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, strings.TrimSpace(strings.TrimSuffix(s[start:], ";")))
+}
@@ -0,0 +1,114 @@
+// Package metrics collects Prometheus metrics of migration runs from runner events.
+package metrics
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/tweety53/gomigrate/internal/migration"
+)
+
+const (
+	namespace = "gomigrate"
+	// pushJob is the job label of metrics pushed to Pushgateway.
+	pushJob = "gomigrate"
+)
+
+// Metrics is migration.Observer updating migrations counters, durations and last success time.
+type Metrics struct {
+	registry    *prometheus.Registry
+	applied     prometheus.Counter
+	reverted    prometheus.Counter
+	failed      *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	pending     prometheus.Gauge
+	lastSuccess prometheus.Gauge
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		applied: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "migrations_applied_total",
+			Help:      "Total number of applied migrations.",
+		}),
+		reverted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "migrations_reverted_total",
+			Help:      "Total number of reverted migrations.",
+		}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "migrations_failed_total",
+			Help:      "Total number of failed migrations by direction.",
+		}, []string{"direction"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "migration_duration_seconds",
+			Help:      "Duration of migrations by direction.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 900},
+		}, []string{"direction"}),
+		pending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pending_migrations",
+			Help:      "Number of not applied migrations.",
+		}),
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Unix time of the last successful migrations run.",
+		}),
+	}
+
+	m.registry.MustRegister(m.applied, m.reverted, m.failed, m.duration, m.pending, m.lastSuccess)
+
+	return m
+}
+
+// Registry returns registry with gomigrate metrics only.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+func (m *Metrics) Notify(e *migration.Event) {
+	switch e.Type {
+	case migration.EventMigrationApplied:
+		m.applied.Inc()
+		m.duration.WithLabelValues(string(e.Direction)).Observe(e.Duration.Seconds())
+	case migration.EventMigrationReverted:
+		m.reverted.Inc()
+		m.duration.WithLabelValues(string(e.Direction)).Observe(e.Duration.Seconds())
+	case migration.EventMigrationFailed:
+		m.failed.WithLabelValues(string(e.Direction)).Inc()
+		m.duration.WithLabelValues(string(e.Direction)).Observe(e.Duration.Seconds())
+	case migration.EventRunFinished:
+		if e.Err == nil {
+			m.lastSuccess.Set(float64(time.Now().Unix()))
+		}
+	case migration.EventRunStarted, migration.EventMigrationStarted, migration.EventStatementExecuted:
+	}
+}
+
+// SetPending sets number of not applied migrations.
+func (m *Metrics) SetPending(n int) {
+	m.pending.Set(float64(n))
+}
+
+// SetLastSuccess sets time of the last successful run, e.g. of up which found nothing to apply and did not
+// start a run.
+func (m *Metrics) SetLastSuccess(t time.Time) {
+	m.lastSuccess.Set(float64(t.Unix()))
+}
+
+// WriteTextfile writes metrics in node_exporter textfile collector format.
+func (m *Metrics) WriteTextfile(path string) error {
+	return errors.Wrapf(prometheus.WriteToTextfile(path, m.registry), "cannot write metrics to %s", path)
+}
+
+// Push replaces metrics of gomigrate job in Pushgateway-compatible endpoint.
+func (m *Metrics) Push(url string) error {
+	return errors.Wrapf(push.New(url, pushJob).Gatherer(m.registry).Push(), "cannot push metrics to %s", url)
+}
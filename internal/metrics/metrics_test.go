@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/migration"
+)
+
+func TestMetrics_Notify(t *testing.T) {
+	m := New()
+	events := []*migration.Event{
+		{Type: migration.EventRunStarted, Direction: migration.DirectionUp},
+		{Type: migration.EventMigrationStarted, Direction: migration.DirectionUp},
+		{Type: migration.EventMigrationApplied, Direction: migration.DirectionUp, Duration: time.Second},
+		{Type: migration.EventMigrationApplied, Direction: migration.DirectionUp, Duration: time.Second},
+		{Type: migration.EventMigrationReverted, Direction: migration.DirectionDown, Duration: time.Second},
+		{Type: migration.EventMigrationFailed, Direction: migration.DirectionDown, Err: errors.New("kek")},
+		{Type: migration.EventRunFinished, Direction: migration.DirectionUp, Err: errors.New("kek")},
+	}
+	for _, e := range events {
+		m.Notify(e)
+	}
+
+	require.Equal(t, float64(2), testutil.ToFloat64(m.applied))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.reverted))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.failed.WithLabelValues("down")))
+	require.Equal(t, float64(0), testutil.ToFloat64(m.failed.WithLabelValues("up")))
+	require.Equal(t, 2, testutil.CollectAndCount(m.duration))
+	require.Equal(t, float64(0), testutil.ToFloat64(m.lastSuccess))
+
+	m.Notify(&migration.Event{Type: migration.EventRunFinished, Direction: migration.DirectionUp})
+	require.InDelta(t, float64(time.Now().Unix()), testutil.ToFloat64(m.lastSuccess), 5)
+
+	m.SetPending(3)
+	require.Equal(t, float64(3), testutil.ToFloat64(m.pending))
+
+	m.SetLastSuccess(time.Unix(100, 0))
+	require.Equal(t, float64(100), testutil.ToFloat64(m.lastSuccess))
+}
+
+func TestMetrics_WriteTextfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomigrate_metrics")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	m := New()
+	m.SetPending(2)
+
+	path := filepath.Join(dir, "gomigrate.prom")
+	require.NoError(t, m.WriteTextfile(path))
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "gomigrate_pending_migrations 2")
+
+	require.Error(t, m.WriteTextfile(filepath.Join(dir, "not_exists", "gomigrate.prom")))
+}
+
+func TestMetrics_Push(t *testing.T) {
+	var (
+		gotPath string
+		gotBody string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotPath, gotBody = r.URL.Path, string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	m := New()
+	m.SetPending(2)
+	require.NoError(t, m.Push(srv.URL))
+	require.Equal(t, "/metrics/job/gomigrate", gotPath)
+	require.True(t, strings.Contains(gotBody, "gomigrate_pending_migrations"))
+}
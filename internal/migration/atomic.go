@@ -0,0 +1,157 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/repo"
+)
+
+var (
+	ErrNonTransactional = errors.New("migration is non-transactional")
+	ErrAtomicEachHooks  = errors.New("before_each/after_each hooks are not supported in atomic mode")
+)
+
+type atomicStep struct {
+	m         *Migration
+	direction Direction
+	fn        func(context.Context, *sql.Tx) error
+}
+
+// RunAtomic reverts down migrations and then applies up ones in a single transaction
+// along with their version writes. It refuses to start if any of migrations is non-transactional.
+// Migration spans are attached to the span of ctx.
+func RunAtomic(ctx context.Context, r *repo.MigrationsRepository, hooks *Hooks, observers Observers, down, up Migrations) error {
+	if hooks.Has(HookBeforeEach) || hooks.Has(HookAfterEach) {
+		return ErrAtomicEachHooks
+	}
+
+	steps := make([]atomicStep, 0, len(down)+len(up))
+	for _, m := range down {
+		fn, err := m.safeFn(DirectionDown, statementNotifier(observers, m, DirectionDown, TxModeAtomic))
+		if err != nil {
+			return err
+		}
+
+		steps = append(steps, atomicStep{m: m, direction: DirectionDown, fn: fn})
+	}
+
+	for _, m := range up {
+		fn, err := m.safeFn(DirectionUp, statementNotifier(observers, m, DirectionUp, TxModeAtomic))
+		if err != nil {
+			return err
+		}
+
+		steps = append(steps, atomicStep{m: m, direction: DirectionUp, fn: fn})
+	}
+
+	db, err := r.GetDB()
+	if err != nil {
+		return errors.Wrap(err, "db not initialized")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	txRepo := r.WithTx(tx)
+	for _, s := range steps {
+		if err := s.observed(ctx, observers, txRepo, tx); err != nil {
+			if txErr := tx.Rollback(); txErr != nil && !errors.Is(txErr, sql.ErrTxDone) {
+				return errors.Wrapf(err, "failed to rollback transaction: %v", txErr)
+			}
+
+			log.Err("All changes of the atomic run were rolled back.\n")
+
+			return err
+		}
+	}
+
+	return errors.Wrap(tx.Commit(), "failed to commit transaction")
+}
+
+// observed runs step notifying observers about its start and result and traces it.
+func (s atomicStep) observed(ctx context.Context, observers Observers, r repo.MigrationRepo, tx *sql.Tx) error {
+	observers.Notify(migrationEvent(EventMigrationStarted, s.m, s.direction, TxModeAtomic))
+	ctx, span := startMigrationSpan(ctx, s.m, s.direction, TxModeAtomic)
+
+	start := time.Now()
+	err := s.run(ctx, withContext(ctx, r), tx)
+	span.End(err)
+
+	observers.Notify(migrationFinishedEvent(s.m, s.direction, TxModeAtomic, time.Since(start), err))
+
+	return err
+}
+
+func (s atomicStep) run(ctx context.Context, r repo.MigrationRepo, tx *sql.Tx) error {
+	if s.direction == DirectionUp {
+		if err := s.fn(ctx, tx); err != nil {
+			return errors.Wrapf(err, "failed to apply %s", s.m.Version)
+		}
+
+		if err := r.InsertVersion(s.m.Version); err != nil {
+			return errors.Wrap(err, "failed to insert migration version")
+		}
+
+		return s.m.saveApplied(r)
+	}
+
+	if err := r.LockVersion(s.m.Version); err != nil {
+		return lockError(errors.Wrap(err, "failed to lock migration version"))
+	}
+
+	if err := s.fn(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to revert %s", s.m.Version)
+	}
+
+	if err := r.DeleteVersion(s.m.Version); err != nil {
+		return errors.Wrapf(err, "failed to delete migration version %s", s.m.Version)
+	}
+
+	return s.m.deleteReverted(r)
+}
+
+// safeFn returns transactional func of migration for given direction, SQL statements are run with its ctx.
+func (m *Migration) safeFn(direction Direction, onExec statementFunc) (func(context.Context, *sql.Tx) error, error) {
+	switch {
+	case m.IsSQL():
+		statements, useTx, err := m.parseSQL(direction)
+		if err != nil {
+			return nil, err
+		}
+
+		if !useTx {
+			return nil, errors.Wrapf(ErrNonTransactional, "%s", m.Version)
+		}
+
+		return assembleSafeFnFromStatements(statements, onExec), nil
+	case filepath.Ext(m.Source) == ".go":
+		if !m.Registered {
+			return nil, errors.Errorf("not registered %v", m.Source)
+		}
+
+		safeFn, fn := m.SafeUpFn, m.UpFn
+		if direction == DirectionDown {
+			safeFn, fn = m.SafeDownFn, m.DownFn
+		}
+
+		switch {
+		case safeFn != nil:
+			return func(_ context.Context, tx *sql.Tx) error { return safeFn(tx) }, nil
+		case fn != nil:
+			return nil, errors.Wrapf(ErrNonTransactional, "%s", m.Version)
+		case direction == DirectionDown:
+			return nil, errors.Wrapf(ErrIrreversible, "%s", m.Version)
+		}
+
+		return nil, errors.Errorf("unexpected nil on both SafeUpFn UpFn of %s", m.Version)
+	}
+
+	return nil, errors.Errorf("unsupported migration file %v", m.Source)
+}
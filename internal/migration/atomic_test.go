@@ -0,0 +1,155 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+)
+
+func TestRunAtomic(t *testing.T) {
+	safe := func(version string, fnErr error) *Migration {
+		return &Migration{
+			Version:    version,
+			Source:     version + ".go",
+			Registered: true,
+			SafeUpFn:   func(*sql.Tx) error { return fnErr },
+			SafeDownFn: func(*sql.Tx) error { return fnErr },
+		}
+	}
+	errSome := errors.New("some error")
+	nonTx := &Migration{
+		Version:    "m200101_000002_test",
+		Source:     "m200101_000002_test.go",
+		Registered: true,
+		UpFn:       func(*sql.DB) error { return nil },
+	}
+
+	insertVersion := regexp.QuoteMeta("INSERT INTO migration (version, apply_time) VALUES ($1, $2);")
+	deleteVersion := regexp.QuoteMeta("DELETE FROM migration WHERE version=$1;")
+	lockVersion := regexp.QuoteMeta("SELECT * FROM migration WHERE version=$1 FOR UPDATE NOWAIT;")
+	saveDownScript := regexp.QuoteMeta("INSERT INTO migration_down (version, statements, use_tx) VALUES ($1, $2, $3)")
+	deleteDownScript := regexp.QuoteMeta("DELETE FROM migration_down WHERE version=$1;")
+	saveBatch := regexp.QuoteMeta("INSERT INTO migration_batch (version, batch) VALUES ($1, $2)")
+	deleteBatch := regexp.QuoteMeta("DELETE FROM migration_batch WHERE version=$1;")
+	batched := safe("m200101_000000_test", nil)
+	batched.Batch = 3
+	sqlMigration := &Migration{
+		Version: "m200101_000001_add_zulul_table",
+		Source:  "testdata/migrations_test/m200101_000001_add_zulul_table.sql",
+	}
+	storedMigration := NewStoredMigration(
+		"m200101_000001_add_zulul_table",
+		&repo.DownScript{Statements: []string{"DROP TABLE zulul;"}, UseTx: true})
+
+	tests := []struct {
+		name    string
+		down    Migrations
+		up      Migrations
+		expect  func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "up in single transaction",
+			up:   Migrations{safe("m200101_000000_test", nil), safe("m200101_000001_test", nil)},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(insertVersion).WithArgs("m200101_000000_test", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertVersion).WithArgs("m200101_000001_test", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "redo in single transaction",
+			down: Migrations{safe("m200101_000000_test", nil)},
+			up:   Migrations{safe("m200101_000000_test", nil)},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(lockVersion).WithArgs("m200101_000000_test").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteVersion).WithArgs("m200101_000000_test").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteBatch).WithArgs("m200101_000000_test").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertVersion).WithArgs("m200101_000000_test", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "sql migration down script is stored",
+			up:   Migrations{sqlMigration},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("CREATE TABLE zulul").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(insertVersion).WithArgs(sqlMigration.Version, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(saveDownScript).WithArgs(sqlMigration.Version, sqlmock.AnyArg(), true).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "stored migration is reverted",
+			down: Migrations{storedMigration},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(lockVersion).WithArgs(storedMigration.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("DROP TABLE zulul;")).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(deleteVersion).WithArgs(storedMigration.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteDownScript).WithArgs(storedMigration.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteBatch).WithArgs(storedMigration.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "batch is stored",
+			up:   Migrations{batched},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(insertVersion).WithArgs(batched.Version, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(saveBatch).WithArgs(batched.Version, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "stored migration can not be applied",
+			up:      Migrations{storedMigration},
+			expect:  func(mock sqlmock.Sqlmock) {},
+			wantErr: ErrStoredUp,
+		},
+		{
+			name: "failed migration rolls back all",
+			up:   Migrations{safe("m200101_000000_test", nil), safe("m200101_000001_test", errSome)},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(insertVersion).WithArgs("m200101_000000_test", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectRollback()
+			},
+			wantErr: errSome,
+		},
+		{
+			name:    "non-transactional migration refused",
+			up:      Migrations{safe("m200101_000000_test", nil), nonTx},
+			expect:  func(mock sqlmock.Sqlmock) {},
+			wantErr: ErrNonTransactional,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			dialect, err := sqldialect.InitDialect("postgres", "migration")
+			require.NoError(t, err)
+			tt.expect(mock)
+
+			err = RunAtomic(context.Background(), repo.NewMigrationsRepository(db, dialect), nil, nil, tt.down, tt.up)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
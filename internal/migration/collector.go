@@ -10,9 +10,13 @@ import (
 
 type MigrationsCollectorInterface interface {
 	CollectMigrations(dirpath string, current, target int) (Migrations, error)
+	CollectRepeatableMigrations(dirpath string) (RepeatableMigrations, error)
 }
 
-type MigrationsCollector struct{}
+type MigrationsCollector struct {
+	// Parser parses collected SQL migrations, nil uses default one.
+	Parser *SQLParser
+}
 
 // CollectMigrations returns all the valid looking migration scripts in the
 // migrations folder and go func registry, and key them by version.
@@ -21,6 +25,24 @@ func (c *MigrationsCollector) CollectMigrations(dirpath string, current, target
 		return nil, errors.Wrap(errors.New("directory does not exist"), dirpath)
 	}
 
+	migrations, err := collectFromDir(dirpath, registeredMigrations, func(*Migration) bool { return true }, current, target, c.Parser)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations = sortAndConnectMigrations(migrations)
+
+	return migrations, nil
+}
+
+// collectFromDir returns unsorted migrations from the dir and registered ones accepted by owns.
+func collectFromDir(
+	dirpath string,
+	registry map[string]*Migration,
+	owns func(*Migration) bool,
+	current, target int,
+	parser *SQLParser,
+) (Migrations, error) {
 	var migrations Migrations
 
 	// SQL migration files.
@@ -29,19 +51,71 @@ func (c *MigrationsCollector) CollectMigrations(dirpath string, current, target
 		return nil, err
 	}
 	for _, file := range sqlMigrationFiles {
+		if IsHookFile(file) || IsRepeatableFile(file) {
+			continue
+		}
+
+		v, err := GetVersionFromFileName(file)
+		if err != nil {
+			return nil, err
+		}
+
+		// .down.sql files are collected along with their .up.sql pair
+		if IsSplitDownFile(file) {
+			if _, err := os.Stat(SplitUpFile(file)); os.IsNotExist(err) {
+				return nil, errors.Errorf("%s has no %s pair", filepath.Base(file), filepath.Base(SplitUpFile(file)))
+			}
+
+			continue
+		}
+
+		if !versionInRange(GetComparableVersion(v), current, target) {
+			continue
+		}
+
+		if IsSplitUpFile(file) {
+			migration, err := newSplitMigration(v, file, parser)
+			if err != nil {
+				return nil, err
+			}
+
+			migrations = append(migrations, migration)
+
+			continue
+		}
+
+		squashed, err := ReadSquashedVersions(file)
+		if err != nil {
+			return nil, err
+		}
+
+		migration := &Migration{Version: v, Source: file, Squashed: squashed, parser: parser}
+		migrations = append(migrations, migration)
+	}
+
+	// SQL migration templates.
+	sqlTemplateFiles, err := filepath.Glob(dirpath + "/*." + string(TypeSQLTemplate))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range sqlTemplateFiles {
 		v, err := GetVersionFromFileName(file)
 		if err != nil {
 			return nil, err
 		}
 
 		if versionInRange(GetComparableVersion(v), current, target) {
-			migration := &Migration{Version: v, Source: file}
+			migration := &Migration{Version: v, Source: file, parser: parser}
 			migrations = append(migrations, migration)
 		}
 	}
 
 	// Go migrations registered via AddMigration().
-	for _, migration := range registeredMigrations {
+	for _, migration := range registry {
+		if !owns(migration) {
+			continue
+		}
+
 		v, err := GetVersionFromFileName(migration.Source)
 		if err != nil {
 			return nil, err
@@ -64,7 +138,7 @@ func (c *MigrationsCollector) CollectMigrations(dirpath string, current, target
 		}
 
 		// Skip migrations already existing migrations registered via AddMigration().
-		if _, ok := registeredMigrations[v]; ok {
+		if _, ok := registry[v]; ok {
 			continue
 		}
 
@@ -74,8 +148,6 @@ func (c *MigrationsCollector) CollectMigrations(dirpath string, current, target
 		}
 	}
 
-	migrations = sortAndConnectMigrations(migrations)
-
 	return migrations, nil
 }
 
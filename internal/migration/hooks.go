@@ -0,0 +1,151 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/log"
+)
+
+type HookPoint string
+
+const (
+	HookBeforeAll  HookPoint = "before_all"
+	HookAfterAll   HookPoint = "after_all"
+	HookBeforeEach HookPoint = "before_each"
+	HookAfterEach  HookPoint = "after_each"
+)
+
+// hookPoints are in order of SQL hook files lookup.
+var hookPoints = []HookPoint{HookBeforeAll, HookAfterAll, HookBeforeEach, HookAfterEach}
+
+// HookEvent describes the moment hook is called at.
+type HookEvent struct {
+	Point HookPoint
+	// Migration is nil for before_all and after_all hooks.
+	Migration *Migration
+	// Direction of the run hooks is the direction of the first (before_all)
+	// or the last (after_all) migration of the run.
+	Direction Direction
+	// Duration of the migration or of the whole run, set for after hooks only.
+	Duration time.Duration
+}
+
+// HookExecutor runs hook queries. Each hooks get the migration tx (or *sql.DB for non-transactional migration),
+// all hooks get *sql.Conn pinned for the whole run, so session settings and locks are kept between them.
+type HookExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// HookFn is called with ctx of the run or migration span.
+type HookFn func(ctx context.Context, ex HookExecutor, e *HookEvent) error
+
+var registeredHooks = map[HookPoint][]HookFn{}
+
+// AddHook registers Go hook, registered hooks run before SQL hook files of the same point.
+func AddHook(point HookPoint, fn HookFn) {
+	registeredHooks[point] = append(registeredHooks[point], fn)
+}
+
+// HookFileName returns name of SQL hook file for the point, e.g. _before_each.sql.
+func HookFileName(point HookPoint) string {
+	return "_" + string(point) + "." + string(TypeSQL)
+}
+
+// IsHookFile reports whether file is SQL hook and must not be treated as migration.
+func IsHookFile(path string) bool {
+	base := filepath.Base(path)
+	for _, point := range hookPoints {
+		if base == HookFileName(point) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Hooks struct {
+	fns map[HookPoint][]HookFn
+}
+
+// CollectHooks returns registered Go hooks and SQL hooks found in the migrations folder parsed with parser.
+func CollectHooks(dirpath string, parser *SQLParser) (*Hooks, error) {
+	hooks := &Hooks{fns: map[HookPoint][]HookFn{}}
+	for point, fns := range registeredHooks {
+		hooks.fns[point] = append(hooks.fns[point], fns...)
+	}
+
+	for _, point := range hookPoints {
+		path := filepath.Join(dirpath, HookFileName(point))
+
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to open SQL hook file: %s", path)
+		}
+
+		statements, err := parser.ParseStatements(f)
+		f.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse SQL hook file: %s", path)
+		}
+
+		hooks.fns[point] = append(hooks.fns[point], assembleHookFnFromStatements(statements))
+	}
+
+	return hooks, nil
+}
+
+func (h *Hooks) empty() bool {
+	return h == nil || len(h.fns) == 0
+}
+
+// Has reports whether hooks of the point are registered.
+func (h *Hooks) Has(point HookPoint) bool {
+	return !h.empty() && len(h.fns[point]) > 0
+}
+
+// Run calls hooks of the event point in order of registration, first failed hook aborts the call.
+func (h *Hooks) Run(ctx context.Context, ex HookExecutor, e *HookEvent) error {
+	if h.empty() {
+		return nil
+	}
+
+	for _, fn := range h.fns[e.Point] {
+		if err := fn(ctx, ex, e); err != nil {
+			if e.Migration != nil {
+				log.Errf("*** %s hook failed for %s\n", e.Point, filepath.Base(e.Migration.Source))
+
+				return errors.Wrapf(err, "%s hook failed for %s", e.Point, e.Migration.Version)
+			}
+
+			log.Errf("*** %s hook failed\n", e.Point)
+
+			return errors.Wrapf(err, "%s hook failed", e.Point)
+		}
+	}
+
+	return nil
+}
+
+// runEach calls migrate between before_each and after_each hooks run on ex.
+func (h *Hooks) runEach(ctx context.Context, ex HookExecutor, m *Migration, direction Direction, migrate func() error) error {
+	if err := h.Run(ctx, ex, &HookEvent{Point: HookBeforeEach, Migration: m, Direction: direction}); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := migrate(); err != nil {
+		return err
+	}
+
+	return h.Run(ctx, ex, &HookEvent{Point: HookAfterEach, Migration: m, Direction: direction, Duration: time.Since(start)})
+}
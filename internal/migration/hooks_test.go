@@ -0,0 +1,186 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gojuno/minimock/v3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/repo"
+)
+
+func TestIsHookFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "migrations/_before_all.sql", want: true},
+		{path: "migrations/_after_all.sql", want: true},
+		{path: "_before_each.sql", want: true},
+		{path: "_after_each.sql", want: true},
+		{path: "_after_each.go", want: false},
+		{path: "migrations/m200101_000000_test.sql", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			require.Equal(t, tt.want, IsHookFile(tt.path))
+		})
+	}
+}
+
+func TestCollectHooks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	hooks, err := CollectHooks("testdata/hooks_test", nil)
+	require.NoError(t, err)
+	require.Len(t, hooks.fns[HookBeforeEach], 1)
+	require.Empty(t, hooks.fns[HookAfterAll])
+
+	mock.ExpectExec("SET lock_timeout = '5s';").WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, hooks.Run(context.Background(), db, &HookEvent{Point: HookBeforeEach, Direction: DirectionUp}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHooks_Run(t *testing.T) {
+	var calls []string
+	hookFn := func(name string, err error) HookFn {
+		return func(_ context.Context, _ HookExecutor, _ *HookEvent) error {
+			calls = append(calls, name)
+
+			return err
+		}
+	}
+
+	tests := []struct {
+		name      string
+		hooks     *Hooks
+		wantCalls []string
+		wantErr   bool
+	}{
+		{
+			name:      "nil hooks",
+			hooks:     nil,
+			wantCalls: nil,
+			wantErr:   false,
+		},
+		{
+			name: "hooks called in order",
+			hooks: &Hooks{fns: map[HookPoint][]HookFn{
+				HookBeforeAll: {hookFn("first", nil), hookFn("second", nil)},
+				HookAfterAll:  {hookFn("after", nil)},
+			}},
+			wantCalls: []string{"first", "second"},
+			wantErr:   false,
+		},
+		{
+			name: "failed hook aborts the call",
+			hooks: &Hooks{fns: map[HookPoint][]HookFn{
+				HookBeforeAll: {hookFn("first", errors.New("some error")), hookFn("second", nil)},
+			}},
+			wantCalls: []string{"first"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			err := tt.hooks.Run(context.Background(), nil, &HookEvent{Point: HookBeforeAll, Direction: DirectionUp})
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestRunner_eachHooks(t *testing.T) {
+	var (
+		events []HookEvent
+		inTx   []bool
+	)
+	recordFn := func(err error) HookFn {
+		return func(_ context.Context, ex HookExecutor, e *HookEvent) error {
+			events = append(events, *e)
+			_, ok := ex.(*sql.Tx)
+			inTx = append(inTx, ok)
+
+			return err
+		}
+	}
+
+	tests := []struct {
+		name       string
+		hooks      *Hooks
+		wantPoints []HookPoint
+		wantErr    bool
+	}{
+		{
+			name: "before and after each called in migration tx",
+			hooks: &Hooks{fns: map[HookPoint][]HookFn{
+				HookBeforeEach: {recordFn(nil)},
+				HookAfterEach:  {recordFn(nil)},
+			}},
+			wantPoints: []HookPoint{HookBeforeEach, HookAfterEach},
+			wantErr:    false,
+		},
+		{
+			name: "failed before each hook aborts migration",
+			hooks: &Hooks{fns: map[HookPoint][]HookFn{
+				HookBeforeEach: {recordFn(errors.New("some error"))},
+				HookAfterEach:  {recordFn(nil)},
+			}},
+			wantPoints: []HookPoint{HookBeforeEach},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, inTx = nil, nil
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			var migrated bool
+			m := &Migration{
+				Version: "m000000_000000_test",
+				Source:  "m000000_000000_test.go",
+				SafeDownFn: func(*sql.Tx) error {
+					migrated = true
+
+					return nil
+				},
+			}
+
+			mock.ExpectBegin()
+			if tt.wantErr {
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectCommit()
+			}
+
+			mRepoMock := repo.NewMigrationRepoMock(minimock.NewController(t)).
+				GetDBMock.Return(db, nil).
+				LockVersionMock.Return(nil).
+				DeleteVersionMock.Return(nil)
+
+			err = NewRunner(tt.hooks).MigrateDownSafe(context.Background(), mRepoMock, m)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, !tt.wantErr, migrated)
+			require.NoError(t, mock.ExpectationsWereMet())
+
+			points := make([]HookPoint, 0, len(events))
+			for i, e := range events {
+				require.Equal(t, m, e.Migration)
+				require.Equal(t, DirectionDown, e.Direction)
+				require.True(t, inTx[i])
+				points = append(points, e.Point)
+			}
+			require.Equal(t, tt.wantPoints, points)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package migration
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// irreversibleAnnotation marks SQL migration which can not be reverted.
+const irreversibleAnnotation = "+gomigrate Irreversible"
+
+// ErrIrreversible is returned on attempt to revert migration which has no Down part.
+var ErrIrreversible = errors.New("migration is irreversible")
+
+// IrreversibleSafeDown may be passed as Go migration down func, reverting such migration
+// is refused before any migration is reverted.
+func IrreversibleSafeDown(*sql.Tx) error {
+	return ErrIrreversible
+}
+
+// IrreversibleDown is IrreversibleSafeDown for non-transactional migrations.
+func IrreversibleDown(*sql.DB) error {
+	return ErrIrreversible
+}
+
+// IsIrreversible reports whether migration can not be reverted: SQL migration is annotated
+// with '-- +gomigrate Irreversible' or has no .down.sql pair, Go migration has nil or
+// Irreversible(Safe)Down down func.
+func (m *Migration) IsIrreversible() (bool, error) {
+	switch filepath.Ext(m.Source) {
+	case ".sql", ".tmpl":
+		_, _, err := m.parseSQL(DirectionDown)
+		if errors.Is(err, ErrIrreversible) {
+			return true, nil
+		}
+
+		return false, err
+	case ".go":
+		if m.SafeDownFn != nil {
+			return sameFunc(m.SafeDownFn, IrreversibleSafeDown), nil
+		}
+
+		if m.DownFn != nil {
+			return sameFunc(m.DownFn, IrreversibleDown), nil
+		}
+
+		return m.Registered, nil
+	}
+
+	return false, nil
+}
+
+func sameFunc(a, b interface{}) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
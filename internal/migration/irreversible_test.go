@@ -0,0 +1,69 @@
+package migration
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigration_IsIrreversible(t *testing.T) {
+	tests := []struct {
+		name string
+		m    *Migration
+		want bool
+	}{
+		{
+			name: "annotated sql",
+			m:    &Migration{Source: "testdata/irreversible_test/m200101_000000_drop_legacy_users.sql"},
+			want: true,
+		},
+		{
+			name: "split sql without down file",
+			m:    &Migration{Source: "testdata/split_test/m200101_000001_add_users_name_index.up.sql"},
+			want: true,
+		},
+		{
+			name: "split sql with down file",
+			m: &Migration{
+				Source:     "testdata/split_test/m200101_000000_create_users.up.sql",
+				DownSource: "testdata/split_test/m200101_000000_create_users.down.sql",
+			},
+			want: false,
+		},
+		{
+			name: "go nil down",
+			m:    &Migration{Source: "m200101_000000_test.go", Registered: true},
+			want: true,
+		},
+		{
+			name: "go irreversible down",
+			m:    &Migration{Source: "m200101_000000_test.go", Registered: true, SafeDownFn: IrreversibleSafeDown},
+			want: true,
+		},
+		{
+			name: "go down",
+			m:    &Migration{Source: "m200101_000000_test.go", Registered: true, SafeDownFn: func(*sql.Tx) error { return nil }},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.IsIrreversible()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseSQLMigration_Irreversible(t *testing.T) {
+	sql := "-- +gomigrate Irreversible\n-- +gomigrate Up\nDELETE FROM users;\n-- +gomigrate Down\n"
+
+	stmts, _, err := defaultSQLParser.parse(strings.NewReader(sql), DirectionUp)
+	require.NoError(t, err)
+	require.Equal(t, []string{"DELETE FROM users;\n"}, stmts)
+
+	_, _, err = defaultSQLParser.parse(strings.NewReader(sql), DirectionDown)
+	require.ErrorIs(t, err, ErrIrreversible)
+}
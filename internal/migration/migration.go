@@ -1,8 +1,12 @@
 package migration
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -19,12 +23,24 @@ const (
 
 var registeredMigrations = map[string]*Migration{}
 
+// ErrStoredUp is returned on attempt to apply migration whose file is missing.
+var ErrStoredUp = errors.New("migration file is missing, only stored down script is available")
+
 type Migration struct {
-	Version    string
-	Next       string
-	Previous   string
-	Source     string // path to .sql\.go file
+	Version  string
+	Next     string
+	Previous string
+	Source   string // path to .sql\.go file
+	// DownSource is path to .down.sql file of split .up.sql/.down.sql migration.
+	DownSource string
 	Registered bool
+	// Squashed contains versions replaced by this baseline migration.
+	Squashed []string
+	// Stored is down script saved at apply time, it is used to revert migration whose file is missing.
+	Stored *repo.DownScript
+	// Batch is number of up invocation applying migration, 0 means batch is not stored.
+	Batch      int
+	parser     *SQLParser
 	SafeUpFn   func(*sql.Tx) error
 	SafeDownFn func(*sql.Tx) error
 	UpFn       func(*sql.DB) error
@@ -34,8 +50,8 @@ type Migration struct {
 type Direction string
 
 var (
-	migrationDirectionUp   Direction = "up"
-	migrationDirectionDown Direction = "down"
+	DirectionUp   Direction = "up"
+	DirectionDown Direction = "down"
 )
 
 type Type string
@@ -43,96 +59,287 @@ type Type string
 var (
 	TypeGo  Type = "go"
 	TypeSQL Type = "sql"
+	// TypeSQLTemplate is SQL migration rendered with text/template before parsing.
+	TypeSQLTemplate Type = "sql.tmpl"
 )
 
 func (m *Migration) String() string {
 	return fmt.Sprintf(m.Version)
 }
 
-func (m *Migration) Up(repo repo.MigrationRepo, runner RunnerInterface) error {
-	if err := m.run(repo, migrationDirectionUp, runner); err != nil {
+// Up applies migration, ctx carries span of the run the migration span is attached to.
+func (m *Migration) Up(ctx context.Context, repo repo.MigrationRepo, runner RunnerInterface) error {
+	return m.run(ctx, repo, DirectionUp, runner)
+}
+
+// Down reverts migration, ctx carries span of the run the migration span is attached to.
+func (m *Migration) Down(ctx context.Context, repo repo.MigrationRepo, runner RunnerInterface) error {
+	return m.run(ctx, repo, DirectionDown, runner)
+}
+
+// NewStoredMigration returns migration reverting version with down script stored at apply time.
+func NewStoredMigration(version string, script *repo.DownScript) *Migration {
+	return &Migration{Version: version, Stored: script}
+}
+
+// saveApplied stores down script and batch of applied migration, it is called by runner with repository
+// bound to the tx writing the version.
+func (m *Migration) saveApplied(r repo.MigrationRepo) error {
+	if err := m.saveDownScript(r); err != nil {
 		return err
 	}
 
-	return nil
+	return m.saveBatch(r)
 }
 
-func (m *Migration) Down(repo repo.MigrationRepo, runner RunnerInterface) error {
-	if err := m.run(repo, migrationDirectionDown, runner); err != nil {
+// saveDownScript stores down statements of applied SQL migration, so it can be reverted when its file is missing.
+// Irreversible and Go migrations have nothing to store.
+func (m *Migration) saveDownScript(r repo.MigrationRepo) error {
+	store, ok := r.(repo.DownScriptRepo)
+	if !ok || m.Stored != nil || !m.IsSQL() {
+		return nil
+	}
+
+	statements, useTx, err := m.parseSQL(DirectionDown)
+	if errors.Is(err, ErrIrreversible) {
+		return nil
+	}
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return errors.Wrapf(store.SaveDownScript(m.Version, &repo.DownScript{Statements: statements, UseTx: useTx}),
+		"failed to save down script of %s", m.Version)
 }
 
-func (m *Migration) run(repo repo.MigrationRepo, direction Direction, runner RunnerInterface) error {
-	switch filepath.Ext(m.Source) {
-	case ".sql":
-		f, err := os.Open(m.Source)
-		if err != nil {
-			return errors.Wrapf(err, "failed to open SQL migration file, err: %v", filepath.Base(m.Source))
+// deleteReverted deletes versions squashed into reverted baseline, down scripts and batches of reverted versions,
+// it is called by runner with repository bound to the tx deleting the version.
+func (m *Migration) deleteReverted(r repo.MigrationRepo) error {
+	// db may still contain versions of migrations squashed into the baseline
+	for _, v := range m.Squashed {
+		if err := r.DeleteVersion(v); err != nil {
+			return errors.Wrapf(err, "failed to delete squashed migration version %s", v)
 		}
-		defer f.Close()
+	}
 
-		statements, useTx, err := parseSQLMigration(f, direction)
-		if err != nil {
-			return errors.Wrapf(err, "failed to parse SQL migration file: %v", filepath.Base(m.Source))
+	versions := append([]string{m.Version}, m.Squashed...)
+	if m.IsSQL() {
+		if err := deleteDownScripts(r, versions); err != nil {
+			return err
 		}
+	}
 
-		if useTx {
-			if direction == migrationDirectionUp {
-				m.SafeUpFn = assembleSafeFnFromStatements(statements)
+	return deleteBatches(r, versions)
+}
 
-				return runner.MigrateUpSafe(repo, m)
-			}
+// deleteDownScripts deletes down scripts of reverted versions.
+func deleteDownScripts(r repo.MigrationRepo, versions []string) error {
+	store, ok := r.(repo.DownScriptRepo)
+	if !ok {
+		return nil
+	}
 
-			m.SafeDownFn = assembleSafeFnFromStatements(statements)
+	for _, v := range versions {
+		if err := store.DeleteDownScript(v); err != nil {
+			return errors.Wrapf(err, "failed to delete down script of %s", v)
+		}
+	}
 
-			return runner.MigrateDownSafe(repo, m)
+	return nil
+}
+
+// saveBatch stores batch number of applied migration.
+func (m *Migration) saveBatch(r repo.MigrationRepo) error {
+	store, ok := r.(repo.BatchRepo)
+	if !ok || m.Batch == 0 {
+		return nil
+	}
+
+	return errors.Wrapf(store.SaveBatch(m.Version, m.Batch), "failed to save batch of %s", m.Version)
+}
+
+// deleteBatches deletes batch numbers of reverted versions.
+func deleteBatches(r repo.MigrationRepo, versions []string) error {
+	store, ok := r.(repo.BatchRepo)
+	if !ok {
+		return nil
+	}
+
+	for _, v := range versions {
+		if err := store.DeleteBatch(v); err != nil {
+			return errors.Wrapf(err, "failed to delete batch of %s", v)
 		}
+	}
 
-		if direction == migrationDirectionUp {
-			m.UpFn = assembleFnFromStatements(statements)
+	return nil
+}
 
-			return runner.MigrateUp(repo, m)
+func (m *Migration) run(ctx context.Context, repo repo.MigrationRepo, direction Direction, runner RunnerInterface) error {
+	switch {
+	case m.IsSQL():
+		statements, useTx, err := m.parseSQL(direction)
+		if err != nil {
+			return err
 		}
 
-		m.DownFn = assembleFnFromStatements(statements)
+		txMode := TxModeNone
+		if useTx {
+			txMode = TxModeSingle
+		}
 
-		return runner.MigrateDown(repo, m)
-	case ".go":
+		return m.traced(ctx, direction, txMode, func(ctx context.Context) error {
+			return m.runSQL(ctx, repo, direction, runner, statements, txMode)
+		})
+	case filepath.Ext(m.Source) == ".go":
 		if !m.Registered {
 			return errors.Errorf("not registered %v", m.Source)
 		}
 
-		if direction == migrationDirectionUp {
-			if m.SafeUpFn != nil {
-				return runner.MigrateUpSafe(repo, m)
-			}
+		txMode := TxModeNone
+		if (direction == DirectionUp && m.SafeUpFn != nil) || (direction == DirectionDown && m.SafeDownFn != nil) {
+			txMode = TxModeSingle
+		}
+
+		return m.traced(ctx, direction, txMode, func(ctx context.Context) error {
+			return m.runGo(ctx, repo, direction, runner)
+		})
+	}
+
+	return nil
+}
+
+// traced runs migrate within migration span, statements and repository queries of migrate are its children.
+func (m *Migration) traced(ctx context.Context, direction Direction, txMode TxMode, migrate func(context.Context) error) error {
+	ctx, span := startMigrationSpan(ctx, m, direction, txMode)
+	err := migrate(ctx)
+	span.End(err)
+
+	return err
+}
+
+func (m *Migration) runSQL(
+	ctx context.Context,
+	repo repo.MigrationRepo,
+	direction Direction,
+	runner RunnerInterface,
+	statements []string,
+	txMode TxMode,
+) error {
+	onExec := statementNotifier(runner, m, direction, txMode)
+	if txMode == TxModeSingle {
+		fn := assembleSafeFnFromStatements(statements, onExec)
+		safeFn := func(tx *sql.Tx) error { return fn(ctx, tx) }
+		if direction == DirectionUp {
+			m.SafeUpFn = safeFn
+
+			return runner.MigrateUpSafe(ctx, repo, m)
+		}
+
+		m.SafeDownFn = safeFn
+
+		return runner.MigrateDownSafe(ctx, repo, m)
+	}
+
+	fn := assembleFnFromStatements(statements, onExec)
+	noTxFn := func(db *sql.DB) error { return fn(ctx, db) }
+	if direction == DirectionUp {
+		m.UpFn = noTxFn
+
+		return runner.MigrateUp(ctx, repo, m)
+	}
+
+	m.DownFn = noTxFn
 
-			if m.UpFn != nil {
-				return runner.MigrateUp(repo, m)
-			}
+	return runner.MigrateDown(ctx, repo, m)
+}
 
-			return errors.New("unexpected nil on both SafeUpFn UpFn")
+func (m *Migration) runGo(ctx context.Context, repo repo.MigrationRepo, direction Direction, runner RunnerInterface) error {
+	if direction == DirectionUp {
+		if m.SafeUpFn != nil {
+			return runner.MigrateUpSafe(ctx, repo, m)
 		}
 
-		if m.SafeDownFn != nil {
-			return runner.MigrateDownSafe(repo, m)
+		if m.UpFn != nil {
+			return runner.MigrateUp(ctx, repo, m)
 		}
 
-		if m.DownFn != nil {
-			return runner.MigrateDown(repo, m)
+		return errors.New("unexpected nil on both SafeUpFn UpFn")
+	}
+
+	if m.SafeDownFn != nil {
+		return runner.MigrateDownSafe(ctx, repo, m)
+	}
+
+	if m.DownFn != nil {
+		return runner.MigrateDown(ctx, repo, m)
+	}
+
+	return errors.Wrap(ErrIrreversible, "nil on both SafeDownFn DownFn")
+}
+
+// IsSQL reports whether migration is .sql, .sql.tmpl, split .up.sql/.down.sql or stored one.
+func (m *Migration) IsSQL() bool {
+	ext := filepath.Ext(m.Source)
+
+	return ext == ".sql" || ext == ".tmpl" || m.Stored != nil
+}
+
+// Statements returns statements of SQL migration for given direction and whether they run in transaction.
+func (m *Migration) Statements(direction Direction) ([]string, bool, error) {
+	return m.parseSQL(direction)
+}
+
+// parseSQL returns statements of SQL migration for given direction.
+func (m *Migration) parseSQL(direction Direction) ([]string, bool, error) {
+	if m.Stored != nil {
+		if direction != DirectionDown {
+			return nil, false, errors.Wrapf(ErrStoredUp, "%s", m.Version)
 		}
 
-		return errors.New("unexpected nil on both SafeDownFn DownFn")
+		return m.Stored.Statements, m.Stored.UseTx, nil
 	}
 
-	return nil
+	if IsSplitUpFile(m.Source) {
+		return m.parseSplitSQL(direction)
+	}
+
+	f, err := openSQLMigration(m)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to open SQL migration file, err: %v", filepath.Base(m.Source))
+	}
+	defer f.Close()
+
+	statements, useTx, err := m.parser.parse(f, direction)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to parse SQL migration file: %v", filepath.Base(m.Source))
+	}
+
+	return statements, useTx, nil
+}
+
+// openSQLMigration returns SQL migration content, templates are rendered.
+func openSQLMigration(m *Migration) (io.ReadCloser, error) {
+	if !strings.HasSuffix(m.Source, "."+string(TypeSQLTemplate)) {
+		return os.Open(m.Source)
+	}
+
+	content, err := renderSQLTemplate(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(content)), nil
 }
 
 func GetVersionFromFileName(name string) (string, error) {
 	base := filepath.Base(name)
+	for _, suffix := range []string{"." + string(TypeSQLTemplate), splitUpSuffix, splitDownSuffix} {
+		if strings.HasSuffix(base, suffix) {
+			base = strings.TrimSuffix(base, suffix) + "." + string(TypeSQL)
+
+			break
+		}
+	}
 
 	if ext := filepath.Ext(base); ext != ".go" && ext != ".sql" {
 		return "", errors.New("only .go and .sql migrations supported")
@@ -206,22 +413,22 @@ func AddSafeNamedMigration(filename string, up func(*sql.Tx) error, down func(*s
 	v, _ := GetVersionFromFileName(filename)
 	migration := &Migration{Version: v, Next: "", Previous: "", Registered: true, SafeUpFn: up, SafeDownFn: down, Source: filename}
 
-	if existing, ok := registeredMigrations[v]; ok {
-		panic(fmt.Sprintf("failed to add migration %q: version conflicts with %q", filename, existing.Source))
-	}
-
-	registeredMigrations[v] = migration
+	register(registeredMigrations, migration)
 }
 
 func AddNamedMigration(filename string, up func(*sql.DB) error, down func(*sql.DB) error) {
 	v, _ := GetVersionFromFileName(filename)
 	migration := &Migration{Version: v, Next: "", Previous: "", Registered: true, UpFn: up, DownFn: down, Source: filename}
 
-	if existing, ok := registeredMigrations[v]; ok {
-		panic(fmt.Sprintf("failed to add migration %q: version conflicts with %q", filename, existing.Source))
+	register(registeredMigrations, migration)
+}
+
+func register(registry map[string]*Migration, migration *Migration) {
+	if existing, ok := registry[migration.Version]; ok {
+		panic(fmt.Sprintf("failed to add migration %q: version conflicts with %q", migration.Source, existing.Source))
 	}
 
-	registeredMigrations[v] = migration
+	registry[migration.Version] = migration
 }
 
 func Convert(records repo.MigrationRecords) Migrations {
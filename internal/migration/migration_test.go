@@ -1,6 +1,7 @@
 package migration
 
 import (
+	"context"
 	"database/sql"
 	"reflect"
 	"testing"
@@ -39,7 +40,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateUpSafeMock.Return(nil)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionUp,
+					direction: DirectionUp,
 					runner:    runnerMock,
 				}
 			}(),
@@ -53,7 +54,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateUpSafeMock.Return(errors.New("kek"))
 				return args{
 					repo:      nil,
-					direction: migrationDirectionUp,
+					direction: DirectionUp,
 					runner:    runnerMock,
 				}
 			}(),
@@ -67,7 +68,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateUpMock.Return(nil)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionUp,
+					direction: DirectionUp,
 					runner:    runnerMock,
 				}
 			}(),
@@ -81,7 +82,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateUpMock.Return(errors.New("kek"))
 				return args{
 					repo:      nil,
-					direction: migrationDirectionUp,
+					direction: DirectionUp,
 					runner:    runnerMock,
 				}
 			}(),
@@ -95,7 +96,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateDownSafeMock.Return(nil)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -109,7 +110,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateDownSafeMock.Return(errors.New("kek"))
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -123,7 +124,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateDownMock.Return(nil)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -137,7 +138,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateDownMock.Return(errors.New("kek"))
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -151,7 +152,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -165,7 +166,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -185,7 +186,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateUpSafeMock.Return(nil)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionUp,
+					direction: DirectionUp,
 					runner:    runnerMock,
 				}
 			}(),
@@ -203,7 +204,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionUp,
+					direction: DirectionUp,
 					runner:    runnerMock,
 				}
 			}(),
@@ -223,7 +224,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateUpSafeMock.Return(errors.New("kek"))
 				return args{
 					repo:      nil,
-					direction: migrationDirectionUp,
+					direction: DirectionUp,
 					runner:    runnerMock,
 				}
 			}(),
@@ -243,7 +244,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateUpMock.Return(nil)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionUp,
+					direction: DirectionUp,
 					runner:    runnerMock,
 				}
 			}(),
@@ -261,7 +262,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionUp,
+					direction: DirectionUp,
 					runner:    runnerMock,
 				}
 			}(),
@@ -281,7 +282,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateUpMock.Return(errors.New("kek"))
 				return args{
 					repo:      nil,
-					direction: migrationDirectionUp,
+					direction: DirectionUp,
 					runner:    runnerMock,
 				}
 			}(),
@@ -301,7 +302,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateDownSafeMock.Return(nil)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -319,7 +320,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -339,7 +340,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateDownSafeMock.Return(errors.New("kek"))
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -359,7 +360,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateDownMock.Return(nil)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -377,7 +378,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -397,7 +398,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc).MigrateDownMock.Return(errors.New("kek"))
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -417,7 +418,7 @@ func TestMigration_run(t *testing.T) {
 				runnerMock := NewRunnerInterfaceMock(mc)
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    runnerMock,
 				}
 			}(),
@@ -431,7 +432,7 @@ func TestMigration_run(t *testing.T) {
 			args: func() args {
 				return args{
 					repo:      nil,
-					direction: migrationDirectionDown,
+					direction: DirectionDown,
 					runner:    nil,
 				}
 			}(),
@@ -448,7 +449,7 @@ func TestMigration_run(t *testing.T) {
 				UpFn:       tt.fields.UpFn,
 				DownFn:     tt.fields.DownFn,
 			}
-			if err := m.run(tt.args.repo, tt.args.direction, tt.args.runner); (err != nil) != tt.wantErr {
+			if err := m.run(context.Background(), tt.args.repo, tt.args.direction, tt.args.runner); (err != nil) != tt.wantErr {
 				t.Errorf("run() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -495,6 +496,30 @@ func TestGetVersionFromFileName(t *testing.T) {
 			want:    "m000000_000000_kek",
 			wantErr: false,
 		},
+		{
+			name:    "success sql template",
+			args:    args{name: "m000000_000000_kek.sql.tmpl"},
+			want:    "m000000_000000_kek",
+			wantErr: false,
+		},
+		{
+			name:    "success split up sql",
+			args:    args{name: "m000000_000000_kek.up.sql"},
+			want:    "m000000_000000_kek",
+			wantErr: false,
+		},
+		{
+			name:    "success split down sql",
+			args:    args{name: "m000000_000000_kek.down.sql"},
+			want:    "m000000_000000_kek",
+			wantErr: false,
+		},
+		{
+			name:    "unknown double extension",
+			args:    args{name: "m000000_000000_kek.old.sql"},
+			want:    "",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -19,6 +19,12 @@ type MigrationsCollectorInterfaceMock struct {
 	afterCollectMigrationsCounter  uint64
 	beforeCollectMigrationsCounter uint64
 	CollectMigrationsMock          mMigrationsCollectorInterfaceMockCollectMigrations
+
+	funcCollectRepeatableMigrations          func(dirpath string) (r1 RepeatableMigrations, err error)
+	inspectFuncCollectRepeatableMigrations   func(dirpath string)
+	afterCollectRepeatableMigrationsCounter  uint64
+	beforeCollectRepeatableMigrationsCounter uint64
+	CollectRepeatableMigrationsMock          mMigrationsCollectorInterfaceMockCollectRepeatableMigrations
 }
 
 // NewMigrationsCollectorInterfaceMock returns a mock for MigrationsCollectorInterface
@@ -31,6 +37,8 @@ func NewMigrationsCollectorInterfaceMock(t minimock.Tester) *MigrationsCollector
 	m.CollectMigrationsMock = mMigrationsCollectorInterfaceMockCollectMigrations{mock: m}
 	m.CollectMigrationsMock.callArgs = []*MigrationsCollectorInterfaceMockCollectMigrationsParams{}
 
+	m.CollectRepeatableMigrationsMock = mMigrationsCollectorInterfaceMockCollectRepeatableMigrations{mock: m}
+	m.CollectRepeatableMigrationsMock.callArgs = []*MigrationsCollectorInterfaceMockCollectRepeatableMigrationsParams{}
 	return m
 }
 
@@ -252,10 +260,228 @@ func (m *MigrationsCollectorInterfaceMock) MinimockCollectMigrationsInspect() {
 	}
 }
 
+type mMigrationsCollectorInterfaceMockCollectRepeatableMigrations struct {
+	mock               *MigrationsCollectorInterfaceMock
+	defaultExpectation *MigrationsCollectorInterfaceMockCollectRepeatableMigrationsExpectation
+	expectations       []*MigrationsCollectorInterfaceMockCollectRepeatableMigrationsExpectation
+
+	callArgs []*MigrationsCollectorInterfaceMockCollectRepeatableMigrationsParams
+	mutex    sync.RWMutex
+}
+
+// MigrationsCollectorInterfaceMockCollectRepeatableMigrationsExpectation specifies expectation struct of the MigrationsCollectorInterface.CollectRepeatableMigrations
+type MigrationsCollectorInterfaceMockCollectRepeatableMigrationsExpectation struct {
+	mock    *MigrationsCollectorInterfaceMock
+	params  *MigrationsCollectorInterfaceMockCollectRepeatableMigrationsParams
+	results *MigrationsCollectorInterfaceMockCollectRepeatableMigrationsResults
+	Counter uint64
+}
+
+// MigrationsCollectorInterfaceMockCollectRepeatableMigrationsParams contains parameters of the MigrationsCollectorInterface.CollectRepeatableMigrations
+type MigrationsCollectorInterfaceMockCollectRepeatableMigrationsParams struct {
+	dirpath string
+}
+
+// MigrationsCollectorInterfaceMockCollectRepeatableMigrationsResults contains results of the MigrationsCollectorInterface.CollectRepeatableMigrations
+type MigrationsCollectorInterfaceMockCollectRepeatableMigrationsResults struct {
+	r1  RepeatableMigrations
+	err error
+}
+
+// Expect sets up expected params for MigrationsCollectorInterface.CollectRepeatableMigrations
+func (mmCollectRepeatableMigrations *mMigrationsCollectorInterfaceMockCollectRepeatableMigrations) Expect(dirpath string) *mMigrationsCollectorInterfaceMockCollectRepeatableMigrations {
+	if mmCollectRepeatableMigrations.mock.funcCollectRepeatableMigrations != nil {
+		mmCollectRepeatableMigrations.mock.t.Fatalf("MigrationsCollectorInterfaceMock.CollectRepeatableMigrations mock is already set by Set")
+	}
+
+	if mmCollectRepeatableMigrations.defaultExpectation == nil {
+		mmCollectRepeatableMigrations.defaultExpectation = &MigrationsCollectorInterfaceMockCollectRepeatableMigrationsExpectation{}
+	}
+
+	mmCollectRepeatableMigrations.defaultExpectation.params = &MigrationsCollectorInterfaceMockCollectRepeatableMigrationsParams{dirpath}
+	for _, e := range mmCollectRepeatableMigrations.expectations {
+		if minimock.Equal(e.params, mmCollectRepeatableMigrations.defaultExpectation.params) {
+			mmCollectRepeatableMigrations.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmCollectRepeatableMigrations.defaultExpectation.params)
+		}
+	}
+
+	return mmCollectRepeatableMigrations
+}
+
+// Inspect accepts an inspector function that has same arguments as the MigrationsCollectorInterface.CollectRepeatableMigrations
+func (mmCollectRepeatableMigrations *mMigrationsCollectorInterfaceMockCollectRepeatableMigrations) Inspect(f func(dirpath string)) *mMigrationsCollectorInterfaceMockCollectRepeatableMigrations {
+	if mmCollectRepeatableMigrations.mock.inspectFuncCollectRepeatableMigrations != nil {
+		mmCollectRepeatableMigrations.mock.t.Fatalf("Inspect function is already set for MigrationsCollectorInterfaceMock.CollectRepeatableMigrations")
+	}
+
+	mmCollectRepeatableMigrations.mock.inspectFuncCollectRepeatableMigrations = f
+
+	return mmCollectRepeatableMigrations
+}
+
+// Return sets up results that will be returned by MigrationsCollectorInterface.CollectRepeatableMigrations
+func (mmCollectRepeatableMigrations *mMigrationsCollectorInterfaceMockCollectRepeatableMigrations) Return(r1 RepeatableMigrations, err error) *MigrationsCollectorInterfaceMock {
+	if mmCollectRepeatableMigrations.mock.funcCollectRepeatableMigrations != nil {
+		mmCollectRepeatableMigrations.mock.t.Fatalf("MigrationsCollectorInterfaceMock.CollectRepeatableMigrations mock is already set by Set")
+	}
+
+	if mmCollectRepeatableMigrations.defaultExpectation == nil {
+		mmCollectRepeatableMigrations.defaultExpectation = &MigrationsCollectorInterfaceMockCollectRepeatableMigrationsExpectation{mock: mmCollectRepeatableMigrations.mock}
+	}
+	mmCollectRepeatableMigrations.defaultExpectation.results = &MigrationsCollectorInterfaceMockCollectRepeatableMigrationsResults{r1, err}
+	return mmCollectRepeatableMigrations.mock
+}
+
+//Set uses given function f to mock the MigrationsCollectorInterface.CollectRepeatableMigrations method
+func (mmCollectRepeatableMigrations *mMigrationsCollectorInterfaceMockCollectRepeatableMigrations) Set(f func(dirpath string) (r1 RepeatableMigrations, err error)) *MigrationsCollectorInterfaceMock {
+	if mmCollectRepeatableMigrations.defaultExpectation != nil {
+		mmCollectRepeatableMigrations.mock.t.Fatalf("Default expectation is already set for the MigrationsCollectorInterface.CollectRepeatableMigrations method")
+	}
+
+	if len(mmCollectRepeatableMigrations.expectations) > 0 {
+		mmCollectRepeatableMigrations.mock.t.Fatalf("Some expectations are already set for the MigrationsCollectorInterface.CollectRepeatableMigrations method")
+	}
+
+	mmCollectRepeatableMigrations.mock.funcCollectRepeatableMigrations = f
+	return mmCollectRepeatableMigrations.mock
+}
+
+// When sets expectation for the MigrationsCollectorInterface.CollectRepeatableMigrations which will trigger the result defined by the following
+// Then helper
+func (mmCollectRepeatableMigrations *mMigrationsCollectorInterfaceMockCollectRepeatableMigrations) When(dirpath string) *MigrationsCollectorInterfaceMockCollectRepeatableMigrationsExpectation {
+	if mmCollectRepeatableMigrations.mock.funcCollectRepeatableMigrations != nil {
+		mmCollectRepeatableMigrations.mock.t.Fatalf("MigrationsCollectorInterfaceMock.CollectRepeatableMigrations mock is already set by Set")
+	}
+
+	expectation := &MigrationsCollectorInterfaceMockCollectRepeatableMigrationsExpectation{
+		mock:   mmCollectRepeatableMigrations.mock,
+		params: &MigrationsCollectorInterfaceMockCollectRepeatableMigrationsParams{dirpath},
+	}
+	mmCollectRepeatableMigrations.expectations = append(mmCollectRepeatableMigrations.expectations, expectation)
+	return expectation
+}
+
+// Then sets up MigrationsCollectorInterface.CollectRepeatableMigrations return parameters for the expectation previously defined by the When method
+func (e *MigrationsCollectorInterfaceMockCollectRepeatableMigrationsExpectation) Then(r1 RepeatableMigrations, err error) *MigrationsCollectorInterfaceMock {
+	e.results = &MigrationsCollectorInterfaceMockCollectRepeatableMigrationsResults{r1, err}
+	return e.mock
+}
+
+// CollectRepeatableMigrations implements MigrationsCollectorInterface
+func (mmCollectRepeatableMigrations *MigrationsCollectorInterfaceMock) CollectRepeatableMigrations(dirpath string) (r1 RepeatableMigrations, err error) {
+	mm_atomic.AddUint64(&mmCollectRepeatableMigrations.beforeCollectRepeatableMigrationsCounter, 1)
+	defer mm_atomic.AddUint64(&mmCollectRepeatableMigrations.afterCollectRepeatableMigrationsCounter, 1)
+
+	if mmCollectRepeatableMigrations.inspectFuncCollectRepeatableMigrations != nil {
+		mmCollectRepeatableMigrations.inspectFuncCollectRepeatableMigrations(dirpath)
+	}
+
+	mm_params := &MigrationsCollectorInterfaceMockCollectRepeatableMigrationsParams{dirpath}
+
+	// Record call args
+	mmCollectRepeatableMigrations.CollectRepeatableMigrationsMock.mutex.Lock()
+	mmCollectRepeatableMigrations.CollectRepeatableMigrationsMock.callArgs = append(mmCollectRepeatableMigrations.CollectRepeatableMigrationsMock.callArgs, mm_params)
+	mmCollectRepeatableMigrations.CollectRepeatableMigrationsMock.mutex.Unlock()
+
+	for _, e := range mmCollectRepeatableMigrations.CollectRepeatableMigrationsMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.r1, e.results.err
+		}
+	}
+
+	if mmCollectRepeatableMigrations.CollectRepeatableMigrationsMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmCollectRepeatableMigrations.CollectRepeatableMigrationsMock.defaultExpectation.Counter, 1)
+		mm_want := mmCollectRepeatableMigrations.CollectRepeatableMigrationsMock.defaultExpectation.params
+		mm_got := MigrationsCollectorInterfaceMockCollectRepeatableMigrationsParams{dirpath}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmCollectRepeatableMigrations.t.Errorf("MigrationsCollectorInterfaceMock.CollectRepeatableMigrations got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmCollectRepeatableMigrations.CollectRepeatableMigrationsMock.defaultExpectation.results
+		if mm_results == nil {
+			mmCollectRepeatableMigrations.t.Fatal("No results are set for the MigrationsCollectorInterfaceMock.CollectRepeatableMigrations")
+		}
+		return (*mm_results).r1, (*mm_results).err
+	}
+	if mmCollectRepeatableMigrations.funcCollectRepeatableMigrations != nil {
+		return mmCollectRepeatableMigrations.funcCollectRepeatableMigrations(dirpath)
+	}
+	mmCollectRepeatableMigrations.t.Fatalf("Unexpected call to MigrationsCollectorInterfaceMock.CollectRepeatableMigrations. %v", dirpath)
+	return
+}
+
+// CollectRepeatableMigrationsAfterCounter returns a count of finished MigrationsCollectorInterfaceMock.CollectRepeatableMigrations invocations
+func (mmCollectRepeatableMigrations *MigrationsCollectorInterfaceMock) CollectRepeatableMigrationsAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmCollectRepeatableMigrations.afterCollectRepeatableMigrationsCounter)
+}
+
+// CollectRepeatableMigrationsBeforeCounter returns a count of MigrationsCollectorInterfaceMock.CollectRepeatableMigrations invocations
+func (mmCollectRepeatableMigrations *MigrationsCollectorInterfaceMock) CollectRepeatableMigrationsBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmCollectRepeatableMigrations.beforeCollectRepeatableMigrationsCounter)
+}
+
+// Calls returns a list of arguments used in each call to MigrationsCollectorInterfaceMock.CollectRepeatableMigrations.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmCollectRepeatableMigrations *mMigrationsCollectorInterfaceMockCollectRepeatableMigrations) Calls() []*MigrationsCollectorInterfaceMockCollectRepeatableMigrationsParams {
+	mmCollectRepeatableMigrations.mutex.RLock()
+
+	argCopy := make([]*MigrationsCollectorInterfaceMockCollectRepeatableMigrationsParams, len(mmCollectRepeatableMigrations.callArgs))
+	copy(argCopy, mmCollectRepeatableMigrations.callArgs)
+
+	mmCollectRepeatableMigrations.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockCollectRepeatableMigrationsDone returns true if the count of the CollectRepeatableMigrations invocations corresponds
+// the number of defined expectations
+func (m *MigrationsCollectorInterfaceMock) MinimockCollectRepeatableMigrationsDone() bool {
+	for _, e := range m.CollectRepeatableMigrationsMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.CollectRepeatableMigrationsMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterCollectRepeatableMigrationsCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcCollectRepeatableMigrations != nil && mm_atomic.LoadUint64(&m.afterCollectRepeatableMigrationsCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockCollectRepeatableMigrationsInspect logs each unmet expectation
+func (m *MigrationsCollectorInterfaceMock) MinimockCollectRepeatableMigrationsInspect() {
+	for _, e := range m.CollectRepeatableMigrationsMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to MigrationsCollectorInterfaceMock.CollectRepeatableMigrations with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.CollectRepeatableMigrationsMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterCollectRepeatableMigrationsCounter) < 1 {
+		if m.CollectRepeatableMigrationsMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to MigrationsCollectorInterfaceMock.CollectRepeatableMigrations")
+		} else {
+			m.t.Errorf("Expected call to MigrationsCollectorInterfaceMock.CollectRepeatableMigrations with params: %#v", *m.CollectRepeatableMigrationsMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcCollectRepeatableMigrations != nil && mm_atomic.LoadUint64(&m.afterCollectRepeatableMigrationsCounter) < 1 {
+		m.t.Error("Expected call to MigrationsCollectorInterfaceMock.CollectRepeatableMigrations")
+	}
+}
+
 // MinimockFinish checks that all mocked methods have been called the expected number of times
 func (m *MigrationsCollectorInterfaceMock) MinimockFinish() {
 	if !m.minimockDone() {
 		m.MinimockCollectMigrationsInspect()
+
+		m.MinimockCollectRepeatableMigrationsInspect()
 		m.t.FailNow()
 	}
 }
@@ -279,5 +505,6 @@ func (m *MigrationsCollectorInterfaceMock) MinimockWait(timeout mm_time.Duration
 func (m *MigrationsCollectorInterfaceMock) minimockDone() bool {
 	done := true
 	return done &&
-		m.MinimockCollectMigrationsDone()
+		m.MinimockCollectMigrationsDone() &&
+		m.MinimockCollectRepeatableMigrationsDone()
 }
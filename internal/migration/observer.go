@@ -0,0 +1,160 @@
+package migration
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/tracing"
+)
+
+const (
+	failedToRevertLogText = "*** failed to revert %s (time: %.3f sec.)\n"
+	failedToApplyLogText  = "*** failed to apply %s (time: %.3f sec.)\n"
+)
+
+type EventType string
+
+const (
+	EventRunStarted        EventType = "run_started"
+	EventMigrationStarted  EventType = "migration_started"
+	EventStatementExecuted EventType = "statement_executed"
+	EventMigrationApplied  EventType = "migration_applied"
+	EventMigrationReverted EventType = "migration_reverted"
+	EventMigrationFailed   EventType = "migration_failed"
+	EventRunFinished       EventType = "run_finished"
+)
+
+type TxMode string
+
+const (
+	TxModeNone   TxMode = "non-transactional"
+	TxModeSingle TxMode = "transactional"
+	// TxModeAtomic means the migration shares transaction with the rest of --atomic run.
+	TxModeAtomic TxMode = "atomic"
+)
+
+// Event describes the moment of migrations run observers are notified about.
+type Event struct {
+	Type EventType
+	// Action is set for run events only.
+	Action string
+	// Version and Source are empty for run events.
+	Version   string
+	Source    string
+	Direction Direction
+	TxMode    TxMode
+	// Statement is set for statement events of SQL migrations.
+	Statement string
+	// Duration of the statement, migration or run, set for finishing events only.
+	Duration time.Duration
+	Err      error
+}
+
+// Observer receives migrations lifecycle events, e.g. to feed metrics, audit log or UI.
+// Notify is called synchronously so it should not block for long.
+type Observer interface {
+	Notify(e *Event)
+}
+
+// ObserverFunc adapts func to Observer.
+type ObserverFunc func(e *Event)
+
+func (f ObserverFunc) Notify(e *Event) {
+	f(e)
+}
+
+type Observers []Observer
+
+func (o Observers) Notify(e *Event) {
+	for i := range o {
+		o[i].Notify(e)
+	}
+}
+
+var registeredObservers Observers
+
+// AddObserver registers observer notified by actions run through the library.
+func AddObserver(o Observer) {
+	registeredObservers = append(registeredObservers, o)
+}
+
+// RegisteredObservers returns observers added with AddObserver.
+func RegisteredObservers() Observers {
+	return append(Observers{}, registeredObservers...)
+}
+
+// migrationEvent returns event of migration m.
+func migrationEvent(t EventType, m *Migration, direction Direction, txMode TxMode) *Event {
+	return &Event{Type: t, Version: m.Version, Source: m.Source, Direction: direction, TxMode: txMode}
+}
+
+// migrationFinishedEvent returns applied, reverted or failed event of migration m.
+func migrationFinishedEvent(m *Migration, direction Direction, txMode TxMode, duration time.Duration, err error) *Event {
+	e := migrationEvent(EventMigrationApplied, m, direction, txMode)
+	switch {
+	case err != nil:
+		e.Type = EventMigrationFailed
+	case direction == DirectionDown:
+		e.Type = EventMigrationReverted
+	}
+	e.Duration = duration
+	e.Err = err
+
+	return e
+}
+
+// startMigrationSpan starts tracing span of migration m.
+func startMigrationSpan(ctx context.Context, m *Migration, direction Direction, txMode TxMode) (context.Context, *tracing.Span) {
+	return tracing.Start(ctx, "gomigrate.migration",
+		tracing.VersionKey.String(m.Version),
+		tracing.DirectionKey.String(string(direction)),
+		tracing.TxModeKey.String(string(txMode)))
+}
+
+// statementNotifier returns func notifying observer about executed statements of migration m.
+func statementNotifier(observer Observer, m *Migration, direction Direction, txMode TxMode) statementFunc {
+	return func(statement string, duration time.Duration, err error) {
+		e := migrationEvent(EventStatementExecuted, m, direction, txMode)
+		e.Statement = statement
+		e.Duration = duration
+		e.Err = err
+		observer.Notify(e)
+	}
+}
+
+// ConsoleObserver writes migrations progress to the log, it is the CLI output.
+type ConsoleObserver struct{}
+
+func (ConsoleObserver) Notify(e *Event) {
+	name := filepath.Base(e.Source)
+
+	switch e.Type {
+	case EventMigrationStarted:
+		if e.Direction == DirectionUp {
+			log.Warnf("***[%s] applying %s", strings.ToUpper(string(e.TxMode)), name)
+
+			return
+		}
+
+		log.Warnf("***[%s] reverting %s", strings.ToUpper(string(e.TxMode)), name)
+	case EventStatementExecuted:
+		log.Debugf("Executed SQL statement: %s (time: %.3f sec.)\n", e.Statement, e.Duration.Seconds())
+	case EventMigrationApplied:
+		log.Infof("*** applied %s (time: %.3f sec.)\n", name, e.Duration.Seconds())
+	case EventMigrationReverted:
+		log.Infof("*** reverted %s (time: %.3f sec.)\n", name, e.Duration.Seconds())
+	case EventMigrationFailed:
+		if e.Direction == DirectionUp {
+			log.Errf(failedToApplyLogText, name, e.Duration.Seconds())
+
+			return
+		}
+
+		log.Errf(failedToRevertLogText, name, e.Duration.Seconds())
+	case EventRunStarted, EventRunFinished:
+		// actions write run summary themselves
+	}
+}
@@ -0,0 +1,207 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gojuno/minimock/v3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+	"github.com/tweety53/gomigrate/internal/tracing"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recorder collects types of events of migration versions.
+type recorder []string
+
+func (r *recorder) Notify(e *Event) {
+	*r = append(*r, string(e.Type)+" "+e.Version+" "+string(e.TxMode))
+}
+
+func TestRunnerObservers(t *testing.T) {
+	errSome := errors.New("some error")
+	insertVersion := regexp.QuoteMeta("INSERT INTO migration (version, apply_time) VALUES ($1, $2);")
+
+	tests := []struct {
+		name       string
+		m          *Migration
+		expect     func(mock sqlmock.Sqlmock)
+		wantEvents recorder
+	}{
+		{
+			name: "applied",
+			m:    &Migration{Version: "m200101_000000_test", UpFn: func(*sql.DB) error { return nil }},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(insertVersion).WithArgs("m200101_000000_test", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantEvents: recorder{
+				"migration_started m200101_000000_test non-transactional",
+				"migration_applied m200101_000000_test non-transactional",
+			},
+		},
+		{
+			name:   "failed",
+			m:      &Migration{Version: "m200101_000000_test", UpFn: func(*sql.DB) error { return errSome }},
+			expect: func(mock sqlmock.Sqlmock) {},
+			wantEvents: recorder{
+				"migration_started m200101_000000_test non-transactional",
+				"migration_failed m200101_000000_test non-transactional",
+			},
+		},
+		{
+			name:       "empty fn not observed",
+			m:          &Migration{Version: "m200101_000000_test"},
+			expect:     func(mock sqlmock.Sqlmock) {},
+			wantEvents: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			dialect, err := sqldialect.InitDialect("postgres", "migration")
+			require.NoError(t, err)
+			tt.expect(mock)
+
+			var events recorder
+			_ = NewRunner(nil, &events).MigrateUp(context.Background(), repo.NewMigrationsRepository(db, dialect), tt.m)
+			require.Equal(t, tt.wantEvents, events)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRunAtomicObservers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	dialect, err := sqldialect.InitDialect("postgres", "migration")
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO migration")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	up := Migrations{
+		{Version: "m200101_000000_test", Source: "m200101_000000_test.go", Registered: true, SafeUpFn: func(*sql.Tx) error { return nil }},
+		{Version: "m200101_000001_test", Source: "m200101_000001_test.go", Registered: true, SafeUpFn: func(*sql.Tx) error { return errors.New("some error") }},
+	}
+
+	var events recorder
+	require.Error(t, RunAtomic(context.Background(), repo.NewMigrationsRepository(db, dialect), nil, Observers{&events}, nil, up))
+	require.Equal(t, recorder{
+		"migration_started m200101_000000_test atomic",
+		"migration_applied m200101_000000_test atomic",
+		"migration_started m200101_000001_test atomic",
+		"migration_failed m200101_000001_test atomic",
+	}, events)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatementEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE a (id int)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE a (id int)")).WillReturnError(errors.New("already exists"))
+
+	var events []*Event
+	m := &Migration{Version: "m200101_000000_test"}
+	onExec := statementNotifier(Observers{ObserverFunc(func(e *Event) { events = append(events, e) })}, m, DirectionUp, TxModeNone)
+
+	err = assembleFnFromStatements([]string{"CREATE TABLE a (id int);", "CREATE TABLE a (id int);"}, onExec)(context.Background(), db)
+	require.Error(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, EventStatementExecuted, events[0].Type)
+	require.Equal(t, "m200101_000000_test", events[0].Version)
+	require.NoError(t, events[0].Err)
+	require.Error(t, events[1].Err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatementEvents_RunnerInterface(t *testing.T) {
+	dir, err := ioutil.TempDir("", "observer")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "m200101_000000_test.sql")
+	require.NoError(t, ioutil.WriteFile(source, []byte("-- +gomigrate NO TRANSACTION\n-- +gomigrate Up\nCREATE TABLE a (id int);\n"), 0600))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE a (id int)")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	var events []*Event
+	mc := minimock.NewController(t)
+	runner := NewRunnerInterfaceMock(mc).
+		MigrateUpMock.Set(func(_ context.Context, _ repo.MigrationRepo, m *Migration) error { return m.UpFn(db) }).
+		NotifyMock.Set(func(e *Event) { events = append(events, e) })
+
+	m := &Migration{Version: "m200101_000000_test", Source: source}
+	require.NoError(t, m.Up(context.Background(), repo.NewMigrationRepoMock(mc), runner))
+	require.Len(t, events, 1)
+	require.Equal(t, EventStatementExecuted, events[0].Type)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunnerTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracing.SetTracerProvider(tp)
+	defer tracing.SetTracerProvider(nil)
+
+	dir, err := ioutil.TempDir("", "tracing")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "m200101_000000_test.sql")
+	require.NoError(t, ioutil.WriteFile(source, []byte(`-- +gomigrate NO TRANSACTION
+-- +gomigrate Up
+INSERT INTO secrets VALUES ('password', 1);
+-- +gomigrate Down
+DELETE FROM secrets;
+`), 0600))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	dialect, err := sqldialect.InitDialect("postgres", "migration")
+	require.NoError(t, err)
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO secrets VALUES ('password', 1)")).WillReturnResult(sqlmock.NewResult(0, 1))
+	// down script is written in the tx of the version
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO migration ")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO migration_down")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// span of the library caller is the parent of the migration span
+	ctx, caller := tp.Tracer("caller").Start(context.Background(), "caller")
+	m := &Migration{Version: "m200101_000000_test", Source: source}
+	require.NoError(t, m.Up(ctx, repo.NewMigrationsRepository(db, dialect), NewRunner(nil)))
+	caller.End()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 5)
+
+	statement, insertVersion, migration := spans[0], spans[1], spans[3]
+	require.Equal(t, "gomigrate.statement", statement.Name)
+	require.Contains(t, statement.Attributes, tracing.StatementKey.String("INSERT INTO secrets VALUES ('?', ?);\n"))
+	require.Equal(t, "gomigrate.repo.exec", insertVersion.Name)
+	require.Equal(t, "gomigrate.migration", migration.Name)
+	require.Contains(t, migration.Attributes, tracing.VersionKey.String("m200101_000000_test"))
+	require.Equal(t, migration.SpanContext.SpanID(), statement.Parent.SpanID())
+	require.Equal(t, migration.SpanContext.SpanID(), insertVersion.Parent.SpanID())
+	require.Equal(t, migration.SpanContext.SpanID(), spans[2].Parent.SpanID())
+	require.Equal(t, caller.SpanContext().SpanID(), migration.Parent.SpanID())
+}
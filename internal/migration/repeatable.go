@@ -0,0 +1,146 @@
+package migration
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/repo"
+)
+
+// RepeatablePrefix marks SQL migrations which are reapplied every time their content changes.
+const RepeatablePrefix = "r_"
+
+type RepeatableMigration struct {
+	Name     string // file name without extension, e.g. r_active_users_view
+	Source   string
+	Checksum string
+	parser   *SQLParser
+}
+
+type RepeatableMigrations []*RepeatableMigration
+
+func (m *RepeatableMigration) String() string {
+	return m.Name
+}
+
+func (ms RepeatableMigrations) String() string {
+	str := "\n"
+	for _, m := range ms {
+		str += fmt.Sprintln(m)
+	}
+
+	return str
+}
+
+// IsRepeatableFile reports whether file is repeatable migration and has no version.
+func IsRepeatableFile(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), RepeatablePrefix) && filepath.Ext(path) == "."+string(TypeSQL)
+}
+
+// CollectRepeatableMigrations returns repeatable migrations from the migrations folder sorted by name.
+func (c *MigrationsCollector) CollectRepeatableMigrations(dirpath string) (RepeatableMigrations, error) {
+	files, err := filepath.Glob(filepath.Join(dirpath, RepeatablePrefix+"*."+string(TypeSQL)))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	migrations := make(RepeatableMigrations, 0, len(files))
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read repeatable migration file: %s", file)
+		}
+
+		sum := sha256.Sum256(content)
+		migrations = append(migrations, &RepeatableMigration{
+			Name:     strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+			Source:   file,
+			Checksum: hex.EncodeToString(sum[:]),
+			parser:   c.Parser,
+		})
+	}
+
+	return migrations, nil
+}
+
+// Apply executes migration statements with ctx and records its checksum.
+func (m *RepeatableMigration) Apply(ctx context.Context, db *sql.DB, repo repo.RepeatableMigrationRepo) error {
+	content, err := ioutil.ReadFile(m.Source)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read repeatable migration file: %s", filepath.Base(m.Source))
+	}
+
+	statements, useTx, err := m.parser.parsePlain(bytes.NewReader(content))
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse repeatable migration file: %s", filepath.Base(m.Source))
+	}
+
+	if useTx {
+		log.Warnf("***[TRANSACTIONAL] applying %s", filepath.Base(m.Source))
+	} else {
+		log.Warnf("***[NON-TRANSACTIONAL] applying %s", filepath.Base(m.Source))
+	}
+
+	start := time.Now()
+	if err := applyStatements(ctx, db, repo, statements, useTx, m); err != nil {
+		log.Errf(failedToApplyLogText, filepath.Base(m.Source), time.Since(start).Seconds())
+
+		return err
+	}
+
+	log.Infof("*** applied %s (time: %.3f sec.)\n", filepath.Base(m.Source), time.Since(start).Seconds())
+
+	return nil
+}
+
+// applyStatements runs statements and saves checksum of m in the same transaction,
+// so a crash never leaves an applied migration without its checksum.
+// Checksum of non-transactional migration is saved after all statements succeeded.
+func applyStatements(ctx context.Context, db *sql.DB, r repo.RepeatableMigrationRepo, statements []string, useTx bool, m *RepeatableMigration) error {
+	if !useTx {
+		if err := assembleFnFromStatements(statements, debugStatement)(ctx, db); err != nil {
+			return err
+		}
+
+		return errors.Wrap(r.SaveChecksum(m.Name, m.Checksum), "failed to save repeatable migration checksum")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	// fn rollbacks tx itself on failure
+	if err := assembleSafeFnFromStatements(statements, debugStatement)(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := r.WithTx(tx).SaveChecksum(m.Name, m.Checksum); err != nil {
+		if txErr := tx.Rollback(); txErr != nil {
+			return errors.Wrap(txErr, "save repeatable migration checksum tx rollback failed")
+		}
+
+		return errors.Wrap(err, "failed to save repeatable migration checksum")
+	}
+
+	return errors.Wrap(tx.Commit(), "failed to commit transaction")
+}
+
+// parsePlain parses SQL script without Up/Down annotations.
+func (p *SQLParser) parsePlain(r io.Reader) ([]string, bool, error) {
+	return p.parse(io.MultiReader(strings.NewReader("-- +gomigrate Up\n"), r), DirectionUp)
+}
@@ -0,0 +1,120 @@
+package migration
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+)
+
+func TestCollectRepeatableMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		dirpath string
+		want    RepeatableMigrations
+	}{
+		{
+			name:    "repeatable migrations found",
+			dirpath: "testdata/migrations_test/",
+			want: RepeatableMigrations{
+				&RepeatableMigration{
+					Name:     "r_active_accounts_view",
+					Source:   "testdata/migrations_test/r_active_accounts_view.sql",
+					Checksum: "553f61e1ad62ed09290e81c6d604098275f7a3d32595b5911c805e9e48888a9c",
+				},
+			},
+		},
+		{
+			name:    "no repeatable migrations",
+			dirpath: "testdata/runner_test/",
+			want:    RepeatableMigrations{},
+		},
+	}
+
+	c := &MigrationsCollector{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.CollectRepeatableMigrations(tt.dirpath)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIsRepeatableFile(t *testing.T) {
+	require.True(t, IsRepeatableFile("migrations/r_views.sql"))
+	require.False(t, IsRepeatableFile("migrations/r_views.go"))
+	require.False(t, IsRepeatableFile("migrations/m200101_000000_r_views.sql"))
+}
+
+func TestParsePlainSQL(t *testing.T) {
+	statements, useTx, err := defaultSQLParser.parsePlain(strings.NewReader(`-- +gomigrate NO TRANSACTION
+CREATE INDEX CONCURRENTLY accounts_email_idx ON accounts (email);
+`))
+	require.NoError(t, err)
+	require.False(t, useTx)
+	require.Len(t, statements, 1)
+}
+
+func TestRepeatableMigration_Apply(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repeatable")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "r_views.sql")
+	require.NoError(t, ioutil.WriteFile(source, []byte("CREATE OR REPLACE VIEW v AS SELECT 1;\n"), 0600))
+
+	dialect, err := sqldialect.InitDialect("postgres", "migration")
+	require.NoError(t, err)
+
+	createView := regexp.QuoteMeta("CREATE OR REPLACE VIEW v AS SELECT 1;")
+	saveChecksum := regexp.QuoteMeta("INSERT INTO migration_repeatable (name, checksum, apply_time)")
+	errSome := errors.New("some error")
+
+	tests := []struct {
+		name    string
+		expect  func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "checksum is saved in migration transaction",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(createView).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(saveChecksum).WithArgs("r_views", "abc", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "failed checksum rolls back statements",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(createView).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(saveChecksum).WillReturnError(errSome)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tt.expect(mock)
+
+			m := &RepeatableMigration{Name: "r_views", Source: source, Checksum: "abc"}
+			err = m.Apply(context.Background(), db, repo.NewRepeatableMigrationsRepository(db, dialect))
+			require.Equal(t, tt.wantErr, err != nil)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
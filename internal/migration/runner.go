@@ -1,243 +1,344 @@
 package migration
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
-	"fmt"
 	"path/filepath"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tweety53/gomigrate/internal/log"
 	"github.com/tweety53/gomigrate/internal/repo"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
 )
 
-const (
-	failedToRevertLogText = "*** failed to revert %s (time: %.3f sec.)\n"
-	failedToApplyLogText  = "*** failed to apply %s (time: %.3f sec.)\n"
-)
-
+// RunnerInterface runs migration fns writing their versions, ctx carries span of the migration.
 type RunnerInterface interface {
-	MigrateUp(repo repo.MigrationRepo, m *Migration) error
-	MigrateUpSafe(repo repo.MigrationRepo, m *Migration) error
-	MigrateDown(repo repo.MigrationRepo, m *Migration) error
-	MigrateDownSafe(repo repo.MigrationRepo, m *Migration) error
+	MigrateUp(ctx context.Context, repo repo.MigrationRepo, m *Migration) error
+	MigrateUpSafe(ctx context.Context, repo repo.MigrationRepo, m *Migration) error
+	MigrateDown(ctx context.Context, repo repo.MigrationRepo, m *Migration) error
+	MigrateDownSafe(ctx context.Context, repo repo.MigrationRepo, m *Migration) error
+	// Notify sends event to runner observers, e.g. statement executed by migration fn.
+	Notify(e *Event)
 }
 
-type Runner struct{}
+type Runner struct {
+	Hooks     *Hooks
+	Observers Observers
+}
+
+func NewRunner(hooks *Hooks, observers ...Observer) *Runner {
+	return &Runner{Hooks: hooks, Observers: observers}
+}
+
+func (r *Runner) Notify(e *Event) {
+	r.Observers.Notify(e)
+}
+
+func (r *Runner) MigrateUp(ctx context.Context, repo repo.MigrationRepo, m *Migration) error {
+	return r.observed(DirectionUp, TxModeNone, m.UpFn != nil, r.migrateUp)(ctx, repo, m)
+}
+
+func (r *Runner) MigrateUpSafe(ctx context.Context, repo repo.MigrationRepo, m *Migration) error {
+	return r.observed(DirectionUp, TxModeSingle, m.SafeUpFn != nil, r.migrateUpSafe)(ctx, repo, m)
+}
+
+func (r *Runner) MigrateDown(ctx context.Context, repo repo.MigrationRepo, m *Migration) error {
+	return r.observed(DirectionDown, TxModeNone, m.DownFn != nil, r.migrateDown)(ctx, repo, m)
+}
+
+func (r *Runner) MigrateDownSafe(ctx context.Context, repo repo.MigrationRepo, m *Migration) error {
+	return r.observed(DirectionDown, TxModeSingle, m.SafeDownFn != nil, r.migrateDownSafe)(ctx, repo, m)
+}
+
+// observed wraps migration with started and applied/reverted/failed events,
+// migration without fn is not observed as there is nothing to run.
+// Repository queries are run with ctx, so they are traced as children of the migration span.
+func (r *Runner) observed(
+	direction Direction,
+	txMode TxMode,
+	hasFn bool,
+	migrate func(context.Context, repo.MigrationRepo, *Migration) error,
+) func(context.Context, repo.MigrationRepo, *Migration) error {
+	if !hasFn {
+		return migrate
+	}
+
+	return func(ctx context.Context, mRepo repo.MigrationRepo, m *Migration) error {
+		r.Observers.Notify(migrationEvent(EventMigrationStarted, m, direction, txMode))
+
+		start := time.Now()
+		err := migrate(ctx, withContext(ctx, mRepo), m)
+
+		r.Observers.Notify(migrationFinishedEvent(m, direction, txMode, time.Since(start), err))
+
+		return err
+	}
+}
+
+// withContext returns repository running its queries with ctx if it supports it.
+func withContext(ctx context.Context, r repo.MigrationRepo) repo.MigrationRepo {
+	if cr, ok := r.(repo.ContextRepo); ok {
+		return cr.WithContext(ctx)
+	}
+
+	return r
+}
+
+// hookedSafeFn wraps migration fn with before_each and after_each hooks run in the migration tx,
+// so session settings like SET LOCAL lock_timeout apply to the migration.
+func (r *Runner) hookedSafeFn(ctx context.Context, m *Migration, direction Direction, fn func(*sql.Tx) error) func(*sql.Tx) error {
+	if !r.Hooks.Has(HookBeforeEach) && !r.Hooks.Has(HookAfterEach) {
+		return fn
+	}
+
+	return func(tx *sql.Tx) error {
+		return r.Hooks.runEach(ctx, tx, m, direction, func() error { return fn(tx) })
+	}
+}
+
+// hookedFn wraps non-transactional migration fn with before_each and after_each hooks,
+// they run on the pool as migration statements do.
+func (r *Runner) hookedFn(ctx context.Context, m *Migration, direction Direction, fn func(*sql.DB) error) func(*sql.DB) error {
+	if !r.Hooks.Has(HookBeforeEach) && !r.Hooks.Has(HookAfterEach) {
+		return fn
+	}
+
+	return func(db *sql.DB) error {
+		return r.Hooks.runEach(ctx, db, m, direction, func() error { return fn(db) })
+	}
+}
 
 //nolint:dupl // because its lie :)
-func (r *Runner) MigrateUp(repo repo.MigrationRepo, m *Migration) error {
+func (r *Runner) migrateUp(ctx context.Context, repo repo.MigrationRepo, m *Migration) error {
 	fn := m.UpFn
-	log.Warnf("***[NON-TRANSACTIONAL] applying %s", filepath.Base(m.Source))
-	start := time.Now()
 	if fn != nil {
-		err := migrateNoTx(repo, m, start, fn, failedToApplyLogText)
+		err := migrateNoTx(repo, r.hookedFn(ctx, m, DirectionUp, fn))
 		if err != nil {
 			return err
 		}
 
-		if err := repo.InsertVersion(m.Version); err != nil {
-			duration := time.Since(start)
-			log.Errf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
-
-			return errors.Wrap(err, "failed to insert migration version")
-		}
-
-		duration := time.Since(start)
-		log.Infof("*** applied %s (time: %.3f sec.)\n", filepath.Base(m.Source), duration.Seconds())
-
-		return nil
+		return insertVersion(repo, m)
 	}
 
-	duration := time.Since(start)
-	log.Warnf("*** NOT applied %s (empty fn()) (time: %.3f sec.)\n", filepath.Base(m.Source), duration.Seconds())
+	log.Warnf("*** NOT applied %s (empty fn())\n", filepath.Base(m.Source))
 
 	return nil
 }
 
-//nolint:nestif //im tired
-func (r *Runner) MigrateUpSafe(repo repo.MigrationRepo, m *Migration) error {
+func (r *Runner) migrateUpSafe(ctx context.Context, repo repo.MigrationRepo, m *Migration) error {
 	fn := m.SafeUpFn
-
-	log.Warnf("***[TRANSACTIONAL] applying %s", filepath.Base(m.Source))
-	start := time.Now()
 	if fn != nil {
 		db, err := repo.GetDB()
 		if err != nil {
-			duration := time.Since(start)
-			log.Errf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
-
 			return errors.Wrap(err, "db not initialized")
 		}
 
 		tx, err := db.Begin()
 		if err != nil {
-			duration := time.Since(start)
-			log.Errf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
-
 			return errors.Wrap(err, "failed to begin transaction")
 		}
 
 		if err := repo.InsertUnAppliedVersion(m.Version); err != nil {
-			return handleInsertUnappliedVersionError(tx, start, m, err)
+			return handleInsertUnappliedVersionError(tx, err)
 		}
 
 		// Run Go migration function.
-		if err := fn(tx); err != nil {
-			return handleGoFuncError(repo, m, tx, start, fn, failedToApplyLogText)
+		if err := r.hookedSafeFn(ctx, m, DirectionUp, fn)(tx); err != nil {
+			return handleGoFuncError(repo, m, tx, fn, err)
+		}
+
+		if err := m.saveApplied(withTx(repo, tx)); err != nil {
+			return handleSaveAppliedError(repo, m, tx, err)
 		}
 
 		if err := repo.UpdateApplyTime(m.Version); err != nil {
-			return handleUpdateApplyTimeError(repo, m, tx, start)
+			return handleUpdateApplyTimeError(repo, m, tx)
 		}
 
 		if err := tx.Commit(); err != nil {
-			duration := time.Since(start)
-			log.Errf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
-
 			return errors.Wrap(err, "failed to commit transaction")
 		}
 
-		duration := time.Since(start)
-		log.Infof("*** applied %s (time: %.3f sec.)\n", filepath.Base(m.Source), duration.Seconds())
-
 		return nil
 	}
 
-	duration := time.Since(start)
-	log.Warnf("*** NOT applied %s (empty fn()) (time: %.3f sec.)\n", filepath.Base(m.Source), duration.Seconds())
+	log.Warnf("*** NOT applied %s (empty fn())\n", filepath.Base(m.Source))
 
 	return nil
 }
 
-func handleInsertUnappliedVersionError(tx *sql.Tx, start time.Time, m *Migration, err error) error {
-	txErr := tx.Rollback()
-	if txErr != nil {
-		duration := time.Since(start)
-		log.Warnf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
-		log.Warn("This version is currently being applied by another app")
+// insertVersion writes version of applied non-transactional migration, its down script and batch are written
+// in the same tx if repository supports it.
+func insertVersion(r repo.MigrationRepo, m *Migration) error {
+	if _, ok := r.(repo.TxRepo); !ok {
+		if err := r.InsertVersion(m.Version); err != nil {
+			return errors.Wrap(err, "failed to insert migration version")
+		}
+
+		return m.saveApplied(r)
+	}
 
-		return errors.Wrap(txErr, "insert unapplied version query tx rollback failed")
+	db, err := r.GetDB()
+	if err != nil {
+		return errors.Wrap(err, "db not initialized")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin insert version transaction")
+	}
+
+	txRepo := withTx(r, tx)
+	if err := txRepo.InsertVersion(m.Version); err != nil {
+		return rollback(tx, errors.Wrap(err, "failed to insert migration version"))
+	}
+
+	if err := m.saveApplied(txRepo); err != nil {
+		return rollback(tx, err)
+	}
+
+	return errors.Wrap(tx.Commit(), "failed to commit insert version transaction")
+}
+
+// deleteVersion deletes version of reverted non-transactional migration, versions squashed into it
+// are deleted in the same tx if repository supports it.
+func deleteVersion(r repo.MigrationRepo, m *Migration) error {
+	if _, ok := r.(repo.TxRepo); !ok {
+		if err := r.DeleteVersion(m.Version); err != nil {
+			return errors.Wrap(err, "failed to delete migration version")
+		}
+
+		return m.deleteReverted(r)
+	}
+
+	db, err := r.GetDB()
+	if err != nil {
+		return errors.Wrap(err, "db not initialized")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin delete version transaction")
+	}
+
+	txRepo := withTx(r, tx)
+	if err := txRepo.DeleteVersion(m.Version); err != nil {
+		return rollback(tx, errors.Wrap(err, "failed to delete migration version"))
+	}
+
+	if err := m.deleteReverted(txRepo); err != nil {
+		return rollback(tx, err)
 	}
 
-	duration := time.Since(start)
-	log.Warnf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
+	return errors.Wrap(tx.Commit(), "failed to commit delete version transaction")
+}
+
+// withTx returns repository running its queries in tx if it supports it.
+func withTx(r repo.MigrationRepo, tx *sql.Tx) repo.MigrationRepo {
+	if tr, ok := r.(repo.TxRepo); ok {
+		return tr.WithTx(tx)
+	}
+
+	return r
+}
+
+// rollback rolls tx back after err.
+func rollback(tx *sql.Tx, err error) error {
+	if txErr := tx.Rollback(); txErr != nil {
+		return errors.Wrapf(err, "tx rollback failed: %v", txErr)
+	}
+
+	return err
+}
+
+func handleInsertUnappliedVersionError(tx *sql.Tx, err error) error {
 	log.Warn("This version is currently being applied by another app")
 
-	return errors.Wrap(err, "gomigrate runner: cant migrate")
+	if txErr := tx.Rollback(); txErr != nil {
+		return errors.Wrap(txErr, "insert unapplied version query tx rollback failed")
+	}
+
+	return lockError(errors.Wrap(err, "gomigrate runner: cant migrate"))
 }
 
 //nolint:dupl // because its lie :)
-func (r *Runner) MigrateDown(repo repo.MigrationRepo, m *Migration) error {
+func (r *Runner) migrateDown(ctx context.Context, repo repo.MigrationRepo, m *Migration) error {
 	fn := m.DownFn
-	log.Warnf("***[NON-TRANSACTIONAL] reverting %s", filepath.Base(m.Source))
-	start := time.Now()
 	if fn != nil {
-		err := migrateNoTx(repo, m, start, fn, failedToRevertLogText)
+		err := migrateNoTx(repo, r.hookedFn(ctx, m, DirectionDown, fn))
 		if err != nil {
 			return err
 		}
 
-		if err := repo.DeleteVersion(m.Version); err != nil {
-			duration := time.Since(start)
-			log.Errf(failedToRevertLogText, filepath.Base(m.Source), duration.Seconds())
-
-			return errors.Wrap(err, "failed to delete migration version")
-		}
-
-		duration := time.Since(start)
-		log.Infof("*** reverted %s (time: %.3f sec.)\n", filepath.Base(m.Source), duration.Seconds())
-
-		return nil
+		return deleteVersion(repo, m)
 	}
 
-	duration := time.Since(start)
-	log.Warnf("*** NOT reverted %s (empty fn()) (time: %.3f sec.)\n", filepath.Base(m.Source), duration.Seconds())
+	log.Warnf("*** NOT reverted %s (empty fn())\n", filepath.Base(m.Source))
 
 	return nil
 }
 
-func migrateNoTx(repo repo.MigrationRepo, m *Migration, start time.Time, fn func(*sql.DB) error, logText string) error {
+func migrateNoTx(repo repo.MigrationRepo, fn func(*sql.DB) error) error {
 	db, err := repo.GetDB()
 	if err != nil {
-		duration := time.Since(start)
-		log.Errf(logText, filepath.Base(m.Source), duration.Seconds())
-
 		return errors.Wrap(err, "gomigrate runner: cant migrate")
 	}
 
 	if err := fn(db); err != nil {
-		duration := time.Since(start)
-		log.Errf(logText, filepath.Base(m.Source), duration.Seconds())
-
 		return errors.Wrap(err, "failed to execute go fn()")
 	}
 
 	return nil
 }
 
-//nolint:nestif // im tired
-func (r *Runner) MigrateDownSafe(repo repo.MigrationRepo, m *Migration) error {
+func (r *Runner) migrateDownSafe(ctx context.Context, repo repo.MigrationRepo, m *Migration) error {
 	fn := m.SafeDownFn
-
-	log.Warnf("***[TRANSACTIONAL] reverting %s", filepath.Base(m.Source))
-	start := time.Now()
 	if fn != nil {
 		db, err := repo.GetDB()
 		if err != nil {
-			duration := time.Since(start)
-			log.Errf(failedToRevertLogText, filepath.Base(m.Source), duration.Seconds())
-
 			return errors.Wrap(err, "gomigrate runner: cant migrate")
 		}
 
 		tx, err := db.Begin()
 		if err != nil {
-			duration := time.Since(start)
-			log.Errf(failedToRevertLogText, filepath.Base(m.Source), duration.Seconds())
-
 			return errors.Wrap(err, "failed to begin transaction")
 		}
 
 		if err := repo.LockVersion(m.Version); err != nil {
-			return handleLockVersionError(tx, start, m, err)
+			return handleLockVersionError(tx, err)
 		}
 
 		// Run Go migration function.
-		if err := fn(tx); err != nil {
-			return handleGoFuncError(repo, m, tx, start, fn, failedToRevertLogText)
+		if err := r.hookedSafeFn(ctx, m, DirectionDown, fn)(tx); err != nil {
+			return handleGoFuncError(repo, m, tx, fn, err)
 		}
 
-		if err := repo.DeleteVersion(m.Version); err != nil {
-			return handleDeleteVersionError(tx, start, failedToRevertLogText, m, err)
+		txRepo := withTx(repo, tx)
+		if err := txRepo.DeleteVersion(m.Version); err != nil {
+			return handleDeleteVersionError(tx, err)
 		}
 
-		if err := tx.Commit(); err != nil {
-			duration := time.Since(start)
-			log.Errf(failedToRevertLogText, filepath.Base(m.Source), duration.Seconds())
+		if err := m.deleteReverted(txRepo); err != nil {
+			return rollback(tx, err)
+		}
 
+		if err := tx.Commit(); err != nil {
 			return errors.Wrap(err, "failed to commit transaction")
 		}
 
-		duration := time.Since(start)
-		log.Infof("*** reverted %s (time: %.3f sec.)\n", filepath.Base(m.Source), duration.Seconds())
-
 		return nil
 	}
 
-	duration := time.Since(start)
-	log.Warnf("*** NOT reverted %s (empty fn()) (time: %.3f sec.)\n", filepath.Base(m.Source), duration.Seconds())
+	log.Warnf("*** NOT reverted %s (empty fn())\n", filepath.Base(m.Source))
 
 	return nil
 }
 
-func handleUpdateApplyTimeError(repo repo.MigrationRepo, m *Migration, tx driver.Tx, start time.Time) error {
-	txErr := tx.Rollback()
-	if txErr != nil {
-		duration := time.Since(start)
-		log.Errf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
-
+func handleUpdateApplyTimeError(repo repo.MigrationRepo, m *Migration, tx driver.Tx) error {
+	if txErr := tx.Rollback(); txErr != nil {
 		return errors.Wrap(txErr, "update apply time query tx rollback failed")
 	}
 
@@ -252,30 +353,17 @@ func handleUpdateApplyTimeError(repo repo.MigrationRepo, m *Migration, tx driver
 	}
 
 	if err := repo.DeleteVersion(m.Version); err != nil {
-		txErr := tx.Rollback()
-		if txErr != nil {
-			duration := time.Since(start)
-			log.Errf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
-
+		if txErr := tx.Rollback(); txErr != nil {
 			return errors.Wrap(txErr, "delete version query tx rollback failed")
 		}
 
-		duration := time.Since(start)
-		log.Errf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
-
 		return errors.Wrap(err, "failed to execute delete version transaction for unapplied version")
 	}
 
 	if err := tx.Commit(); err != nil {
-		duration := time.Since(start)
-		log.Errf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
-
 		return errors.Wrap(err, "failed to commit delete version transaction")
 	}
 
-	duration := time.Since(start)
-	log.Errf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
-
 	return errors.New("failed to execute update apply time transaction")
 }
 
@@ -283,15 +371,10 @@ func handleGoFuncError(
 	repo repo.MigrationRepo,
 	m *Migration,
 	tx *sql.Tx,
-	start time.Time,
 	fn func(*sql.Tx) error,
-	logText string,
+	fnErr error,
 ) error {
-	txErr := tx.Rollback()
-	if txErr != nil {
-		duration := time.Since(start)
-		log.Errf(logText, filepath.Base(m.Source), duration.Seconds())
-
+	if txErr := tx.Rollback(); txErr != nil {
 		return errors.Wrap(txErr, "failed to rollback failed migration fn() execution")
 	}
 
@@ -306,50 +389,49 @@ func handleGoFuncError(
 	}
 
 	if err := repo.DeleteVersion(m.Version); err != nil {
-		return handleDeleteVersionError(tx, start, logText, m, err)
+		return handleDeleteVersionError(tx, err)
 	}
 
 	if err := tx.Commit(); err != nil {
-		duration := time.Since(start)
-		log.Errf(failedToApplyLogText, filepath.Base(m.Source), duration.Seconds())
-
 		return errors.Wrap(err, "failed to commit delete version transaction")
 	}
 
-	duration := time.Since(start)
-	log.Errf(logText, filepath.Base(m.Source), duration.Seconds())
-
-	return errors.New(fmt.Sprintf("failed to run Go migration function %T", fn))
+	return errors.Wrapf(fnErr, "failed to run Go migration function %T", fn)
 }
 
-func handleDeleteVersionError(tx *sql.Tx, start time.Time, logText string, m *Migration, err error) error {
-	txErr := tx.Rollback()
-	if txErr != nil {
-		duration := time.Since(start)
-		log.Errf(logText, filepath.Base(m.Source), duration.Seconds())
+// handleSaveAppliedError rolls back applied migration whose down script or batch cannot be stored
+// and deletes its unapplied version.
+func handleSaveAppliedError(repo repo.MigrationRepo, m *Migration, tx *sql.Tx, saveErr error) error {
+	if txErr := tx.Rollback(); txErr != nil {
+		return errors.Wrap(txErr, "failed to rollback migration with unsaved down script or batch")
+	}
 
-		return errors.Wrap(err, "failed to rollback delete version transaction for unapplied version")
+	if err := repo.DeleteVersion(m.Version); err != nil {
+		return errors.Wrap(err, "failed to delete unapplied version")
 	}
 
-	duration := time.Since(start)
-	log.Errf(logText, filepath.Base(m.Source), duration.Seconds())
+	return saveErr
+}
+
+func handleDeleteVersionError(tx *sql.Tx, err error) error {
+	if txErr := tx.Rollback(); txErr != nil {
+		return errors.Wrap(err, "failed to rollback delete version transaction for unapplied version")
+	}
 
 	return errors.Wrap(err, "failed to execute delete version transaction for unapplied version")
 }
 
-func handleLockVersionError(tx *sql.Tx, start time.Time, m *Migration, err error) error {
-	txErr := tx.Rollback()
-	if txErr != nil {
-		duration := time.Since(start)
-		log.Errf(failedToRevertLogText, filepath.Base(m.Source), duration.Seconds())
-		log.Warn("This version is currently being reverted by another app")
+func handleLockVersionError(tx *sql.Tx, err error) error {
+	log.Warn("This version is currently being reverted by another app")
 
+	if txErr := tx.Rollback(); txErr != nil {
 		return errors.Wrap(txErr, "lock version query tx rollback failed")
 	}
 
-	duration := time.Since(start)
-	log.Warnf(failedToRevertLogText, filepath.Base(m.Source), duration.Seconds())
-	log.Warn("This version is currently being reverted by another app")
+	return lockError(err)
+}
 
-	return err
+// lockError sets exit code to errors of versions locked by another app.
+func lockError(err error) error {
+	return &errorsInternal.GoMigrateError{Err: err, ExitCode: exitcode.LockConflict}
 }
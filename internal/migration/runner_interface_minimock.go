@@ -3,6 +3,7 @@ package migration
 // Code generated by http://github.com/gojuno/minimock (dev). DO NOT EDIT.
 
 import (
+	"context"
 	"sync"
 	mm_atomic "sync/atomic"
 	mm_time "time"
@@ -15,29 +16,35 @@ import (
 type RunnerInterfaceMock struct {
 	t minimock.Tester
 
-	funcMigrateDown          func(repo repo.MigrationRepo, m *Migration) (err error)
-	inspectFuncMigrateDown   func(repo repo.MigrationRepo, m *Migration)
+	funcMigrateDown          func(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error)
+	inspectFuncMigrateDown   func(ctx context.Context, repo repo.MigrationRepo, m *Migration)
 	afterMigrateDownCounter  uint64
 	beforeMigrateDownCounter uint64
 	MigrateDownMock          mRunnerInterfaceMockMigrateDown
 
-	funcMigrateDownSafe          func(repo repo.MigrationRepo, m *Migration) (err error)
-	inspectFuncMigrateDownSafe   func(repo repo.MigrationRepo, m *Migration)
+	funcMigrateDownSafe          func(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error)
+	inspectFuncMigrateDownSafe   func(ctx context.Context, repo repo.MigrationRepo, m *Migration)
 	afterMigrateDownSafeCounter  uint64
 	beforeMigrateDownSafeCounter uint64
 	MigrateDownSafeMock          mRunnerInterfaceMockMigrateDownSafe
 
-	funcMigrateUp          func(repo repo.MigrationRepo, m *Migration) (err error)
-	inspectFuncMigrateUp   func(repo repo.MigrationRepo, m *Migration)
+	funcMigrateUp          func(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error)
+	inspectFuncMigrateUp   func(ctx context.Context, repo repo.MigrationRepo, m *Migration)
 	afterMigrateUpCounter  uint64
 	beforeMigrateUpCounter uint64
 	MigrateUpMock          mRunnerInterfaceMockMigrateUp
 
-	funcMigrateUpSafe          func(repo repo.MigrationRepo, m *Migration) (err error)
-	inspectFuncMigrateUpSafe   func(repo repo.MigrationRepo, m *Migration)
+	funcMigrateUpSafe          func(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error)
+	inspectFuncMigrateUpSafe   func(ctx context.Context, repo repo.MigrationRepo, m *Migration)
 	afterMigrateUpSafeCounter  uint64
 	beforeMigrateUpSafeCounter uint64
 	MigrateUpSafeMock          mRunnerInterfaceMockMigrateUpSafe
+
+	funcNotify          func(e *Event)
+	inspectFuncNotify   func(e *Event)
+	afterNotifyCounter  uint64
+	beforeNotifyCounter uint64
+	NotifyMock          mRunnerInterfaceMockNotify
 }
 
 // NewRunnerInterfaceMock returns a mock for RunnerInterface
@@ -59,6 +66,9 @@ func NewRunnerInterfaceMock(t minimock.Tester) *RunnerInterfaceMock {
 	m.MigrateUpSafeMock = mRunnerInterfaceMockMigrateUpSafe{mock: m}
 	m.MigrateUpSafeMock.callArgs = []*RunnerInterfaceMockMigrateUpSafeParams{}
 
+	m.NotifyMock = mRunnerInterfaceMockNotify{mock: m}
+	m.NotifyMock.callArgs = []*RunnerInterfaceMockNotifyParams{}
+
 	return m
 }
 
@@ -81,6 +91,7 @@ type RunnerInterfaceMockMigrateDownExpectation struct {
 
 // RunnerInterfaceMockMigrateDownParams contains parameters of the RunnerInterface.MigrateDown
 type RunnerInterfaceMockMigrateDownParams struct {
+	ctx  context.Context
 	repo repo.MigrationRepo
 	m    *Migration
 }
@@ -91,7 +102,7 @@ type RunnerInterfaceMockMigrateDownResults struct {
 }
 
 // Expect sets up expected params for RunnerInterface.MigrateDown
-func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) Expect(repo repo.MigrationRepo, m *Migration) *mRunnerInterfaceMockMigrateDown {
+func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) Expect(ctx context.Context, repo repo.MigrationRepo, m *Migration) *mRunnerInterfaceMockMigrateDown {
 	if mmMigrateDown.mock.funcMigrateDown != nil {
 		mmMigrateDown.mock.t.Fatalf("RunnerInterfaceMock.MigrateDown mock is already set by Set")
 	}
@@ -100,7 +111,7 @@ func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) Expect(repo repo.Migration
 		mmMigrateDown.defaultExpectation = &RunnerInterfaceMockMigrateDownExpectation{}
 	}
 
-	mmMigrateDown.defaultExpectation.params = &RunnerInterfaceMockMigrateDownParams{repo, m}
+	mmMigrateDown.defaultExpectation.params = &RunnerInterfaceMockMigrateDownParams{ctx, repo, m}
 	for _, e := range mmMigrateDown.expectations {
 		if minimock.Equal(e.params, mmMigrateDown.defaultExpectation.params) {
 			mmMigrateDown.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmMigrateDown.defaultExpectation.params)
@@ -111,7 +122,7 @@ func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) Expect(repo repo.Migration
 }
 
 // Inspect accepts an inspector function that has same arguments as the RunnerInterface.MigrateDown
-func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) Inspect(f func(repo repo.MigrationRepo, m *Migration)) *mRunnerInterfaceMockMigrateDown {
+func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) Inspect(f func(ctx context.Context, repo repo.MigrationRepo, m *Migration)) *mRunnerInterfaceMockMigrateDown {
 	if mmMigrateDown.mock.inspectFuncMigrateDown != nil {
 		mmMigrateDown.mock.t.Fatalf("Inspect function is already set for RunnerInterfaceMock.MigrateDown")
 	}
@@ -135,7 +146,7 @@ func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) Return(err error) *RunnerI
 }
 
 //Set uses given function f to mock the RunnerInterface.MigrateDown method
-func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) Set(f func(repo repo.MigrationRepo, m *Migration) (err error)) *RunnerInterfaceMock {
+func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) Set(f func(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error)) *RunnerInterfaceMock {
 	if mmMigrateDown.defaultExpectation != nil {
 		mmMigrateDown.mock.t.Fatalf("Default expectation is already set for the RunnerInterface.MigrateDown method")
 	}
@@ -150,14 +161,14 @@ func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) Set(f func(repo repo.Migra
 
 // When sets expectation for the RunnerInterface.MigrateDown which will trigger the result defined by the following
 // Then helper
-func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) When(repo repo.MigrationRepo, m *Migration) *RunnerInterfaceMockMigrateDownExpectation {
+func (mmMigrateDown *mRunnerInterfaceMockMigrateDown) When(ctx context.Context, repo repo.MigrationRepo, m *Migration) *RunnerInterfaceMockMigrateDownExpectation {
 	if mmMigrateDown.mock.funcMigrateDown != nil {
 		mmMigrateDown.mock.t.Fatalf("RunnerInterfaceMock.MigrateDown mock is already set by Set")
 	}
 
 	expectation := &RunnerInterfaceMockMigrateDownExpectation{
 		mock:   mmMigrateDown.mock,
-		params: &RunnerInterfaceMockMigrateDownParams{repo, m},
+		params: &RunnerInterfaceMockMigrateDownParams{ctx, repo, m},
 	}
 	mmMigrateDown.expectations = append(mmMigrateDown.expectations, expectation)
 	return expectation
@@ -170,15 +181,15 @@ func (e *RunnerInterfaceMockMigrateDownExpectation) Then(err error) *RunnerInter
 }
 
 // MigrateDown implements RunnerInterface
-func (mmMigrateDown *RunnerInterfaceMock) MigrateDown(repo repo.MigrationRepo, m *Migration) (err error) {
+func (mmMigrateDown *RunnerInterfaceMock) MigrateDown(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error) {
 	mm_atomic.AddUint64(&mmMigrateDown.beforeMigrateDownCounter, 1)
 	defer mm_atomic.AddUint64(&mmMigrateDown.afterMigrateDownCounter, 1)
 
 	if mmMigrateDown.inspectFuncMigrateDown != nil {
-		mmMigrateDown.inspectFuncMigrateDown(repo, m)
+		mmMigrateDown.inspectFuncMigrateDown(ctx, repo, m)
 	}
 
-	mm_params := &RunnerInterfaceMockMigrateDownParams{repo, m}
+	mm_params := &RunnerInterfaceMockMigrateDownParams{ctx, repo, m}
 
 	// Record call args
 	mmMigrateDown.MigrateDownMock.mutex.Lock()
@@ -195,7 +206,7 @@ func (mmMigrateDown *RunnerInterfaceMock) MigrateDown(repo repo.MigrationRepo, m
 	if mmMigrateDown.MigrateDownMock.defaultExpectation != nil {
 		mm_atomic.AddUint64(&mmMigrateDown.MigrateDownMock.defaultExpectation.Counter, 1)
 		mm_want := mmMigrateDown.MigrateDownMock.defaultExpectation.params
-		mm_got := RunnerInterfaceMockMigrateDownParams{repo, m}
+		mm_got := RunnerInterfaceMockMigrateDownParams{ctx, repo, m}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
 			mmMigrateDown.t.Errorf("RunnerInterfaceMock.MigrateDown got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
@@ -207,9 +218,9 @@ func (mmMigrateDown *RunnerInterfaceMock) MigrateDown(repo repo.MigrationRepo, m
 		return (*mm_results).err
 	}
 	if mmMigrateDown.funcMigrateDown != nil {
-		return mmMigrateDown.funcMigrateDown(repo, m)
+		return mmMigrateDown.funcMigrateDown(ctx, repo, m)
 	}
-	mmMigrateDown.t.Fatalf("Unexpected call to RunnerInterfaceMock.MigrateDown. %v %v", repo, m)
+	mmMigrateDown.t.Fatalf("Unexpected call to RunnerInterfaceMock.MigrateDown. %v %v %v", ctx, repo, m)
 	return
 }
 
@@ -297,6 +308,7 @@ type RunnerInterfaceMockMigrateDownSafeExpectation struct {
 
 // RunnerInterfaceMockMigrateDownSafeParams contains parameters of the RunnerInterface.MigrateDownSafe
 type RunnerInterfaceMockMigrateDownSafeParams struct {
+	ctx  context.Context
 	repo repo.MigrationRepo
 	m    *Migration
 }
@@ -307,7 +319,7 @@ type RunnerInterfaceMockMigrateDownSafeResults struct {
 }
 
 // Expect sets up expected params for RunnerInterface.MigrateDownSafe
-func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) Expect(repo repo.MigrationRepo, m *Migration) *mRunnerInterfaceMockMigrateDownSafe {
+func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) Expect(ctx context.Context, repo repo.MigrationRepo, m *Migration) *mRunnerInterfaceMockMigrateDownSafe {
 	if mmMigrateDownSafe.mock.funcMigrateDownSafe != nil {
 		mmMigrateDownSafe.mock.t.Fatalf("RunnerInterfaceMock.MigrateDownSafe mock is already set by Set")
 	}
@@ -316,7 +328,7 @@ func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) Expect(repo repo.M
 		mmMigrateDownSafe.defaultExpectation = &RunnerInterfaceMockMigrateDownSafeExpectation{}
 	}
 
-	mmMigrateDownSafe.defaultExpectation.params = &RunnerInterfaceMockMigrateDownSafeParams{repo, m}
+	mmMigrateDownSafe.defaultExpectation.params = &RunnerInterfaceMockMigrateDownSafeParams{ctx, repo, m}
 	for _, e := range mmMigrateDownSafe.expectations {
 		if minimock.Equal(e.params, mmMigrateDownSafe.defaultExpectation.params) {
 			mmMigrateDownSafe.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmMigrateDownSafe.defaultExpectation.params)
@@ -327,7 +339,7 @@ func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) Expect(repo repo.M
 }
 
 // Inspect accepts an inspector function that has same arguments as the RunnerInterface.MigrateDownSafe
-func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) Inspect(f func(repo repo.MigrationRepo, m *Migration)) *mRunnerInterfaceMockMigrateDownSafe {
+func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) Inspect(f func(ctx context.Context, repo repo.MigrationRepo, m *Migration)) *mRunnerInterfaceMockMigrateDownSafe {
 	if mmMigrateDownSafe.mock.inspectFuncMigrateDownSafe != nil {
 		mmMigrateDownSafe.mock.t.Fatalf("Inspect function is already set for RunnerInterfaceMock.MigrateDownSafe")
 	}
@@ -351,7 +363,7 @@ func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) Return(err error)
 }
 
 //Set uses given function f to mock the RunnerInterface.MigrateDownSafe method
-func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) Set(f func(repo repo.MigrationRepo, m *Migration) (err error)) *RunnerInterfaceMock {
+func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) Set(f func(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error)) *RunnerInterfaceMock {
 	if mmMigrateDownSafe.defaultExpectation != nil {
 		mmMigrateDownSafe.mock.t.Fatalf("Default expectation is already set for the RunnerInterface.MigrateDownSafe method")
 	}
@@ -366,14 +378,14 @@ func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) Set(f func(repo re
 
 // When sets expectation for the RunnerInterface.MigrateDownSafe which will trigger the result defined by the following
 // Then helper
-func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) When(repo repo.MigrationRepo, m *Migration) *RunnerInterfaceMockMigrateDownSafeExpectation {
+func (mmMigrateDownSafe *mRunnerInterfaceMockMigrateDownSafe) When(ctx context.Context, repo repo.MigrationRepo, m *Migration) *RunnerInterfaceMockMigrateDownSafeExpectation {
 	if mmMigrateDownSafe.mock.funcMigrateDownSafe != nil {
 		mmMigrateDownSafe.mock.t.Fatalf("RunnerInterfaceMock.MigrateDownSafe mock is already set by Set")
 	}
 
 	expectation := &RunnerInterfaceMockMigrateDownSafeExpectation{
 		mock:   mmMigrateDownSafe.mock,
-		params: &RunnerInterfaceMockMigrateDownSafeParams{repo, m},
+		params: &RunnerInterfaceMockMigrateDownSafeParams{ctx, repo, m},
 	}
 	mmMigrateDownSafe.expectations = append(mmMigrateDownSafe.expectations, expectation)
 	return expectation
@@ -386,15 +398,15 @@ func (e *RunnerInterfaceMockMigrateDownSafeExpectation) Then(err error) *RunnerI
 }
 
 // MigrateDownSafe implements RunnerInterface
-func (mmMigrateDownSafe *RunnerInterfaceMock) MigrateDownSafe(repo repo.MigrationRepo, m *Migration) (err error) {
+func (mmMigrateDownSafe *RunnerInterfaceMock) MigrateDownSafe(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error) {
 	mm_atomic.AddUint64(&mmMigrateDownSafe.beforeMigrateDownSafeCounter, 1)
 	defer mm_atomic.AddUint64(&mmMigrateDownSafe.afterMigrateDownSafeCounter, 1)
 
 	if mmMigrateDownSafe.inspectFuncMigrateDownSafe != nil {
-		mmMigrateDownSafe.inspectFuncMigrateDownSafe(repo, m)
+		mmMigrateDownSafe.inspectFuncMigrateDownSafe(ctx, repo, m)
 	}
 
-	mm_params := &RunnerInterfaceMockMigrateDownSafeParams{repo, m}
+	mm_params := &RunnerInterfaceMockMigrateDownSafeParams{ctx, repo, m}
 
 	// Record call args
 	mmMigrateDownSafe.MigrateDownSafeMock.mutex.Lock()
@@ -411,7 +423,7 @@ func (mmMigrateDownSafe *RunnerInterfaceMock) MigrateDownSafe(repo repo.Migratio
 	if mmMigrateDownSafe.MigrateDownSafeMock.defaultExpectation != nil {
 		mm_atomic.AddUint64(&mmMigrateDownSafe.MigrateDownSafeMock.defaultExpectation.Counter, 1)
 		mm_want := mmMigrateDownSafe.MigrateDownSafeMock.defaultExpectation.params
-		mm_got := RunnerInterfaceMockMigrateDownSafeParams{repo, m}
+		mm_got := RunnerInterfaceMockMigrateDownSafeParams{ctx, repo, m}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
 			mmMigrateDownSafe.t.Errorf("RunnerInterfaceMock.MigrateDownSafe got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
@@ -423,9 +435,9 @@ func (mmMigrateDownSafe *RunnerInterfaceMock) MigrateDownSafe(repo repo.Migratio
 		return (*mm_results).err
 	}
 	if mmMigrateDownSafe.funcMigrateDownSafe != nil {
-		return mmMigrateDownSafe.funcMigrateDownSafe(repo, m)
+		return mmMigrateDownSafe.funcMigrateDownSafe(ctx, repo, m)
 	}
-	mmMigrateDownSafe.t.Fatalf("Unexpected call to RunnerInterfaceMock.MigrateDownSafe. %v %v", repo, m)
+	mmMigrateDownSafe.t.Fatalf("Unexpected call to RunnerInterfaceMock.MigrateDownSafe. %v %v %v", ctx, repo, m)
 	return
 }
 
@@ -513,6 +525,7 @@ type RunnerInterfaceMockMigrateUpExpectation struct {
 
 // RunnerInterfaceMockMigrateUpParams contains parameters of the RunnerInterface.MigrateUp
 type RunnerInterfaceMockMigrateUpParams struct {
+	ctx  context.Context
 	repo repo.MigrationRepo
 	m    *Migration
 }
@@ -523,7 +536,7 @@ type RunnerInterfaceMockMigrateUpResults struct {
 }
 
 // Expect sets up expected params for RunnerInterface.MigrateUp
-func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) Expect(repo repo.MigrationRepo, m *Migration) *mRunnerInterfaceMockMigrateUp {
+func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) Expect(ctx context.Context, repo repo.MigrationRepo, m *Migration) *mRunnerInterfaceMockMigrateUp {
 	if mmMigrateUp.mock.funcMigrateUp != nil {
 		mmMigrateUp.mock.t.Fatalf("RunnerInterfaceMock.MigrateUp mock is already set by Set")
 	}
@@ -532,7 +545,7 @@ func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) Expect(repo repo.MigrationRepo
 		mmMigrateUp.defaultExpectation = &RunnerInterfaceMockMigrateUpExpectation{}
 	}
 
-	mmMigrateUp.defaultExpectation.params = &RunnerInterfaceMockMigrateUpParams{repo, m}
+	mmMigrateUp.defaultExpectation.params = &RunnerInterfaceMockMigrateUpParams{ctx, repo, m}
 	for _, e := range mmMigrateUp.expectations {
 		if minimock.Equal(e.params, mmMigrateUp.defaultExpectation.params) {
 			mmMigrateUp.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmMigrateUp.defaultExpectation.params)
@@ -543,7 +556,7 @@ func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) Expect(repo repo.MigrationRepo
 }
 
 // Inspect accepts an inspector function that has same arguments as the RunnerInterface.MigrateUp
-func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) Inspect(f func(repo repo.MigrationRepo, m *Migration)) *mRunnerInterfaceMockMigrateUp {
+func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) Inspect(f func(ctx context.Context, repo repo.MigrationRepo, m *Migration)) *mRunnerInterfaceMockMigrateUp {
 	if mmMigrateUp.mock.inspectFuncMigrateUp != nil {
 		mmMigrateUp.mock.t.Fatalf("Inspect function is already set for RunnerInterfaceMock.MigrateUp")
 	}
@@ -567,7 +580,7 @@ func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) Return(err error) *RunnerInter
 }
 
 //Set uses given function f to mock the RunnerInterface.MigrateUp method
-func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) Set(f func(repo repo.MigrationRepo, m *Migration) (err error)) *RunnerInterfaceMock {
+func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) Set(f func(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error)) *RunnerInterfaceMock {
 	if mmMigrateUp.defaultExpectation != nil {
 		mmMigrateUp.mock.t.Fatalf("Default expectation is already set for the RunnerInterface.MigrateUp method")
 	}
@@ -582,14 +595,14 @@ func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) Set(f func(repo repo.Migration
 
 // When sets expectation for the RunnerInterface.MigrateUp which will trigger the result defined by the following
 // Then helper
-func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) When(repo repo.MigrationRepo, m *Migration) *RunnerInterfaceMockMigrateUpExpectation {
+func (mmMigrateUp *mRunnerInterfaceMockMigrateUp) When(ctx context.Context, repo repo.MigrationRepo, m *Migration) *RunnerInterfaceMockMigrateUpExpectation {
 	if mmMigrateUp.mock.funcMigrateUp != nil {
 		mmMigrateUp.mock.t.Fatalf("RunnerInterfaceMock.MigrateUp mock is already set by Set")
 	}
 
 	expectation := &RunnerInterfaceMockMigrateUpExpectation{
 		mock:   mmMigrateUp.mock,
-		params: &RunnerInterfaceMockMigrateUpParams{repo, m},
+		params: &RunnerInterfaceMockMigrateUpParams{ctx, repo, m},
 	}
 	mmMigrateUp.expectations = append(mmMigrateUp.expectations, expectation)
 	return expectation
@@ -602,15 +615,15 @@ func (e *RunnerInterfaceMockMigrateUpExpectation) Then(err error) *RunnerInterfa
 }
 
 // MigrateUp implements RunnerInterface
-func (mmMigrateUp *RunnerInterfaceMock) MigrateUp(repo repo.MigrationRepo, m *Migration) (err error) {
+func (mmMigrateUp *RunnerInterfaceMock) MigrateUp(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error) {
 	mm_atomic.AddUint64(&mmMigrateUp.beforeMigrateUpCounter, 1)
 	defer mm_atomic.AddUint64(&mmMigrateUp.afterMigrateUpCounter, 1)
 
 	if mmMigrateUp.inspectFuncMigrateUp != nil {
-		mmMigrateUp.inspectFuncMigrateUp(repo, m)
+		mmMigrateUp.inspectFuncMigrateUp(ctx, repo, m)
 	}
 
-	mm_params := &RunnerInterfaceMockMigrateUpParams{repo, m}
+	mm_params := &RunnerInterfaceMockMigrateUpParams{ctx, repo, m}
 
 	// Record call args
 	mmMigrateUp.MigrateUpMock.mutex.Lock()
@@ -627,7 +640,7 @@ func (mmMigrateUp *RunnerInterfaceMock) MigrateUp(repo repo.MigrationRepo, m *Mi
 	if mmMigrateUp.MigrateUpMock.defaultExpectation != nil {
 		mm_atomic.AddUint64(&mmMigrateUp.MigrateUpMock.defaultExpectation.Counter, 1)
 		mm_want := mmMigrateUp.MigrateUpMock.defaultExpectation.params
-		mm_got := RunnerInterfaceMockMigrateUpParams{repo, m}
+		mm_got := RunnerInterfaceMockMigrateUpParams{ctx, repo, m}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
 			mmMigrateUp.t.Errorf("RunnerInterfaceMock.MigrateUp got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
@@ -639,9 +652,9 @@ func (mmMigrateUp *RunnerInterfaceMock) MigrateUp(repo repo.MigrationRepo, m *Mi
 		return (*mm_results).err
 	}
 	if mmMigrateUp.funcMigrateUp != nil {
-		return mmMigrateUp.funcMigrateUp(repo, m)
+		return mmMigrateUp.funcMigrateUp(ctx, repo, m)
 	}
-	mmMigrateUp.t.Fatalf("Unexpected call to RunnerInterfaceMock.MigrateUp. %v %v", repo, m)
+	mmMigrateUp.t.Fatalf("Unexpected call to RunnerInterfaceMock.MigrateUp. %v %v %v", ctx, repo, m)
 	return
 }
 
@@ -729,6 +742,7 @@ type RunnerInterfaceMockMigrateUpSafeExpectation struct {
 
 // RunnerInterfaceMockMigrateUpSafeParams contains parameters of the RunnerInterface.MigrateUpSafe
 type RunnerInterfaceMockMigrateUpSafeParams struct {
+	ctx  context.Context
 	repo repo.MigrationRepo
 	m    *Migration
 }
@@ -739,7 +753,7 @@ type RunnerInterfaceMockMigrateUpSafeResults struct {
 }
 
 // Expect sets up expected params for RunnerInterface.MigrateUpSafe
-func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) Expect(repo repo.MigrationRepo, m *Migration) *mRunnerInterfaceMockMigrateUpSafe {
+func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) Expect(ctx context.Context, repo repo.MigrationRepo, m *Migration) *mRunnerInterfaceMockMigrateUpSafe {
 	if mmMigrateUpSafe.mock.funcMigrateUpSafe != nil {
 		mmMigrateUpSafe.mock.t.Fatalf("RunnerInterfaceMock.MigrateUpSafe mock is already set by Set")
 	}
@@ -748,7 +762,7 @@ func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) Expect(repo repo.Migra
 		mmMigrateUpSafe.defaultExpectation = &RunnerInterfaceMockMigrateUpSafeExpectation{}
 	}
 
-	mmMigrateUpSafe.defaultExpectation.params = &RunnerInterfaceMockMigrateUpSafeParams{repo, m}
+	mmMigrateUpSafe.defaultExpectation.params = &RunnerInterfaceMockMigrateUpSafeParams{ctx, repo, m}
 	for _, e := range mmMigrateUpSafe.expectations {
 		if minimock.Equal(e.params, mmMigrateUpSafe.defaultExpectation.params) {
 			mmMigrateUpSafe.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmMigrateUpSafe.defaultExpectation.params)
@@ -759,7 +773,7 @@ func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) Expect(repo repo.Migra
 }
 
 // Inspect accepts an inspector function that has same arguments as the RunnerInterface.MigrateUpSafe
-func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) Inspect(f func(repo repo.MigrationRepo, m *Migration)) *mRunnerInterfaceMockMigrateUpSafe {
+func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) Inspect(f func(ctx context.Context, repo repo.MigrationRepo, m *Migration)) *mRunnerInterfaceMockMigrateUpSafe {
 	if mmMigrateUpSafe.mock.inspectFuncMigrateUpSafe != nil {
 		mmMigrateUpSafe.mock.t.Fatalf("Inspect function is already set for RunnerInterfaceMock.MigrateUpSafe")
 	}
@@ -783,7 +797,7 @@ func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) Return(err error) *Run
 }
 
 //Set uses given function f to mock the RunnerInterface.MigrateUpSafe method
-func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) Set(f func(repo repo.MigrationRepo, m *Migration) (err error)) *RunnerInterfaceMock {
+func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) Set(f func(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error)) *RunnerInterfaceMock {
 	if mmMigrateUpSafe.defaultExpectation != nil {
 		mmMigrateUpSafe.mock.t.Fatalf("Default expectation is already set for the RunnerInterface.MigrateUpSafe method")
 	}
@@ -798,14 +812,14 @@ func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) Set(f func(repo repo.M
 
 // When sets expectation for the RunnerInterface.MigrateUpSafe which will trigger the result defined by the following
 // Then helper
-func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) When(repo repo.MigrationRepo, m *Migration) *RunnerInterfaceMockMigrateUpSafeExpectation {
+func (mmMigrateUpSafe *mRunnerInterfaceMockMigrateUpSafe) When(ctx context.Context, repo repo.MigrationRepo, m *Migration) *RunnerInterfaceMockMigrateUpSafeExpectation {
 	if mmMigrateUpSafe.mock.funcMigrateUpSafe != nil {
 		mmMigrateUpSafe.mock.t.Fatalf("RunnerInterfaceMock.MigrateUpSafe mock is already set by Set")
 	}
 
 	expectation := &RunnerInterfaceMockMigrateUpSafeExpectation{
 		mock:   mmMigrateUpSafe.mock,
-		params: &RunnerInterfaceMockMigrateUpSafeParams{repo, m},
+		params: &RunnerInterfaceMockMigrateUpSafeParams{ctx, repo, m},
 	}
 	mmMigrateUpSafe.expectations = append(mmMigrateUpSafe.expectations, expectation)
 	return expectation
@@ -818,15 +832,15 @@ func (e *RunnerInterfaceMockMigrateUpSafeExpectation) Then(err error) *RunnerInt
 }
 
 // MigrateUpSafe implements RunnerInterface
-func (mmMigrateUpSafe *RunnerInterfaceMock) MigrateUpSafe(repo repo.MigrationRepo, m *Migration) (err error) {
+func (mmMigrateUpSafe *RunnerInterfaceMock) MigrateUpSafe(ctx context.Context, repo repo.MigrationRepo, m *Migration) (err error) {
 	mm_atomic.AddUint64(&mmMigrateUpSafe.beforeMigrateUpSafeCounter, 1)
 	defer mm_atomic.AddUint64(&mmMigrateUpSafe.afterMigrateUpSafeCounter, 1)
 
 	if mmMigrateUpSafe.inspectFuncMigrateUpSafe != nil {
-		mmMigrateUpSafe.inspectFuncMigrateUpSafe(repo, m)
+		mmMigrateUpSafe.inspectFuncMigrateUpSafe(ctx, repo, m)
 	}
 
-	mm_params := &RunnerInterfaceMockMigrateUpSafeParams{repo, m}
+	mm_params := &RunnerInterfaceMockMigrateUpSafeParams{ctx, repo, m}
 
 	// Record call args
 	mmMigrateUpSafe.MigrateUpSafeMock.mutex.Lock()
@@ -843,7 +857,7 @@ func (mmMigrateUpSafe *RunnerInterfaceMock) MigrateUpSafe(repo repo.MigrationRep
 	if mmMigrateUpSafe.MigrateUpSafeMock.defaultExpectation != nil {
 		mm_atomic.AddUint64(&mmMigrateUpSafe.MigrateUpSafeMock.defaultExpectation.Counter, 1)
 		mm_want := mmMigrateUpSafe.MigrateUpSafeMock.defaultExpectation.params
-		mm_got := RunnerInterfaceMockMigrateUpSafeParams{repo, m}
+		mm_got := RunnerInterfaceMockMigrateUpSafeParams{ctx, repo, m}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
 			mmMigrateUpSafe.t.Errorf("RunnerInterfaceMock.MigrateUpSafe got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
@@ -855,9 +869,9 @@ func (mmMigrateUpSafe *RunnerInterfaceMock) MigrateUpSafe(repo repo.MigrationRep
 		return (*mm_results).err
 	}
 	if mmMigrateUpSafe.funcMigrateUpSafe != nil {
-		return mmMigrateUpSafe.funcMigrateUpSafe(repo, m)
+		return mmMigrateUpSafe.funcMigrateUpSafe(ctx, repo, m)
 	}
-	mmMigrateUpSafe.t.Fatalf("Unexpected call to RunnerInterfaceMock.MigrateUpSafe. %v %v", repo, m)
+	mmMigrateUpSafe.t.Fatalf("Unexpected call to RunnerInterfaceMock.MigrateUpSafe. %v %v %v", ctx, repo, m)
 	return
 }
 
@@ -926,6 +940,193 @@ func (m *RunnerInterfaceMock) MinimockMigrateUpSafeInspect() {
 	}
 }
 
+type mRunnerInterfaceMockNotify struct {
+	mock               *RunnerInterfaceMock
+	defaultExpectation *RunnerInterfaceMockNotifyExpectation
+	expectations       []*RunnerInterfaceMockNotifyExpectation
+
+	callArgs []*RunnerInterfaceMockNotifyParams
+	mutex    sync.RWMutex
+}
+
+// RunnerInterfaceMockNotifyExpectation specifies expectation struct of the RunnerInterface.Notify
+type RunnerInterfaceMockNotifyExpectation struct {
+	mock   *RunnerInterfaceMock
+	params *RunnerInterfaceMockNotifyParams
+
+	Counter uint64
+}
+
+// RunnerInterfaceMockNotifyParams contains parameters of the RunnerInterface.Notify
+type RunnerInterfaceMockNotifyParams struct {
+	e *Event
+}
+
+// Expect sets up expected params for RunnerInterface.Notify
+func (mmNotify *mRunnerInterfaceMockNotify) Expect(e *Event) *mRunnerInterfaceMockNotify {
+	if mmNotify.mock.funcNotify != nil {
+		mmNotify.mock.t.Fatalf("RunnerInterfaceMock.Notify mock is already set by Set")
+	}
+
+	if mmNotify.defaultExpectation == nil {
+		mmNotify.defaultExpectation = &RunnerInterfaceMockNotifyExpectation{}
+	}
+
+	mmNotify.defaultExpectation.params = &RunnerInterfaceMockNotifyParams{e}
+	for _, e := range mmNotify.expectations {
+		if minimock.Equal(e.params, mmNotify.defaultExpectation.params) {
+			mmNotify.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmNotify.defaultExpectation.params)
+		}
+	}
+
+	return mmNotify
+}
+
+// Inspect accepts an inspector function that has same arguments as the RunnerInterface.Notify
+func (mmNotify *mRunnerInterfaceMockNotify) Inspect(f func(e *Event)) *mRunnerInterfaceMockNotify {
+	if mmNotify.mock.inspectFuncNotify != nil {
+		mmNotify.mock.t.Fatalf("Inspect function is already set for RunnerInterfaceMock.Notify")
+	}
+
+	mmNotify.mock.inspectFuncNotify = f
+
+	return mmNotify
+}
+
+// Return sets up results that will be returned by RunnerInterface.Notify
+func (mmNotify *mRunnerInterfaceMockNotify) Return() *RunnerInterfaceMock {
+	if mmNotify.mock.funcNotify != nil {
+		mmNotify.mock.t.Fatalf("RunnerInterfaceMock.Notify mock is already set by Set")
+	}
+
+	if mmNotify.defaultExpectation == nil {
+		mmNotify.defaultExpectation = &RunnerInterfaceMockNotifyExpectation{mock: mmNotify.mock}
+	}
+
+	return mmNotify.mock
+}
+
+//Set uses given function f to mock the RunnerInterface.Notify method
+func (mmNotify *mRunnerInterfaceMockNotify) Set(f func(e *Event)) *RunnerInterfaceMock {
+	if mmNotify.defaultExpectation != nil {
+		mmNotify.mock.t.Fatalf("Default expectation is already set for the RunnerInterface.Notify method")
+	}
+
+	if len(mmNotify.expectations) > 0 {
+		mmNotify.mock.t.Fatalf("Some expectations are already set for the RunnerInterface.Notify method")
+	}
+
+	mmNotify.mock.funcNotify = f
+	return mmNotify.mock
+}
+
+// Notify implements RunnerInterface
+func (mmNotify *RunnerInterfaceMock) Notify(e *Event) {
+	mm_atomic.AddUint64(&mmNotify.beforeNotifyCounter, 1)
+	defer mm_atomic.AddUint64(&mmNotify.afterNotifyCounter, 1)
+
+	if mmNotify.inspectFuncNotify != nil {
+		mmNotify.inspectFuncNotify(e)
+	}
+
+	mm_params := &RunnerInterfaceMockNotifyParams{e}
+
+	// Record call args
+	mmNotify.NotifyMock.mutex.Lock()
+	mmNotify.NotifyMock.callArgs = append(mmNotify.NotifyMock.callArgs, mm_params)
+	mmNotify.NotifyMock.mutex.Unlock()
+
+	for _, e := range mmNotify.NotifyMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return
+		}
+	}
+
+	if mmNotify.NotifyMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmNotify.NotifyMock.defaultExpectation.Counter, 1)
+		mm_want := mmNotify.NotifyMock.defaultExpectation.params
+		mm_got := RunnerInterfaceMockNotifyParams{e}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmNotify.t.Errorf("RunnerInterfaceMock.Notify got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		return
+
+	}
+	if mmNotify.funcNotify != nil {
+		mmNotify.funcNotify(e)
+		return
+	}
+	mmNotify.t.Fatalf("Unexpected call to RunnerInterfaceMock.Notify. %v", e)
+
+}
+
+// NotifyAfterCounter returns a count of finished RunnerInterfaceMock.Notify invocations
+func (mmNotify *RunnerInterfaceMock) NotifyAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmNotify.afterNotifyCounter)
+}
+
+// NotifyBeforeCounter returns a count of RunnerInterfaceMock.Notify invocations
+func (mmNotify *RunnerInterfaceMock) NotifyBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmNotify.beforeNotifyCounter)
+}
+
+// Calls returns a list of arguments used in each call to RunnerInterfaceMock.Notify.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmNotify *mRunnerInterfaceMockNotify) Calls() []*RunnerInterfaceMockNotifyParams {
+	mmNotify.mutex.RLock()
+
+	argCopy := make([]*RunnerInterfaceMockNotifyParams, len(mmNotify.callArgs))
+	copy(argCopy, mmNotify.callArgs)
+
+	mmNotify.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockNotifyDone returns true if the count of the Notify invocations corresponds
+// the number of defined expectations
+func (m *RunnerInterfaceMock) MinimockNotifyDone() bool {
+	for _, e := range m.NotifyMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.NotifyMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterNotifyCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcNotify != nil && mm_atomic.LoadUint64(&m.afterNotifyCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockNotifyInspect logs each unmet expectation
+func (m *RunnerInterfaceMock) MinimockNotifyInspect() {
+	for _, e := range m.NotifyMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to RunnerInterfaceMock.Notify with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.NotifyMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterNotifyCounter) < 1 {
+		if m.NotifyMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to RunnerInterfaceMock.Notify")
+		} else {
+			m.t.Errorf("Expected call to RunnerInterfaceMock.Notify with params: %#v", *m.NotifyMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcNotify != nil && mm_atomic.LoadUint64(&m.afterNotifyCounter) < 1 {
+		m.t.Error("Expected call to RunnerInterfaceMock.Notify")
+	}
+}
+
 // MinimockFinish checks that all mocked methods have been called the expected number of times
 func (m *RunnerInterfaceMock) MinimockFinish() {
 	if !m.minimockDone() {
@@ -936,6 +1137,8 @@ func (m *RunnerInterfaceMock) MinimockFinish() {
 		m.MinimockMigrateUpInspect()
 
 		m.MinimockMigrateUpSafeInspect()
+
+		m.MinimockNotifyInspect()
 		m.t.FailNow()
 	}
 }
@@ -962,5 +1165,6 @@ func (m *RunnerInterfaceMock) minimockDone() bool {
 		m.MinimockMigrateDownDone() &&
 		m.MinimockMigrateDownSafeDone() &&
 		m.MinimockMigrateUpDone() &&
-		m.MinimockMigrateUpSafeDone()
+		m.MinimockMigrateUpSafeDone() &&
+		m.MinimockNotifyDone()
 }
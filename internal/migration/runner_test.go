@@ -1,14 +1,18 @@
 package migration
 
 import (
+	"context"
 	"database/sql"
+	"regexp"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gojuno/minimock/v3"
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
 	"github.com/tweety53/gomigrate/internal/log"
 	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
 )
 
 func Test_migrateUpGo(t *testing.T) {
@@ -405,7 +409,7 @@ func Test_migrateUpGo(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			runner := &Runner{}
-			if err := runner.MigrateUpSafe(tt.args.repo.mRepo, tt.args.m); (err != nil) != tt.wantErr {
+			if err := runner.MigrateUpSafe(context.Background(), tt.args.repo.mRepo, tt.args.m); (err != nil) != tt.wantErr {
 				t.Errorf("MigrateUpSafe() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err := tt.args.repo.dbMock.ExpectationsWereMet(); err != nil {
@@ -588,7 +592,7 @@ func Test_migrateUp(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			runner := &Runner{}
-			if err := runner.MigrateUp(tt.args.repo.mRepo, tt.args.m); (err != nil) != tt.wantErr {
+			if err := runner.MigrateUp(context.Background(), tt.args.repo.mRepo, tt.args.m); (err != nil) != tt.wantErr {
 				t.Errorf("MigrateUp() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err := tt.args.repo.dbMock.ExpectationsWereMet(); err != nil {
@@ -924,7 +928,7 @@ func Test_migrateDownSafe(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			runner := &Runner{}
-			if err := runner.MigrateDownSafe(tt.args.repo.mRepo, tt.args.m); (err != nil) != tt.wantErr {
+			if err := runner.MigrateDownSafe(context.Background(), tt.args.repo.mRepo, tt.args.m); (err != nil) != tt.wantErr {
 				t.Errorf("MigrateDownSafe() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err := tt.args.repo.dbMock.ExpectationsWereMet(); err != nil {
@@ -1109,7 +1113,7 @@ func Test_migrateDown(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			runner := &Runner{}
-			if err := runner.MigrateDown(tt.args.repo.mRepo, tt.args.m); (err != nil) != tt.wantErr {
+			if err := runner.MigrateDown(context.Background(), tt.args.repo.mRepo, tt.args.m); (err != nil) != tt.wantErr {
 				t.Errorf("MigrateDown() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err := tt.args.repo.dbMock.ExpectationsWereMet(); err != nil {
@@ -1118,3 +1122,150 @@ func Test_migrateDown(t *testing.T) {
 		})
 	}
 }
+
+func Test_insertVersion(t *testing.T) {
+	insertVersionSQL := regexp.QuoteMeta("INSERT INTO migration (version, apply_time) VALUES ($1, $2);")
+	saveDownScript := regexp.QuoteMeta("INSERT INTO migration_down (version, statements, use_tx) VALUES ($1, $2, $3)")
+	saveBatch := regexp.QuoteMeta("INSERT INTO migration_batch (version, batch) VALUES ($1, $2)")
+	m := &Migration{
+		Version: "m200101_000001_add_zulul_table",
+		Source:  "testdata/migrations_test/m200101_000001_add_zulul_table.sql",
+	}
+	batched := &Migration{Version: "m200101_000000_test", Source: "m200101_000000_test.go", Batch: 2}
+
+	tests := []struct {
+		name    string
+		m       *Migration
+		expect  func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "down script is saved in the tx of the version",
+			m:    m,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(insertVersionSQL).WithArgs(m.Version, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(saveDownScript).WithArgs(m.Version, sqlmock.AnyArg(), true).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "batch is saved in the tx of the version",
+			m:    batched,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(insertVersionSQL).WithArgs(batched.Version, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(saveBatch).WithArgs(batched.Version, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "version is rolled back if down script is not saved",
+			m:    m,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(insertVersionSQL).WithArgs(m.Version, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(saveDownScript).WillReturnError(errors.New("some error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			dialect, err := sqldialect.InitDialect("postgres", "migration")
+			require.NoError(t, err)
+			tt.expect(mock)
+
+			err = insertVersion(repo.NewMigrationsRepository(db, dialect), tt.m)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_deleteVersion(t *testing.T) {
+	deleteVersionSQL := regexp.QuoteMeta("DELETE FROM migration WHERE version=$1;")
+	deleteDownScript := regexp.QuoteMeta("DELETE FROM migration_down WHERE version=$1;")
+	deleteBatch := regexp.QuoteMeta("DELETE FROM migration_batch WHERE version=$1;")
+	m := &Migration{
+		Version:  "m200101_000000_squashed",
+		Source:   "m200101_000000_squashed.go",
+		Squashed: []string{"m190101_000001_a", "m190101_000002_b"},
+	}
+
+	sqlMigration := &Migration{
+		Version: "m200101_000001_add_zulul_table",
+		Source:  "testdata/migrations_test/m200101_000001_add_zulul_table.sql",
+	}
+
+	tests := []struct {
+		name    string
+		m       *Migration
+		expect  func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "squashed versions are deleted in the tx of the version",
+			m:    m,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteVersionSQL).WithArgs(m.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteVersionSQL).WithArgs(m.Squashed[0]).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteVersionSQL).WithArgs(m.Squashed[1]).WillReturnResult(sqlmock.NewResult(0, 1))
+				for _, v := range append([]string{m.Version}, m.Squashed...) {
+					mock.ExpectExec(deleteBatch).WithArgs(v).WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "version is restored if squashed version is not deleted",
+			m:    m,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteVersionSQL).WithArgs(m.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteVersionSQL).WithArgs(m.Squashed[0]).WillReturnError(errors.New("some error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "version is restored if down script is not deleted",
+			m:    sqlMigration,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteVersionSQL).WithArgs(sqlMigration.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteDownScript).WillReturnError(errors.New("some error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "down script and batch are deleted in the tx of the version",
+			m:    sqlMigration,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteVersionSQL).WithArgs(sqlMigration.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteDownScript).WithArgs(sqlMigration.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteBatch).WithArgs(sqlMigration.Version).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			dialect, err := sqldialect.InitDialect("postgres", "migration")
+			require.NoError(t, err)
+			tt.expect(mock)
+
+			err = deleteVersion(repo.NewMigrationsRepository(db, dialect), tt.m)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
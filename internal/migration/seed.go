@@ -0,0 +1,73 @@
+package migration
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+var registeredSeeds = map[string]*Migration{}
+
+func AddSafeNamedSeed(filename string, up func(*sql.Tx) error, down func(*sql.Tx) error) {
+	v, _ := GetVersionFromFileName(filename)
+	seed := &Migration{Version: v, Next: "", Previous: "", Registered: true, SafeUpFn: up, SafeDownFn: down, Source: filename}
+
+	register(registeredSeeds, seed)
+}
+
+func AddNamedSeed(filename string, up func(*sql.DB) error, down func(*sql.DB) error) {
+	v, _ := GetVersionFromFileName(filename)
+	seed := &Migration{Version: v, Next: "", Previous: "", Registered: true, UpFn: up, DownFn: down, Source: filename}
+
+	register(registeredSeeds, seed)
+}
+
+// SeedsCollector collects seeds from the seeds folder and its environment subfolder,
+// e.g. seeds/*.sql are applied for every environment and seeds/dev/*.sql for dev only.
+type SeedsCollector struct {
+	env string
+	// Parser parses collected SQL seeds, nil uses default one.
+	Parser *SQLParser
+}
+
+func NewSeedsCollector(env string) *SeedsCollector {
+	return &SeedsCollector{env: env}
+}
+
+// CollectMigrations returns seeds of the environment set. Go seeds registered
+// via AddSeed() belong to the set of the folder their source file is placed in.
+func (c *SeedsCollector) CollectMigrations(dirpath string, current, target int) (Migrations, error) {
+	if _, err := os.Stat(dirpath); os.IsNotExist(err) {
+		return nil, errors.Wrap(errors.New("directory does not exist"), dirpath)
+	}
+
+	dirs := []string{dirpath}
+	if c.env != "" {
+		envDir := filepath.Join(dirpath, c.env)
+		if info, err := os.Stat(envDir); err == nil && info.IsDir() {
+			dirs = append(dirs, envDir)
+		}
+	}
+
+	var seeds Migrations
+	for _, dir := range dirs {
+		dirName := filepath.Base(dir)
+		dirSeeds, err := collectFromDir(dir, registeredSeeds, func(m *Migration) bool {
+			return filepath.Base(filepath.Dir(m.Source)) == dirName
+		}, current, target, c.Parser)
+		if err != nil {
+			return nil, err
+		}
+
+		seeds = append(seeds, dirSeeds...)
+	}
+
+	return sortAndConnectMigrations(seeds), nil
+}
+
+// CollectRepeatableMigrations returns nothing, seeds are tracked by version only.
+func (c *SeedsCollector) CollectRepeatableMigrations(_ string) (RepeatableMigrations, error) {
+	return nil, nil
+}
@@ -0,0 +1,70 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedsCollector_CollectMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		dirpath string
+		want    Migrations
+		wantErr bool
+	}{
+		{
+			name:    "common seeds only",
+			env:     "",
+			dirpath: "testdata/seeds_test",
+			want: Migrations{
+				&Migration{
+					Version: "m200101_000000_roles",
+					Source:  "testdata/seeds_test/m200101_000000_roles.sql",
+				},
+			},
+		},
+		{
+			name:    "common and environment seeds",
+			env:     "dev",
+			dirpath: "testdata/seeds_test",
+			want: Migrations{
+				&Migration{
+					Version: "m200101_000000_roles",
+					Next:    "m200101_000001_fake_users",
+					Source:  "testdata/seeds_test/m200101_000000_roles.sql",
+				},
+				&Migration{
+					Version:  "m200101_000001_fake_users",
+					Previous: "m200101_000000_roles",
+					Source:   "testdata/seeds_test/dev/m200101_000001_fake_users.sql",
+				},
+			},
+		},
+		{
+			name:    "environment without seeds dir",
+			env:     "staging",
+			dirpath: "testdata/seeds_test",
+			want: Migrations{
+				&Migration{
+					Version: "m200101_000000_roles",
+					Source:  "testdata/seeds_test/m200101_000000_roles.sql",
+				},
+			},
+		},
+		{
+			name:    "seeds dir not exists",
+			dirpath: "testdata/iamnotexists",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSeedsCollector(tt.env).CollectMigrations(tt.dirpath, 0, 0)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package migration
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Split migrations keep Up and Down statements in separate name.up.sql and name.down.sql files.
+const (
+	splitUpSuffix   = ".up.sql"
+	splitDownSuffix = ".down.sql"
+)
+
+func IsSplitUpFile(path string) bool {
+	return strings.HasSuffix(path, splitUpSuffix)
+}
+
+func IsSplitDownFile(path string) bool {
+	return strings.HasSuffix(path, splitDownSuffix)
+}
+
+// SplitDownFile returns path of .down.sql pair for .up.sql file.
+func SplitDownFile(upPath string) string {
+	return strings.TrimSuffix(upPath, splitUpSuffix) + splitDownSuffix
+}
+
+// SplitUpFile returns path of .up.sql pair for .down.sql file.
+func SplitUpFile(downPath string) string {
+	return strings.TrimSuffix(downPath, splitDownSuffix) + splitUpSuffix
+}
+
+// newSplitMigration returns migration for .up.sql file, missing .down.sql pair makes it irreversible.
+func newSplitMigration(version, upPath string, parser *SQLParser) (*Migration, error) {
+	m := &Migration{Version: version, Source: upPath, parser: parser}
+
+	downPath := SplitDownFile(upPath)
+	if _, err := os.Stat(downPath); err == nil {
+		m.DownSource = downPath
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// parseSplitSQL parses direction specific file of split migration.
+func (m *Migration) parseSplitSQL(direction Direction) ([]string, bool, error) {
+	source := m.Source
+	if direction == DirectionDown {
+		if m.DownSource == "" {
+			return nil, false, errors.Wrapf(ErrIrreversible, "%s has no %s pair", filepath.Base(m.Source), filepath.Base(SplitDownFile(m.Source)))
+		}
+
+		source = m.DownSource
+	}
+
+	content, err := ioutil.ReadFile(source)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to open SQL migration file, err: %v", filepath.Base(source))
+	}
+
+	content, err = stripDirectionAnnotations(content)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to scan SQL migration file: %v", filepath.Base(source))
+	}
+
+	statements, useTx, err := m.parser.parsePlain(bytes.NewReader(content))
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to parse SQL migration file: %v", filepath.Base(source))
+	}
+
+	return statements, useTx, nil
+}
+
+// stripDirectionAnnotations drops optional Up/Down annotations, direction of split file is defined by its name.
+func stripDirectionAnnotations(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, scanBufSize), scanBufSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if cmd := strings.TrimSpace(strings.TrimPrefix(line, "--")); strings.HasPrefix(line, "--") &&
+			(cmd == "+gomigrate Up" || cmd == "+gomigrate Down") {
+			continue
+		}
+
+		buf.WriteString(line + "\n")
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectMigrations_Split(t *testing.T) {
+	c := &MigrationsCollector{}
+
+	got, err := c.CollectMigrations("testdata/split_test", 0, 300101000000)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	require.Equal(t, "m200101_000000_create_users", got[0].Version)
+	require.Equal(t, "testdata/split_test/m200101_000000_create_users.up.sql", got[0].Source)
+	require.Equal(t, "testdata/split_test/m200101_000000_create_users.down.sql", got[0].DownSource)
+
+	require.Equal(t, "m200101_000001_add_users_name_index", got[1].Version)
+	require.Empty(t, got[1].DownSource)
+
+	_, err = c.CollectMigrations("testdata/split_test/orphan", 0, 300101000000)
+	require.Error(t, err)
+}
+
+func TestMigration_parseSplitSQL(t *testing.T) {
+	users := &Migration{
+		Version:    "m200101_000000_create_users",
+		Source:     "testdata/split_test/m200101_000000_create_users.up.sql",
+		DownSource: "testdata/split_test/m200101_000000_create_users.down.sql",
+	}
+	index := &Migration{
+		Version: "m200101_000001_add_users_name_index",
+		Source:  "testdata/split_test/m200101_000001_add_users_name_index.up.sql",
+	}
+
+	tests := []struct {
+		name      string
+		m         *Migration
+		direction Direction
+		want      []string
+		wantTx    bool
+		wantErr   error
+	}{
+		{
+			name:      "up without annotations",
+			m:         users,
+			direction: DirectionUp,
+			want: []string{
+				"CREATE TABLE users (\n    id   serial PRIMARY KEY,\n    name text NOT NULL\n);\n",
+				"INSERT INTO users (name) VALUES ('admin; root');\n",
+			},
+			wantTx: true,
+		},
+		{
+			name:      "down with optional annotation",
+			m:         users,
+			direction: DirectionDown,
+			want:      []string{"DROP TABLE users;\n"},
+			wantTx:    true,
+		},
+		{
+			name:      "no transaction",
+			m:         index,
+			direction: DirectionUp,
+			want:      []string{"CREATE INDEX CONCURRENTLY users_name_idx ON users (name);\n"},
+			wantTx:    false,
+		},
+		{
+			name:      "missing down file",
+			m:         index,
+			direction: DirectionDown,
+			wantErr:   ErrIrreversible,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, useTx, err := tt.m.parseSQL(tt.direction)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantTx, useTx)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,18 +1,45 @@
 package migration
 
 import (
+	"context"
 	"database/sql"
+	"io"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/tracing"
 )
 
+// statementFunc is called after each statement of SQL migration is executed, nil is allowed.
+type statementFunc func(statement string, duration time.Duration, err error)
+
+func (f statementFunc) executed(statement string, start time.Time, err error) {
+	if f != nil {
+		f(clearStatement(statement), time.Since(start), err)
+	}
+}
+
+// startStatementSpan starts tracing span of SQL statement, literals are not traced.
+func startStatementSpan(ctx context.Context, statement string) (context.Context, *tracing.Span) {
+	return tracing.Start(ctx, "gomigrate.statement", tracing.StatementKey.String(tracing.Sanitize(clearStatement(statement))))
+}
+
+// debugStatement logs executed statements of hooks and repeatable migrations, which are not observed.
+func debugStatement(statement string, duration time.Duration, _ error) {
+	log.Debugf("Executed SQL statement: %s (time: %.3f sec.)\n", statement, duration.Seconds())
+}
+
 // dont know how to test this :).
-func assembleSafeFnFromStatements(statements []string) func(tx *sql.Tx) error {
-	return func(tx *sql.Tx) error {
+func assembleSafeFnFromStatements(statements []string, onExec statementFunc) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
 		for i := range statements {
-			log.Debugf("Executing SQL statement: %s\n", clearStatement(statements[i]))
-			if _, err := tx.Exec(statements[i]); err != nil {
+			start := time.Now()
+			spanCtx, span := startStatementSpan(ctx, statements[i])
+			_, err := tx.ExecContext(spanCtx, statements[i])
+			span.End(err)
+			onExec.executed(statements[i], start, err)
+			if err != nil {
 				log.Err("Rollback transaction")
 				txErr := tx.Rollback()
 				if txErr != nil {
@@ -28,11 +55,15 @@ func assembleSafeFnFromStatements(statements []string) func(tx *sql.Tx) error {
 }
 
 // dont know how to test this :).
-func assembleFnFromStatements(statements []string) func(db *sql.DB) error {
-	return func(db *sql.DB) error {
+func assembleFnFromStatements(statements []string, onExec statementFunc) func(ctx context.Context, db *sql.DB) error {
+	return func(ctx context.Context, db *sql.DB) error {
 		for i := range statements {
-			log.Debugf("Executing SQL statement: %s\n", clearStatement(statements[i]))
-			if _, err := db.Exec(statements[i]); err != nil {
+			start := time.Now()
+			spanCtx, span := startStatementSpan(ctx, statements[i])
+			_, err := db.ExecContext(spanCtx, statements[i])
+			span.End(err)
+			onExec.executed(statements[i], start, err)
+			if err != nil {
 				return errors.Wrapf(err, "failed to execute SQL query %q", clearStatement(statements[i]))
 			}
 		}
@@ -40,3 +71,59 @@ func assembleFnFromStatements(statements []string) func(db *sql.DB) error {
 		return nil
 	}
 }
+
+// assembleHookFnFromStatements returns SQL hook running statements on hook executor.
+func assembleHookFnFromStatements(statements []string) HookFn {
+	return func(ctx context.Context, ex HookExecutor, _ *HookEvent) error {
+		for i := range statements {
+			start := time.Now()
+			spanCtx, span := startStatementSpan(ctx, statements[i])
+			_, err := ex.ExecContext(spanCtx, statements[i])
+			span.End(err)
+			debugStatement(clearStatement(statements[i]), time.Since(start), err)
+			if err != nil {
+				return errors.Wrapf(err, "failed to execute SQL query %q", clearStatement(statements[i]))
+			}
+		}
+
+		return nil
+	}
+}
+
+// SQLParser splits SQL migrations into statements and expands their placeholders. Each service has
+// its own parser, so services with different configs may be used in one process.
+type SQLParser struct {
+	rules        splitRules
+	substitution *substitution
+}
+
+// NewSQLParser returns parser splitting statements with quoting rules of the dialect and replacing
+// ${var} with vars value or environment variable and {{%name}} with tablePrefix + name
+// in files annotated with +gomigrate Substitute.
+func NewSQLParser(dialect string, vars map[string]string, tablePrefix string) *SQLParser {
+	return &SQLParser{
+		rules:        splitRulesOf(dialect),
+		substitution: &substitution{vars: vars, tablePrefix: tablePrefix},
+	}
+}
+
+// defaultSQLParser is used instead of nil parser, it uses postgres rules and expands environment variables only.
+var defaultSQLParser = NewSQLParser("postgres", nil, "")
+
+func (p *SQLParser) orDefault() *SQLParser {
+	if p == nil {
+		return defaultSQLParser
+	}
+
+	return p
+}
+
+// ParseStatements splits plain SQL script (without Up/Down annotations) into statements.
+func (p *SQLParser) ParseStatements(r io.Reader) ([]string, error) {
+	stmts, _, err := p.parsePlain(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return stmts, nil
+}
@@ -49,6 +49,9 @@ func (s *stateMachine) Set(new parserState) {
 
 const scanBufSize = 4 * 1024 * 1024
 
+// lintIgnoreDirective comments like '-- gomigrate:ignore drop-table' suppress lint rules for the next statement.
+const lintIgnoreDirective = "gomigrate:ignore"
+
 var matchEmptyLines = regexp.MustCompile(`^\s*$`)
 
 var bufferPool = &sync.Pool{
@@ -60,15 +63,17 @@ var bufferPool = &sync.Pool{
 // Split given SQL script into individual statements and return
 // SQL statements for given direction (up=true, down=false).
 //
-// The base case is to simply split on semicolons, as these
-// naturally terminate a statement.
+// Statements are split on semicolons which are not inside quoted strings,
+// identifiers, dollar quotes or comments, see sqlSplitter.
 //
-// However, more complex cases like pl/pgsql can have semicolons
-// within a statement. For these cases, we provide the explicit annotations
+// Some statements can not be split this way, e.g. COPY FROM stdin data
+// or MySQL DELIMITER. For these cases, we provide the explicit annotations
 // 'StatementBegin' and 'StatementEnd' to allow the script to
 // tell us to ignore semicolons.
 //nolint
-func parseSQLMigration(r io.Reader, direction Direction) (stmts []string, useTx bool, err error) {
+func (p *SQLParser) parse(r io.Reader, direction Direction) (stmts []string, useTx bool, err error) {
+	p = p.orDefault()
+
 	var buf bytes.Buffer
 	scanBuf := bufferPool.Get().([]byte)
 	defer bufferPool.Put(scanBuf)
@@ -78,11 +83,15 @@ func parseSQLMigration(r io.Reader, direction Direction) (stmts []string, useTx
 
 	stateMachine := stateMachine(start)
 	useTx = true
+	substitute := false
+	irreversible := false
+	splitter := newSQLSplitter(p.rules)
 
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		if strings.HasPrefix(line, "--") {
+		// annotations are recognized only outside of quoted strings and comments
+		if strings.HasPrefix(line, "--") && splitter.inCode() {
 			cmd := strings.TrimSpace(strings.TrimPrefix(line, "--"))
 
 			switch cmd {
@@ -98,6 +107,10 @@ func parseSQLMigration(r io.Reader, direction Direction) (stmts []string, useTx
 			case "+gomigrate Down":
 				switch stateMachine.Get() {
 				case gomigrateUp, gomigrateStatementEndUp:
+					if pending := splitter.pending(); pending != "" {
+						return nil, false, errors.Errorf("failed to parse migration: unexpected unfinished SQL query before '-- +gomigrate Down': %q: missing semicolon?", pending)
+					}
+					splitter.reset()
 					stateMachine.Set(gomigrateDown)
 				default:
 					return nil, false, errors.Errorf("must start with '-- +gomigrate Up' annotation, stateMachine=%v", stateMachine)
@@ -105,6 +118,11 @@ func parseSQLMigration(r io.Reader, direction Direction) (stmts []string, useTx
 				continue
 
 			case "+gomigrate StatementBegin":
+				if pending := splitter.pending(); pending != "" {
+					return nil, false, errors.Errorf("failed to parse migration: unexpected unfinished SQL query before '-- +gomigrate StatementBegin': %q: missing semicolon?", pending)
+				}
+				splitter.reset()
+
 				switch stateMachine.Get() {
 				case gomigrateUp, gomigrateStatementEndUp:
 					stateMachine.Set(gomigrateStatementBeginUp)
@@ -118,80 +136,74 @@ func parseSQLMigration(r io.Reader, direction Direction) (stmts []string, useTx
 			case "+gomigrate StatementEnd":
 				switch stateMachine.Get() {
 				case gomigrateStatementBeginUp:
-					stateMachine.Set(gomigrateStatementEndUp)
+					if direction == DirectionUp {
+						buf.WriteString(line + "\n")
+						stmts = append(stmts, buf.String())
+						log.Debugf("StateMachine: store Up statement")
+					}
+					buf.Reset()
+					stateMachine.Set(gomigrateUp)
 				case gomigrateStatementBeginDown:
-					stateMachine.Set(gomigrateStatementEndDown)
+					if direction == DirectionDown {
+						buf.WriteString(line + "\n")
+						stmts = append(stmts, buf.String())
+						log.Debugf("StateMachine: store Down statement")
+					}
+					buf.Reset()
+					stateMachine.Set(gomigrateDown)
 				default:
 					return nil, false, errors.New("'-- +gomigrate StatementEnd' must be defined after '-- +gomigrate StatementBegin'")
 				}
+				continue
 
 			case "+gomigrate NO TRANSACTION":
 				useTx = false
 				continue
 
-			default:
-				// Ignore comments.
-				log.Debugf("StateMachine: ignore comment")
+			case substituteAnnotation:
+				substitute = true
+				continue
+
+			case irreversibleAnnotation:
+				irreversible = true
 				continue
+
+			default:
+				// Ignore comments, lint directives are kept with the statement they precede.
+				if !strings.HasPrefix(cmd, lintIgnoreDirective) || stateMachine.Get() == start {
+					log.Debugf("StateMachine: ignore comment")
+					continue
+				}
 			}
 		}
 
-		// Ignore empty lines.
-		if matchEmptyLines.MatchString(line) {
+		// Ignore empty lines, they are kept inside quoted strings and comments only.
+		if splitter.inCode() && matchEmptyLines.MatchString(line) {
 			log.Debugf("StateMachine: ignore empty line")
 			continue
 		}
 
-		// Write SQL line to a buffer.
-		if _, err := buf.WriteString(line + "\n"); err != nil {
-			return nil, false, errors.Wrap(err, "failed to write to buf")
-		}
-
-		// Read SQL body one by line, if we're in the right direction.
-		//
-		// 1) basic query with semicolon; 2) psql statement
-		//
-		// Export statement once we hit end of statement.
-		switch stateMachine.Get() {
-		case gomigrateUp, gomigrateStatementBeginUp, gomigrateStatementEndUp:
-			if direction == migrationDirectionDown {
-				buf.Reset()
-				log.Debugf("StateMachine: ignore down")
-				continue
-			}
-		case gomigrateDown, gomigrateStatementBeginDown, gomigrateStatementEndDown:
-			if direction == migrationDirectionUp {
-				buf.Reset()
-				log.Debugf("StateMachine: ignore up")
-				continue
-			}
-		default:
-			return nil, false, errors.Errorf("failed to parse migration: unexpected state %q on line %q", stateMachine, line)
-		}
-
 		switch stateMachine.Get() {
 		case gomigrateUp:
-			if endsWithSemicolon(line) {
-				stmts = append(stmts, buf.String())
-				buf.Reset()
-				log.Debugf("StateMachine: store simple Up query")
+			lineStmts := splitter.feed(line)
+			if direction == DirectionUp {
+				stmts = append(stmts, lineStmts...)
 			}
 		case gomigrateDown:
-			if endsWithSemicolon(line) {
-				stmts = append(stmts, buf.String())
-				buf.Reset()
-				log.Debugf("StateMachine: store simple Down query")
+			lineStmts := splitter.feed(line)
+			if direction == DirectionDown {
+				stmts = append(stmts, lineStmts...)
+			}
+		case gomigrateStatementBeginUp:
+			if direction == DirectionUp {
+				buf.WriteString(line + "\n")
 			}
-		case gomigrateStatementEndUp:
-			stmts = append(stmts, buf.String())
-			buf.Reset()
-			log.Debugf("StateMachine: store Up statement")
-			stateMachine.Set(gomigrateUp)
-		case gomigrateStatementEndDown:
-			stmts = append(stmts, buf.String())
-			buf.Reset()
-			log.Debugf("StateMachine: store Down statement")
-			stateMachine.Set(gomigrateDown)
+		case gomigrateStatementBeginDown:
+			if direction == DirectionDown {
+				buf.WriteString(line + "\n")
+			}
+		default:
+			return nil, false, errors.Errorf("failed to parse migration: unexpected state %q on line %q", stateMachine, line)
 		}
 	}
 	if err := scanner.Err(); err != nil {
@@ -206,31 +218,23 @@ func parseSQLMigration(r io.Reader, direction Direction) (stmts []string, useTx
 		return nil, false, errors.New("failed to parse migration: missing '-- +gomigrate StatementEnd' annotation")
 	}
 
-	if bufferRemaining := strings.TrimSpace(buf.String()); len(bufferRemaining) > 0 {
-		return nil, false, errors.Errorf("failed to parse migration: state %q, direction: %v: unexpected unfinished SQL query: %q: missing semicolon?", stateMachine, direction, bufferRemaining)
+	if !splitter.inCode() {
+		return nil, false, errors.New("failed to parse migration: unterminated quoted string or comment")
 	}
 
-	return stmts, useTx, nil
-}
-
-// Checks the line to see if the line has a statement-ending semicolon
-// or if the line contains a double-dash comment.
-func endsWithSemicolon(line string) bool {
-	scanBuf := bufferPool.Get().([]byte)
-	defer bufferPool.Put(scanBuf)
+	if bufferRemaining := splitter.pending(); len(bufferRemaining) > 0 {
+		return nil, false, errors.Errorf("failed to parse migration: state %q, direction: %v: unexpected unfinished SQL query: %q: missing semicolon?", stateMachine, direction, bufferRemaining)
+	}
 
-	prev := ""
-	scanner := bufio.NewScanner(strings.NewReader(line))
-	scanner.Buffer(scanBuf, scanBufSize)
-	scanner.Split(bufio.ScanWords)
+	if irreversible && direction == DirectionDown {
+		return nil, false, ErrIrreversible
+	}
 
-	for scanner.Scan() {
-		word := scanner.Text()
-		if strings.HasPrefix(word, "--") {
-			break
+	if substitute {
+		if stmts, err = p.substitution.expand(stmts); err != nil {
+			return nil, false, errors.Wrap(err, "failed to expand placeholders")
 		}
-		prev = word
 	}
 
-	return strings.HasSuffix(prev, ";")
+	return stmts, useTx, nil
 }
@@ -5,33 +5,73 @@ import (
 	"testing"
 
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
 )
 
-func TestSemicolons(t *testing.T) {
+func TestSQLSplitter(t *testing.T) {
 	t.Parallel()
 
-	type testData struct {
-		line   string
-		result bool
+	tests := []struct {
+		name    string
+		rules   splitRules
+		lines   []string
+		want    []string
+		pending string
+	}{
+		{name: "statement", rules: postgresSplitRules, lines: []string{"END;"}, want: []string{"END;\n"}},
+		{name: "trailing comment", rules: postgresSplitRules, lines: []string{"END   ; -- comment"}, want: []string{"END   ; -- comment\n"}},
+		{name: "semicolon in comment", rules: postgresSplitRules, lines: []string{"END -- comment ;"}, pending: "END -- comment ;"},
+		{name: "semicolon in identifier", rules: postgresSplitRules, lines: []string{`END " ; " -- comment`}, pending: `END " ; " -- comment`},
+		{name: "several statements on line", rules: postgresSplitRules, lines: []string{"SELECT 1; SELECT 2;"}, want: []string{"SELECT 1;\n", "SELECT 2;\n"}},
+		{name: "semicolon in literal", rules: postgresSplitRules, lines: []string{"SELECT 'a;', 'it''s;';"}, want: []string{"SELECT 'a;', 'it''s;';\n"}},
+		{name: "escape string", rules: postgresSplitRules, lines: []string{`SELECT E'\';', 1;`}, want: []string{`SELECT E'\';', 1;` + "\n"}},
+		{name: "multiline literal", rules: postgresSplitRules, lines: []string{"INSERT INTO t VALUES ('a;", "", "b');"}, want: []string{"INSERT INTO t VALUES ('a;\n\nb');\n"}},
+		{name: "nested block comment", rules: postgresSplitRules, lines: []string{"/* a /* b; */ c; */ SELECT 1;"}, want: []string{"/* a /* b; */ c; */ SELECT 1;\n"}},
+		{
+			name:  "dollar quotes",
+			rules: postgresSplitRules,
+			lines: []string{"CREATE FUNCTION f() RETURNS int AS $$", "BEGIN", "  RETURN 1;", "END;", "$$ LANGUAGE plpgsql;"},
+			want:  []string{"CREATE FUNCTION f() RETURNS int AS $$\nBEGIN\n  RETURN 1;\nEND;\n$$ LANGUAGE plpgsql;\n"},
+		},
+		{name: "tagged dollar quotes", rules: postgresSplitRules, lines: []string{"SELECT $fn$ $$; $fn$;"}, want: []string{"SELECT $fn$ $$; $fn$;\n"}},
+		{name: "positional params", rules: postgresSplitRules, lines: []string{"PREPARE p AS SELECT $1; SELECT 2;"}, want: []string{"PREPARE p AS SELECT $1;\n", "SELECT 2;\n"}},
+		{name: "mysql backslash escape", rules: mysqlSplitRules, lines: []string{`SELECT 'it\'s;';`}, want: []string{`SELECT 'it\'s;';` + "\n"}},
+		{name: "mysql backticks", rules: mysqlSplitRules, lines: []string{"SELECT `a;b` FROM t;"}, want: []string{"SELECT `a;b` FROM t;\n"}},
+		{name: "mysql hash comment", rules: mysqlSplitRules, lines: []string{"SELECT 1 # comment;"}, pending: "SELECT 1 # comment;"},
+		{name: "mysql dollar is not a quote", rules: mysqlSplitRules, lines: []string{"SELECT $$; SELECT 1;"}, want: []string{"SELECT $$;\n", "SELECT 1;\n"}},
 	}
 
-	tests := []testData{
-		{line: "END;", result: true},
-		{line: "END; -- comment", result: true},
-		{line: "END   ; -- comment", result: true},
-		{line: "END -- comment", result: false},
-		{line: "END -- comment ;", result: false},
-		{line: "END \" ; \" -- comment", result: false},
-	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 
-	for _, test := range tests {
-		r := endsWithSemicolon(test.line)
-		if r != test.result {
-			t.Errorf("incorrect semicolon. got %v, want %v", r, test.result)
-		}
+			s := newSQLSplitter(tt.rules)
+			var got []string
+			for _, line := range tt.lines {
+				got = append(got, s.feed(line)...)
+			}
+
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.pending, s.pending())
+		})
 	}
 }
 
+func TestSQLParser_DialectRules(t *testing.T) {
+	t.Parallel()
+
+	sql := "-- +gomigrate Up\nSELECT `a;b` FROM t;\n"
+
+	mysql, _, err := NewSQLParser("mysql", nil, "").parse(strings.NewReader(sql), DirectionUp)
+	require.NoError(t, err)
+	require.Len(t, mysql, 1)
+
+	postgres, _, err := NewSQLParser("postgres", nil, "").parse(strings.NewReader(sql), DirectionUp)
+	require.NoError(t, err)
+	require.Len(t, postgres, 2)
+}
+
 func TestSplitStatements(t *testing.T) {
 	t.Parallel()
 
@@ -42,7 +82,7 @@ func TestSplitStatements(t *testing.T) {
 	}
 
 	tt := []testData{
-		{sql: multilineSQL, up: 4, down: 1},
+		{sql: multilineSQL, up: 5, down: 1},
 		{sql: emptySQL, up: 0, down: 0},
 		{sql: emptySQL2, up: 0, down: 0},
 		{sql: functxt, up: 2, down: 2},
@@ -54,7 +94,7 @@ func TestSplitStatements(t *testing.T) {
 
 	for i, test := range tt {
 		// up
-		stmts, _, err := parseSQLMigration(strings.NewReader(test.sql), migrationDirectionUp)
+		stmts, _, err := defaultSQLParser.parse(strings.NewReader(test.sql), DirectionUp)
 		if err != nil {
 			t.Error(errors.Wrapf(err, "tt[%v] unexpected error", i))
 		}
@@ -63,7 +103,7 @@ func TestSplitStatements(t *testing.T) {
 		}
 
 		// down
-		stmts, _, err = parseSQLMigration(strings.NewReader(test.sql), migrationDirectionDown)
+		stmts, _, err = defaultSQLParser.parse(strings.NewReader(test.sql), DirectionDown)
 		if err != nil {
 			t.Error(errors.Wrapf(err, "tt[%v] unexpected error", i))
 		}
@@ -84,15 +124,29 @@ func TestParsingErrors(t *testing.T) {
 		noUpDownAnnotations,
 		multiUpDown,
 		downFirst,
+		unterminatedQuote,
+		unfinishedBeforeDown,
 	}
 	for i, sql := range tt {
-		_, _, err := parseSQLMigration(strings.NewReader(sql), migrationDirectionUp)
+		_, _, err := defaultSQLParser.parse(strings.NewReader(sql), DirectionUp)
 		if err == nil {
 			t.Errorf("expected error on tt[%v] %q", i, sql)
 		}
 	}
 }
 
+var unterminatedQuote = `-- +gomigrate Up
+INSERT INTO post (title) VALUES ('unterminated;
+-- +gomigrate Down
+DELETE FROM post;
+`
+
+var unfinishedBeforeDown = `-- +gomigrate Up
+SELECT 1
+-- +gomigrate Down
+SELECT 2;
+`
+
 var multilineSQL = `-- +gomigrate Up
 CREATE TABLE post (
 		id int NOT NULL,
@@ -387,3 +441,23 @@ ALTER TABLE some_table ALTER COLUMN password TYPE VARCHAR(50);
 		})
 	}
 }
+
+func TestParseSQLMigration_LintIgnoreDirective(t *testing.T) {
+	sql := `-- +gomigrate Up
+-- gomigrate:ignore drop-table
+DROP TABLE users;
+-- plain comment
+DROP TABLE posts;
+-- gomigrate:ignore drop-table
+
+-- +gomigrate Down
+CREATE TABLE users (id int);
+`
+	stmts, _, err := defaultSQLParser.parse(strings.NewReader(sql), DirectionUp)
+	require.NoError(t, err)
+	require.Equal(t, []string{"-- gomigrate:ignore drop-table\nDROP TABLE users;\n", "DROP TABLE posts;\n"}, stmts)
+
+	stmts, _, err = defaultSQLParser.parse(strings.NewReader(sql), DirectionDown)
+	require.NoError(t, err)
+	require.Equal(t, []string{"CREATE TABLE users (id int);\n"}, stmts)
+}
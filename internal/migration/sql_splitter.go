@@ -0,0 +1,234 @@
+package migration
+
+import (
+	"strings"
+)
+
+// splitRules describe dialect quoting rules which affect statements splitting.
+type splitRules struct {
+	// dollarQuotes enables $$...$$ and $tag$...$tag$ strings.
+	dollarQuotes bool
+	// escapeStrings enables E'...' strings with backslash escapes.
+	escapeStrings bool
+	// backslashEscapes enables backslash escapes in all quoted strings.
+	backslashEscapes bool
+	// backticks enables `...` quoted identifiers.
+	backticks bool
+	// hashComments enables # line comments.
+	hashComments bool
+	// nestedComments enables nested /* /* */ */ block comments.
+	nestedComments bool
+}
+
+var (
+	postgresSplitRules = splitRules{dollarQuotes: true, escapeStrings: true, nestedComments: true}
+	mysqlSplitRules    = splitRules{backslashEscapes: true, backticks: true, hashComments: true}
+)
+
+var dialectSplitRules = map[string]splitRules{
+	"postgres": postgresSplitRules,
+	"mysql":    mysqlSplitRules,
+}
+
+// splitRulesOf returns quoting rules of the dialect, unknown dialects fall back to postgres rules.
+func splitRulesOf(dialect string) splitRules {
+	rules, ok := dialectSplitRules[dialect]
+	if !ok {
+		return postgresSplitRules
+	}
+
+	return rules
+}
+
+type splitterState int
+
+const (
+	splitterTop splitterState = iota
+	splitterLineComment
+	splitterBlockComment
+	splitterSingleQuote
+	splitterEscapeString
+	splitterDoubleQuote
+	splitterBacktick
+	splitterDollarQuote
+)
+
+// sqlSplitter splits SQL script fed line by line into statements on semicolons
+// which are not inside quoted strings, identifiers or comments.
+type sqlSplitter struct {
+	rules        splitRules
+	state        splitterState
+	commentDepth int
+	dollarTag    string
+	buf          strings.Builder
+}
+
+func newSQLSplitter(rules splitRules) *sqlSplitter {
+	return &sqlSplitter{rules: rules}
+}
+
+// inCode reports whether splitter is outside of quoted strings and comments,
+// so the next line may be an annotation.
+func (s *sqlSplitter) inCode() bool {
+	return s.state == splitterTop
+}
+
+// pending returns the text of unfinished statement, comments waiting for the next statement are not counted.
+func (s *sqlSplitter) pending() string {
+	text := strings.TrimSpace(s.buf.String())
+	for _, line := range strings.Split(text, "\n") {
+		if !s.isBlankOrComment(line) {
+			return text
+		}
+	}
+
+	return ""
+}
+
+func (s *sqlSplitter) reset() {
+	s.buf.Reset()
+}
+
+// feed consumes the line and returns statements finished on it. Line comments
+// following the last statement on the line are kept with it.
+func (s *sqlSplitter) feed(line string) []string {
+	text := line + "\n"
+
+	var (
+		stmts []string
+		start int
+	)
+
+	for i := 0; i < len(text); i++ {
+		switch s.state {
+		case splitterTop:
+			if text[i] == ';' {
+				s.buf.WriteString(text[start : i+1])
+				stmts = append(stmts, s.buf.String())
+				s.buf.Reset()
+				start = i + 1
+
+				continue
+			}
+
+			i = s.enter(text, i)
+		case splitterLineComment:
+			if text[i] == '\n' {
+				s.state = splitterTop
+			}
+		case splitterBlockComment:
+			switch {
+			case strings.HasPrefix(text[i:], "*/"):
+				s.commentDepth--
+				if s.commentDepth == 0 {
+					s.state = splitterTop
+				}
+				i++
+			case s.rules.nestedComments && strings.HasPrefix(text[i:], "/*"):
+				s.commentDepth++
+				i++
+			}
+		case splitterSingleQuote:
+			i = s.skipQuoted(text, i, '\'', s.rules.backslashEscapes)
+		case splitterEscapeString:
+			i = s.skipQuoted(text, i, '\'', true)
+		case splitterDoubleQuote:
+			i = s.skipQuoted(text, i, '"', s.rules.backslashEscapes)
+		case splitterBacktick:
+			i = s.skipQuoted(text, i, '`', false)
+		case splitterDollarQuote:
+			if strings.HasPrefix(text[i:], s.dollarTag) {
+				i += len(s.dollarTag) - 1
+				s.state = splitterTop
+			}
+		}
+	}
+
+	rest := text[start:]
+	if len(stmts) > 0 && s.state == splitterTop && s.isBlankOrComment(rest) {
+		stmts[len(stmts)-1] += strings.TrimRight(rest, " \t\n")
+	} else {
+		s.buf.WriteString(rest)
+	}
+
+	for i := range stmts {
+		stmts[i] = strings.TrimSpace(stmts[i]) + "\n"
+	}
+
+	return stmts
+}
+
+// enter switches state if quoted string or comment starts at text[i], returns index of the last consumed char.
+func (s *sqlSplitter) enter(text string, i int) int {
+	switch c := text[i]; {
+	case strings.HasPrefix(text[i:], "--"), s.rules.hashComments && c == '#':
+		s.state = splitterLineComment
+	case strings.HasPrefix(text[i:], "/*"):
+		s.state = splitterBlockComment
+		s.commentDepth = 1
+
+		return i + 1
+	case c == '\'':
+		s.state = splitterSingleQuote
+		if s.rules.escapeStrings && i > 0 && (text[i-1] == 'E' || text[i-1] == 'e') && (i == 1 || !isIdentChar(text[i-2])) {
+			s.state = splitterEscapeString
+		}
+	case c == '"':
+		s.state = splitterDoubleQuote
+	case c == '`' && s.rules.backticks:
+		s.state = splitterBacktick
+	case c == '$' && s.rules.dollarQuotes && (i == 0 || !isIdentChar(text[i-1])):
+		if tag := dollarTag(text[i:]); tag != "" {
+			s.state = splitterDollarQuote
+			s.dollarTag = tag
+
+			return i + len(tag) - 1
+		}
+	}
+
+	return i
+}
+
+// skipQuoted handles char inside quoted text, doubled quote is an escaped one.
+func (s *sqlSplitter) skipQuoted(text string, i int, quote byte, backslash bool) int {
+	switch text[i] {
+	case '\\':
+		if backslash {
+			return i + 1
+		}
+	case quote:
+		if i+1 < len(text) && text[i+1] == quote {
+			return i + 1
+		}
+
+		s.state = splitterTop
+	}
+
+	return i
+}
+
+func (s *sqlSplitter) isBlankOrComment(text string) bool {
+	text = strings.TrimSpace(text)
+
+	return text == "" || strings.HasPrefix(text, "--") || (s.rules.hashComments && strings.HasPrefix(text, "#"))
+}
+
+// dollarTag returns $tag$ opening text or empty string, positional params like $1 are not tags.
+func dollarTag(text string) string {
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '$':
+			return text[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || (i > 1 && c >= '0' && c <= '9'):
+		default:
+			return ""
+		}
+	}
+
+	return ""
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '$'
+}
@@ -0,0 +1,48 @@
+package migration
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// squashedAnnotation marks baseline migration constituents, one version per line:
+//
+//	-- +gomigrate Squashed m200101_000000_add_accounts_table
+const squashedAnnotation = "+gomigrate Squashed"
+
+// ReadSquashedVersions returns versions squashed into the baseline SQL migration file.
+func ReadSquashedVersions(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open SQL migration file: %s", path)
+	}
+	defer f.Close()
+
+	var versions []string
+
+	scanBuf := bufferPool.Get().([]byte)
+	defer bufferPool.Put(scanBuf)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(scanBuf, scanBufSize)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "--") {
+			continue
+		}
+
+		cmd := strings.TrimSpace(strings.TrimPrefix(line, "--"))
+		if strings.HasPrefix(cmd, squashedAnnotation+" ") {
+			versions = append(versions, strings.TrimSpace(strings.TrimPrefix(cmd, squashedAnnotation)))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to scan migration")
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,39 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSquashedVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "baseline",
+			path: "testdata/squash_test/m200101_000001_baseline.sql",
+			want: []string{"m200101_000000_test", "m200101_000001_test1"},
+		},
+		{
+			name: "regular migration",
+			path: "testdata/migrations_test/m200101_000001_add_zulul_table.sql",
+			want: nil,
+		},
+		{
+			name:    "file not exists",
+			path:    "testdata/squash_test/not_exists.sql",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadSquashedVersions(tt.path)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package migration
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/log"
+)
+
+// substituteAnnotation enables placeholders expansion for the file,
+// so files without it may freely use $1, $$ and {{ }} in statements.
+const substituteAnnotation = "+gomigrate Substitute"
+
+var ErrUndefinedVariable = errors.New("undefined variable")
+
+var (
+	matchVariable    = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	matchPrefixTable = regexp.MustCompile(`\{\{%([A-Za-z0-9_]+)\}\}`)
+)
+
+type substitution struct {
+	vars        map[string]string
+	tablePrefix string
+}
+
+func (s *substitution) lookup(name string) (string, bool) {
+	if v, ok := s.vars[name]; ok {
+		return v, true
+	}
+
+	return os.LookupEnv(name)
+}
+
+func (s *substitution) expand(statements []string) ([]string, error) {
+	res := make([]string, 0, len(statements))
+	for _, stmt := range statements {
+		var undefined string
+		expanded := matchVariable.ReplaceAllStringFunc(stmt, func(m string) string {
+			name := matchVariable.FindStringSubmatch(m)[1]
+			v, ok := s.lookup(name)
+			if !ok && undefined == "" {
+				undefined = name
+			}
+
+			return v
+		})
+		if undefined != "" {
+			return nil, errors.Wrap(ErrUndefinedVariable, undefined)
+		}
+
+		expanded = s.expandPrefixes(expanded)
+
+		log.Debugf("Expanded SQL statement: %s\n", clearStatement(expanded))
+		res = append(res, expanded)
+	}
+
+	return res, nil
+}
+
+// expandPrefixes replaces {{%name}} with tablePrefix + name.
+func (s *substitution) expandPrefixes(text string) string {
+	return matchPrefixTable.ReplaceAllStringFunc(text, func(m string) string {
+		return s.tablePrefix + matchPrefixTable.FindStringSubmatch(m)[1]
+	})
+}
+
+// declaresSubstitution reports whether SQL script has +gomigrate Substitute annotation.
+func declaresSubstitution(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "--") && strings.TrimSpace(strings.TrimPrefix(line, "--")) == substituteAnnotation {
+			return true
+		}
+	}
+
+	return false
+}
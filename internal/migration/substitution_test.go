@@ -0,0 +1,89 @@
+package migration
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSQLMigration_Substitution(t *testing.T) {
+	parser := NewSQLParser("postgres", map[string]string{"schema": "tenant_1", "owner": "app"}, "tbl_")
+
+	os.Setenv("GOMIGRATE_TEST_TABLESPACE", "fast_ssd")
+	defer os.Unsetenv("GOMIGRATE_TEST_TABLESPACE")
+
+	tests := []struct {
+		name    string
+		sql     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "substitution off by default",
+			sql: `-- +gomigrate Up
+CREATE TABLE ${schema}.{{%users}} (id int);
+PREPARE get_user AS SELECT * FROM users WHERE id = $1;
+`,
+			want: []string{
+				"CREATE TABLE ${schema}.{{%users}} (id int);\n",
+				"PREPARE get_user AS SELECT * FROM users WHERE id = $1;\n",
+			},
+		},
+		{
+			name: "vars, env and table prefix expanded",
+			sql: `-- +gomigrate Substitute
+-- +gomigrate Up
+CREATE TABLE ${schema}.{{%users}} (id int) TABLESPACE ${GOMIGRATE_TEST_TABLESPACE};
+ALTER TABLE ${schema}.{{%users}} OWNER TO ${owner};
+`,
+			want: []string{
+				"CREATE TABLE tenant_1.tbl_users (id int) TABLESPACE fast_ssd;\n",
+				"ALTER TABLE tenant_1.tbl_users OWNER TO app;\n",
+			},
+		},
+		{
+			name: "dollar quotes and params kept",
+			sql: `-- +gomigrate Up
+-- +gomigrate Substitute
+-- +gomigrate StatementBegin
+CREATE FUNCTION ${schema}.inc(i int) RETURNS int AS $$ SELECT $1 + 1; $$ LANGUAGE sql;
+-- +gomigrate StatementEnd
+`,
+			want: []string{
+				"CREATE FUNCTION tenant_1.inc(i int) RETURNS int AS $$ SELECT $1 + 1; $$ LANGUAGE sql;\n-- +gomigrate StatementEnd\n",
+			},
+		},
+		{
+			name: "undefined variable",
+			sql: `-- +gomigrate Substitute
+-- +gomigrate Up
+CREATE TABLE ${unknown_schema}.users (id int);
+`,
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := parser.parse(strings.NewReader(tt.sql), DirectionUp)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSQLParser_Independent(t *testing.T) {
+	sql := `-- +gomigrate Substitute
+-- +gomigrate Up
+CREATE TABLE {{%users}} (id int);
+`
+	first, _, err := NewSQLParser("postgres", nil, "a_").parse(strings.NewReader(sql), DirectionUp)
+	require.NoError(t, err)
+	second, _, err := NewSQLParser("postgres", nil, "b_").parse(strings.NewReader(sql), DirectionUp)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"CREATE TABLE a_users (id int);\n"}, first)
+	require.Equal(t, []string{"CREATE TABLE b_users (id int);\n"}, second)
+}
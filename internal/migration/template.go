@@ -0,0 +1,74 @@
+package migration
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const templateDateLayout = "2006-01-02"
+
+type sqlTemplateData struct {
+	Version string
+}
+
+// sqlTemplateFuncs are available in .sql.tmpl migrations in addition to text/template builtins.
+var sqlTemplateFuncs = template.FuncMap{
+	// seq returns ints from start to end exclusive: {{range $i := seq 0 12}}.
+	"seq": func(start, end int) []int {
+		var res []int
+		for i := start; i < end; i++ {
+			res = append(res, i)
+		}
+
+		return res
+	},
+	"add":  func(a, b int) int { return a + b },
+	"list": func(items ...string) []string { return items },
+	"join": func(items []string, sep string) string { return strings.Join(items, sep) },
+	// date parses YYYY-MM-DD date: {{$start := date "2021-01-01"}}.
+	"date": func(s string) (time.Time, error) {
+		return time.Parse(templateDateLayout, s)
+	},
+	"addDays":   func(t time.Time, n int) time.Time { return t.AddDate(0, 0, n) },
+	"addMonths": func(t time.Time, n int) time.Time { return t.AddDate(0, n, 0) },
+	"addYears":  func(t time.Time, n int) time.Time { return t.AddDate(n, 0, 0) },
+	// formatDate formats date with Go layout: {{formatDate $d "2006_01"}}.
+	"formatDate": func(t time.Time, layout string) string { return t.Format(layout) },
+	"quoteIdent": func(s string) string {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	},
+	"quoteLiteral": func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	},
+}
+
+// renderSQLTemplate renders .sql.tmpl migration into SQL migration with Up/Down annotations.
+// {{%name}} prefixes are not valid template actions, so they are expanded before rendering.
+func renderSQLTemplate(m *Migration) ([]byte, error) {
+	content, err := ioutil.ReadFile(m.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	if declaresSubstitution(content) {
+		content = []byte(m.parser.orDefault().substitution.expandPrefixes(string(content)))
+	}
+
+	tmpl, err := template.New(filepath.Base(m.Source)).Funcs(sqlTemplateFuncs).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse SQL migration template")
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, sqlTemplateData{Version: m.Version}); err != nil {
+		return nil, errors.Wrap(err, "failed to render SQL migration template")
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,94 @@
+package migration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSQLMigration_Template(t *testing.T) {
+	m := &Migration{
+		Version: "m200101_000000_add_partitions",
+		Source:  "testdata/template_test/m200101_000000_add_partitions.sql.tmpl",
+	}
+
+	tests := []struct {
+		name      string
+		direction Direction
+		want      []string
+	}{
+		{
+			name:      "up",
+			direction: DirectionUp,
+			want: []string{
+				"CREATE TABLE events_2021_11 PARTITION OF events\n    FOR VALUES FROM ('2021-11-01') TO ('2021-12-01');\n",
+				"CREATE TABLE events_2021_12 PARTITION OF events\n    FOR VALUES FROM ('2021-12-01') TO ('2022-01-01');\n",
+				"CREATE TABLE events_2022_01 PARTITION OF events\n    FOR VALUES FROM ('2022-01-01') TO ('2022-02-01');\n",
+			},
+		},
+		{
+			name:      "down",
+			direction: DirectionDown,
+			want: []string{
+				"DROP TABLE events_2021_11;\n",
+				"DROP TABLE events_2021_12;\n",
+				"DROP TABLE events_2022_01;\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := openSQLMigration(m)
+			require.NoError(t, err)
+			defer f.Close()
+
+			got, useTx, err := defaultSQLParser.parse(f, tt.direction)
+			require.NoError(t, err)
+			require.True(t, useTx)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOpenSQLMigration_TemplateSubstitution(t *testing.T) {
+	dir, err := ioutil.TempDir("", "template")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "m200101_000000_add_events.sql.tmpl")
+	require.NoError(t, ioutil.WriteFile(source, []byte(`-- +gomigrate Substitute
+-- +gomigrate Up
+{{range $i := seq 0 2}}
+CREATE TABLE ${schema}.{{%events}}_{{$i}} (id int);
+{{end}}
+`), 0600))
+
+	parser := NewSQLParser("postgres", map[string]string{"schema": "tenant_1"}, "app_")
+	m := &Migration{Version: "m200101_000000_add_events", Source: source, parser: parser}
+
+	f, err := openSQLMigration(m)
+	require.NoError(t, err)
+	defer f.Close()
+
+	got, _, err := parser.parse(f, DirectionUp)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"CREATE TABLE tenant_1.app_events_0 (id int);\n",
+		"CREATE TABLE tenant_1.app_events_1 (id int);\n",
+	}, got)
+}
+
+func TestSQLTemplateFuncs(t *testing.T) {
+	seq := sqlTemplateFuncs["seq"].(func(int, int) []int)
+	require.Equal(t, []int{1, 2, 3}, seq(1, 4))
+	require.Nil(t, seq(4, 4))
+
+	quoteIdent := sqlTemplateFuncs["quoteIdent"].(func(string) string)
+	require.Equal(t, `"some ""table"""`, quoteIdent(`some "table"`))
+
+	quoteLiteral := sqlTemplateFuncs["quoteLiteral"].(func(string) string)
+	require.Equal(t, `'it''s'`, quoteLiteral(`it's`))
+}
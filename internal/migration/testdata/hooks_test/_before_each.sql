@@ -0,0 +1 @@
+SET lock_timeout = '5s';
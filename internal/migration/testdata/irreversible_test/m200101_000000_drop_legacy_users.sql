@@ -0,0 +1,5 @@
+-- +gomigrate Irreversible
+-- +gomigrate Up
+DROP TABLE legacy_users;
+
+-- +gomigrate Down
@@ -0,0 +1 @@
+REFRESH MATERIALIZED VIEW stats;
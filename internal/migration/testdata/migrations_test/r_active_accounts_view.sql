@@ -0,0 +1,3 @@
+-- +gomigrate StatementBegin
+CREATE OR REPLACE VIEW active_accounts AS SELECT * FROM accounts WHERE active;
+-- +gomigrate StatementEnd
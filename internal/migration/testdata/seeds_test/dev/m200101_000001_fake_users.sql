@@ -0,0 +1,5 @@
+-- +gomigrate Up
+INSERT INTO users (name, role) VALUES ('john', 'admin') ON CONFLICT DO NOTHING;
+
+-- +gomigrate Down
+DELETE FROM users WHERE name = 'john';
@@ -0,0 +1,5 @@
+-- +gomigrate Up
+INSERT INTO roles (name) VALUES ('admin'), ('user') ON CONFLICT DO NOTHING;
+
+-- +gomigrate Down
+DELETE FROM roles WHERE name IN ('admin', 'user');
@@ -0,0 +1,5 @@
+-- +gomigrate Up
+INSERT INTO feature_flags (name, enabled) VALUES ('new_checkout', false) ON CONFLICT DO NOTHING;
+
+-- +gomigrate Down
+DELETE FROM feature_flags WHERE name = 'new_checkout';
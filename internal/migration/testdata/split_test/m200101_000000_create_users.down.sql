@@ -0,0 +1,2 @@
+-- +gomigrate Down
+DROP TABLE users;
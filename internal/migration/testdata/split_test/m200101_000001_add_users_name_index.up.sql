@@ -0,0 +1,2 @@
+-- +gomigrate NO TRANSACTION
+CREATE INDEX CONCURRENTLY users_name_idx ON users (name);
@@ -0,0 +1,13 @@
+-- +gomigrate Squashed m200101_000000_test
+-- +gomigrate Squashed m200101_000001_test1
+-- +gomigrate Up
+-- +gomigrate StatementBegin
+CREATE TABLE test (
+    id integer NOT NULL
+);
+-- +gomigrate StatementEnd
+
+-- +gomigrate Down
+-- +gomigrate StatementBegin
+DROP TABLE test;
+-- +gomigrate StatementEnd
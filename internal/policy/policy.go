@@ -0,0 +1,325 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/helpers"
+	"github.com/tweety53/gomigrate/internal/log"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+var (
+	ErrActionForbidden          = errors.New("action is forbidden for this environment by protection policy")
+	ErrOutsideMaintenanceWindow = errors.New("mutating actions are allowed only inside maintenance windows")
+	ErrDBNameNotConfirmed       = errors.New("database name was not confirmed")
+	ErrConfirmationRequired     = errors.New("action needs typed database name and cannot be run non-interactively")
+	ErrInvalidWindowTime        = errors.New("maintenance window time must be in HH:MM format")
+	ErrInvalidWindowDay         = errors.New("maintenance window day must be one of mon, tue, wed, thu, fri, sat, sun")
+)
+
+const clockFormat = "15:04"
+
+// mutatingActions are the actions which modify database schema or migrations history.
+var mutatingActions = map[string]bool{
+	"up":     true,
+	"down":   true,
+	"fresh":  true,
+	"redo":   true,
+	"to":     true,
+	"mark":   true,
+	"seed":   true,
+	"squash": true,
+}
+
+// destructiveActions are the actions which may lose data or rewrite migrations history,
+// options listed for action make it destructive only when passed, e.g. `seed --reset`.
+var destructiveActions = map[string][]string{
+	"down":   nil,
+	"fresh":  nil,
+	"redo":   nil,
+	"to":     nil,
+	"mark":   nil,
+	"squash": nil,
+	"seed":   {"--reset"},
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Policy describes restrictions applied to a single environment.
+type Policy struct {
+	// ForbiddenActions contains actions with optional leading params, e.g. "fresh" or "down all".
+	ForbiddenActions []string `yaml:"forbidden_actions"`
+	// ConfirmDBName requires typing the database name before destructive actions.
+	ConfirmDBName bool `yaml:"confirm_db_name"`
+	// MaintenanceWindows restricts mutating actions to the given time ranges (UTC).
+	MaintenanceWindows []*Window `yaml:"maintenance_windows"`
+}
+
+// Window is a daily time range, From may be greater than To for ranges crossing midnight.
+type Window struct {
+	Days []string `yaml:"days"`
+	From string   `yaml:"from"`
+	To   string   `yaml:"to"`
+}
+
+func (p *Policy) Validate() error {
+	for _, w := range p.MaintenanceWindows {
+		if _, err := time.Parse(clockFormat, w.From); err != nil {
+			return errors.Wrap(ErrInvalidWindowTime, w.From)
+		}
+
+		if _, err := time.Parse(clockFormat, w.To); err != nil {
+			return errors.Wrap(ErrInvalidWindowTime, w.To)
+		}
+
+		for _, d := range w.Days {
+			if _, ok := weekdays[strings.ToLower(d)]; !ok {
+				return errors.Wrap(ErrInvalidWindowDay, d)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *Window) contains(t time.Time) bool {
+	if len(w.Days) > 0 {
+		var dayMatched bool
+		for _, d := range w.Days {
+			if weekdays[strings.ToLower(d)] == t.Weekday() {
+				dayMatched = true
+
+				break
+			}
+		}
+
+		if !dayMatched {
+			return false
+		}
+	}
+
+	// format was checked by Validate()
+	from, _ := time.Parse(clockFormat, w.From)
+	to, _ := time.Parse(clockFormat, w.To)
+	now, _ := time.Parse(clockFormat, t.Format(clockFormat))
+
+	if from.After(to) {
+		return !now.Before(from) || now.Before(to)
+	}
+
+	return !now.Before(from) && now.Before(to)
+}
+
+func (w *Window) String() string {
+	if len(w.Days) == 0 {
+		return fmt.Sprintf("%s-%s", w.From, w.To)
+	}
+
+	return fmt.Sprintf("%s %s-%s", strings.Join(w.Days, ","), w.From, w.To)
+}
+
+type Guard struct {
+	env     string
+	policy  *Policy
+	dbName  func() (string, error)
+	now     func() time.Time
+	confirm func(dbName string) bool
+}
+
+func NewGuard(env string, policy *Policy, dbName func() (string, error)) *Guard {
+	return &Guard{
+		env:     env,
+		policy:  policy,
+		dbName:  dbName,
+		now:     time.Now,
+		confirm: helpers.AskForDatabaseName,
+	}
+}
+
+// Check returns GoMigrateError with exitcode.PolicyViolation if action
+// with given args is not allowed by environment policy.
+func (g *Guard) Check(action string, args []string) error {
+	if g.policy == nil {
+		return nil
+	}
+
+	for _, forbidden := range g.policy.ForbiddenActions {
+		if actionMatches(forbidden, action, args) {
+			log.Errf("Action '%s' is forbidden for '%s' environment.\n", forbidden, g.env)
+
+			return violation(errors.Wrap(ErrActionForbidden, forbidden))
+		}
+	}
+
+	if !mutatingActions[action] {
+		return nil
+	}
+
+	if len(g.policy.MaintenanceWindows) > 0 && !g.inMaintenanceWindow() {
+		log.Errf("Action '%s' is allowed for '%s' environment only in maintenance windows: %s\n", action, g.env, g.windowsString())
+
+		return violation(ErrOutsideMaintenanceWindow)
+	}
+
+	if !g.ConfirmsDBName(action, args) {
+		return nil
+	}
+
+	if g.confirm == nil {
+		log.Errf("Action '%s' needs typed database name for '%s' environment. Nothing has been performed.\n", action, g.env)
+
+		return violation(errors.Wrap(ErrConfirmationRequired, action))
+	}
+
+	dbName, err := g.dbName()
+	if err != nil {
+		return errors.Wrap(err, "cannot get database name for confirmation")
+	}
+
+	if !g.confirm(dbName) {
+		log.Err("Typed database name does not match. Nothing has been performed.")
+
+		return violation(ErrDBNameNotConfirmed)
+	}
+
+	return nil
+}
+
+// CheckNonInteractive is Check of caller which cannot ask for confirmation, e.g. app startup,
+// action needing typed database name is refused.
+func (g *Guard) CheckNonInteractive(action string, args []string) error {
+	guard := *g
+	guard.confirm = nil
+
+	return guard.Check(action, args)
+}
+
+// ConfirmsDBName reports whether Check asks to type database name before action with given args,
+// so the action does not need to ask for confirmation again.
+func (g *Guard) ConfirmsDBName(action string, args []string) bool {
+	return g.policy != nil && g.policy.ConfirmDBName && mutatingActions[action] && isDestructive(action, args)
+}
+
+func (g *Guard) inMaintenanceWindow() bool {
+	now := g.now().UTC()
+	for _, w := range g.policy.MaintenanceWindows {
+		if w.contains(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Guard) windowsString() string {
+	windows := make([]string, 0, len(g.policy.MaintenanceWindows))
+	for _, w := range g.policy.MaintenanceWindows {
+		windows = append(windows, w.String())
+	}
+
+	return strings.Join(windows, "; ") + " (UTC)"
+}
+
+// actionMatches checks whether action with args is covered by forbidden rule,
+// e.g. rule "down all" covers `down all` and `down 0` but not `down 2`, rule "down" covers all of them.
+// Options of rule, e.g. "--reset", must be passed in any position, the rest of params are matched positionally.
+func actionMatches(rule string, action string, args []string) bool {
+	fields := strings.Fields(rule)
+	if len(fields) == 0 || fields[0] != action {
+		return false
+	}
+
+	params, options := splitArgs(args)
+	ruleParams, ruleOptions := splitArgs(fields[1:])
+
+	for o := range ruleOptions {
+		if !options[o] {
+			return false
+		}
+	}
+
+	if len(ruleParams) > len(params) {
+		return false
+	}
+
+	for i, p := range ruleParams {
+		if normalizeParam(p) != normalizeParam(params[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDestructive reports whether action with args may lose data or rewrite migrations history.
+func isDestructive(action string, args []string) bool {
+	options, ok := destructiveActions[action]
+	if !ok {
+		return false
+	}
+
+	if len(options) == 0 {
+		return true
+	}
+
+	_, passed := splitArgs(args)
+	for _, o := range options {
+		if passed[o] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitArgs splits args into positional params and set of options.
+func splitArgs(args []string) ([]string, map[string]bool) {
+	var params []string
+	options := make(map[string]bool)
+
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--") {
+			options[arg] = true
+
+			continue
+		}
+
+		params = append(params, arg)
+	}
+
+	return params, options
+}
+
+// normalizeParam makes equivalent limits equal, 0 limit means all migrations.
+func normalizeParam(p string) string {
+	n, err := strconv.Atoi(p)
+	if err != nil {
+		return p
+	}
+
+	if n == 0 {
+		return helpers.LimitAll
+	}
+
+	return strconv.Itoa(n)
+}
+
+func violation(err error) error {
+	return &errorsInternal.GoMigrateError{
+		Err:      err,
+		ExitCode: exitcode.PolicyViolation,
+	}
+}
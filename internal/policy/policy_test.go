@@ -0,0 +1,305 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *Policy
+		wantErr bool
+	}{
+		{
+			name:    "empty policy",
+			policy:  &Policy{},
+			wantErr: false,
+		},
+		{
+			name: "valid windows",
+			policy: &Policy{MaintenanceWindows: []*Window{
+				{From: "02:00", To: "05:00"},
+				{Days: []string{"sat", "Sun"}, From: "22:00", To: "06:00"},
+			}},
+			wantErr: false,
+		},
+		{
+			name:    "bad from",
+			policy:  &Policy{MaintenanceWindows: []*Window{{From: "2am", To: "05:00"}}},
+			wantErr: true,
+		},
+		{
+			name:    "bad to",
+			policy:  &Policy{MaintenanceWindows: []*Window{{From: "02:00", To: "25:00"}}},
+			wantErr: true,
+		},
+		{
+			name:    "bad day",
+			policy:  &Policy{MaintenanceWindows: []*Window{{Days: []string{"monday"}, From: "02:00", To: "05:00"}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			require.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestWindow_contains(t *testing.T) {
+	// 2021-01-04 is monday
+	monday := func(clock string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", "2021-01-04 "+clock)
+		require.NoError(t, err)
+
+		return tm
+	}
+
+	tests := []struct {
+		name   string
+		window *Window
+		t      time.Time
+		want   bool
+	}{
+		{
+			name:   "inside",
+			window: &Window{From: "02:00", To: "05:00"},
+			t:      monday("03:00"),
+			want:   true,
+		},
+		{
+			name:   "from is inclusive",
+			window: &Window{From: "02:00", To: "05:00"},
+			t:      monday("02:00"),
+			want:   true,
+		},
+		{
+			name:   "to is exclusive",
+			window: &Window{From: "02:00", To: "05:00"},
+			t:      monday("05:00"),
+			want:   false,
+		},
+		{
+			name:   "crossing midnight before midnight",
+			window: &Window{From: "22:00", To: "04:00"},
+			t:      monday("23:30"),
+			want:   true,
+		},
+		{
+			name:   "crossing midnight after midnight",
+			window: &Window{From: "22:00", To: "04:00"},
+			t:      monday("01:00"),
+			want:   true,
+		},
+		{
+			name:   "crossing midnight outside",
+			window: &Window{From: "22:00", To: "04:00"},
+			t:      monday("12:00"),
+			want:   false,
+		},
+		{
+			name:   "day matched",
+			window: &Window{Days: []string{"mon"}, From: "02:00", To: "05:00"},
+			t:      monday("03:00"),
+			want:   true,
+		},
+		{
+			name:   "day not matched",
+			window: &Window{Days: []string{"sat", "sun"}, From: "02:00", To: "05:00"},
+			t:      monday("03:00"),
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.window.contains(tt.t))
+		})
+	}
+}
+
+func TestGuard_Check(t *testing.T) {
+	inWindow := func() time.Time { return time.Date(2021, 1, 4, 3, 0, 0, 0, time.UTC) }
+	outOfWindow := func() time.Time { return time.Date(2021, 1, 4, 12, 0, 0, 0, time.UTC) }
+	dbName := func() (string, error) { return "gomigrate_prod", nil }
+
+	prodPolicy := &Policy{
+		ForbiddenActions:   []string{"fresh", "down all", "mark"},
+		ConfirmDBName:      true,
+		MaintenanceWindows: []*Window{{From: "02:00", To: "05:00"}},
+	}
+
+	tests := []struct {
+		name          string
+		policy        *Policy
+		now           func() time.Time
+		confirmed     bool
+		action        string
+		args          []string
+		wantErr       bool
+		wantConfirmed bool
+	}{
+		{
+			name:    "no policy",
+			policy:  nil,
+			action:  "fresh",
+			wantErr: false,
+		},
+		{
+			name:    "forbidden action",
+			policy:  prodPolicy,
+			now:     inWindow,
+			action:  "fresh",
+			wantErr: true,
+		},
+		{
+			name:    "forbidden action with params",
+			policy:  prodPolicy,
+			now:     inWindow,
+			action:  "down",
+			args:    []string{"all"},
+			wantErr: true,
+		},
+		{
+			name:    "forbidden action with equivalent params",
+			policy:  prodPolicy,
+			now:     inWindow,
+			action:  "down",
+			args:    []string{"--atomic", "0"},
+			wantErr: true,
+		},
+		{
+			name:    "forbidden action with option",
+			policy:  &Policy{ForbiddenActions: []string{"seed --reset"}},
+			action:  "seed",
+			args:    []string{"--reset"},
+			wantErr: true,
+		},
+		{
+			name:    "forbidden option is not passed",
+			policy:  &Policy{ForbiddenActions: []string{"seed --reset"}},
+			action:  "seed",
+			wantErr: false,
+		},
+		{
+			name:          "allowed destructive action confirmed",
+			policy:        prodPolicy,
+			now:           inWindow,
+			confirmed:     true,
+			action:        "down",
+			args:          []string{"2"},
+			wantErr:       false,
+			wantConfirmed: true,
+		},
+		{
+			name:          "allowed destructive action not confirmed",
+			policy:        prodPolicy,
+			now:           inWindow,
+			confirmed:     false,
+			action:        "down",
+			wantErr:       true,
+			wantConfirmed: true,
+		},
+		{
+			name:          "destructive option needs confirmation",
+			policy:        prodPolicy,
+			now:           inWindow,
+			confirmed:     false,
+			action:        "seed",
+			args:          []string{"--reset"},
+			wantErr:       true,
+			wantConfirmed: true,
+		},
+		{
+			name:    "seed without reset needs no confirmation",
+			policy:  prodPolicy,
+			now:     inWindow,
+			action:  "seed",
+			wantErr: false,
+		},
+		{
+			name:          "squash needs confirmation",
+			policy:        prodPolicy,
+			now:           inWindow,
+			confirmed:     true,
+			action:        "squash",
+			args:          []string{"m200101_000000_test"},
+			wantErr:       false,
+			wantConfirmed: true,
+		},
+		{
+			name:    "non destructive action needs no confirmation",
+			policy:  prodPolicy,
+			now:     inWindow,
+			action:  "up",
+			wantErr: false,
+		},
+		{
+			name:    "mutating action outside maintenance window",
+			policy:  prodPolicy,
+			now:     outOfWindow,
+			action:  "up",
+			wantErr: true,
+		},
+		{
+			name:    "read only action outside maintenance window",
+			policy:  prodPolicy,
+			now:     outOfWindow,
+			action:  "history",
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var confirmCalled bool
+			g := NewGuard("prod", tt.policy, dbName)
+			g.now = tt.now
+			g.confirm = func(name string) bool {
+				confirmCalled = true
+				require.Equal(t, "gomigrate_prod", name)
+
+				return tt.confirmed
+			}
+
+			err := g.Check(tt.action, tt.args)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.wantConfirmed, confirmCalled)
+			if err != nil {
+				require.Equal(t, exitcode.PolicyViolation, errorsInternal.ErrorExitCode(err))
+			}
+		})
+	}
+}
+
+func TestGuard_ConfirmsDBName(t *testing.T) {
+	dbName := func() (string, error) { return "gomigrate_prod", nil }
+	g := NewGuard("prod", &Policy{ConfirmDBName: true}, dbName)
+
+	require.True(t, g.ConfirmsDBName("seed", []string{"--reset"}))
+	require.False(t, g.ConfirmsDBName("seed", nil))
+	require.False(t, g.ConfirmsDBName("up", nil))
+	require.False(t, NewGuard("prod", &Policy{}, dbName).ConfirmsDBName("seed", []string{"--reset"}))
+	require.False(t, NewGuard("dev", nil, dbName).ConfirmsDBName("seed", []string{"--reset"}))
+}
+
+func TestGuard_CheckNonInteractive(t *testing.T) {
+	dbName := func() (string, error) { return "gomigrate_prod", nil }
+	g := NewGuard("prod", &Policy{ConfirmDBName: true}, dbName)
+	g.confirm = func(string) bool {
+		t.Fatal("confirmation must not be asked")
+
+		return true
+	}
+
+	err := g.CheckNonInteractive("down", []string{"1"})
+	require.ErrorIs(t, err, ErrConfirmationRequired)
+	require.Equal(t, exitcode.PolicyViolation, errorsInternal.ErrorExitCode(err))
+
+	require.NoError(t, g.CheckNonInteractive("up", nil))
+	require.NoError(t, NewGuard("dev", nil, dbName).CheckNonInteractive("down", []string{"1"}))
+}
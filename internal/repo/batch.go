@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"github.com/pkg/errors"
+)
+
+// EnsureBatchesTable creates batches table, it is called along with migrations table creation and
+// before migrating, so reads do not run DDL.
+func (r *MigrationsRepository) EnsureBatchesTable() error {
+	if _, err := r.exec(r.dialect.CreateBatchesTableSQL()); err != nil {
+		return errors.Wrap(err, "failed to create batches table")
+	}
+
+	return nil
+}
+
+// NextBatch returns batch number for the next up invocation, the first batch is returned
+// if batches table is not created yet.
+func (r *MigrationsRepository) NextBatch() (int, error) {
+	rows, err := r.query(r.dialect.NextBatchSQL())
+	if undefinedTable(err) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var batch int
+	if rows.Next() {
+		if err := rows.Scan(&batch); err != nil {
+			return 0, errors.Wrap(err, "failed to scan row")
+		}
+	}
+
+	return batch, rows.Err()
+}
+
+func (r *MigrationsRepository) SaveBatch(v string, batch int) error {
+	if _, err := r.exec(r.dialect.SaveBatchSQL(), v, batch); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetBatches returns batch numbers by versions, no batches are returned if batches table is not created yet.
+func (r *MigrationsRepository) GetBatches() (map[string]int, error) {
+	rows, err := r.query(r.dialect.BatchesSQL())
+	if undefinedTable(err) {
+		return map[string]int{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batches := make(map[string]int)
+	for rows.Next() {
+		var (
+			v     string
+			batch int
+		)
+		if err := rows.Scan(&v, &batch); err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+
+		batches[v] = batch
+	}
+
+	return batches, rows.Err()
+}
+
+func (r *MigrationsRepository) DeleteBatch(v string) error {
+	if _, err := r.exec(r.dialect.DeleteBatchSQL(), v); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+)
+
+func TestMigrationsRepository_Batch(t *testing.T) {
+	history := regexp.QuoteMeta("SELECT version, COALESCE(apply_time, 0) FROM some_table")
+	createTable := regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS some_table_batch")
+	next := regexp.QuoteMeta("SELECT COALESCE(MAX(batch), 0) + 1 FROM some_table_batch;")
+	save := regexp.QuoteMeta("INSERT INTO some_table_batch (version, batch) VALUES ($1, $2)")
+	get := regexp.QuoteMeta("SELECT version, batch FROM some_table_batch;")
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	dialect, err := sqldialect.InitDialect("postgres", "some_table")
+	require.NoError(t, err)
+	r := NewMigrationsRepository(db, dialect)
+
+	// migrations table check does not create batches table
+	mock.ExpectQuery(history).WillReturnRows(sqlmock.NewRows([]string{"version", "apply_time"}))
+	_, err = r.EnsureDBVersion()
+	require.NoError(t, err)
+
+	// no batches are stored before the first migration
+	mock.ExpectQuery(next).WillReturnError(&pq.Error{Code: "42P01"})
+	batch, err := r.NextBatch()
+	require.NoError(t, err)
+	require.Equal(t, 1, batch)
+
+	mock.ExpectQuery(get).WillReturnError(&pq.Error{Code: "42P01"})
+	batches, err := r.GetBatches()
+	require.NoError(t, err)
+	require.Empty(t, batches)
+
+	mock.ExpectExec(createTable).WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, r.EnsureBatchesTable())
+
+	mock.ExpectQuery(next).WillReturnRows(sqlmock.NewRows([]string{"batch"}).AddRow(3))
+	batch, err = r.NextBatch()
+	require.NoError(t, err)
+	require.Equal(t, 3, batch)
+
+	mock.ExpectExec(save).WithArgs("m200101_000000_test", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, r.SaveBatch("m200101_000000_test", 3))
+
+	mock.ExpectQuery(get).WillReturnRows(sqlmock.NewRows([]string{"version", "batch"}).
+		AddRow("m200101_000000_test", 3).
+		AddRow("m200101_000001_test", 2))
+	batches, err = r.GetBatches()
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"m200101_000000_test": 3, "m200101_000001_test": 2}, batches)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -20,6 +20,12 @@ type DBOperationRepoMock struct {
 	beforeAllTableNamesCounter uint64
 	AllTableNamesMock          mDBOperationRepoMockAllTableNames
 
+	funcCurrentDatabaseName          func() (s1 string, err error)
+	inspectFuncCurrentDatabaseName   func()
+	afterCurrentDatabaseNameCounter  uint64
+	beforeCurrentDatabaseNameCounter uint64
+	CurrentDatabaseNameMock          mDBOperationRepoMockCurrentDatabaseName
+
 	funcDropForeignKey          func(tableName string, fkName string) (err error)
 	inspectFuncDropForeignKey   func(tableName string, fkName string)
 	afterDropForeignKeyCounter  uint64
@@ -54,6 +60,8 @@ func NewDBOperationRepoMock(t minimock.Tester) *DBOperationRepoMock {
 
 	m.AllTableNamesMock = mDBOperationRepoMockAllTableNames{mock: m}
 
+	m.CurrentDatabaseNameMock = mDBOperationRepoMockCurrentDatabaseName{mock: m}
+
 	m.DropForeignKeyMock = mDBOperationRepoMockDropForeignKey{mock: m}
 	m.DropForeignKeyMock.callArgs = []*DBOperationRepoMockDropForeignKeyParams{}
 
@@ -212,6 +220,150 @@ func (m *DBOperationRepoMock) MinimockAllTableNamesInspect() {
 	}
 }
 
+type mDBOperationRepoMockCurrentDatabaseName struct {
+	mock               *DBOperationRepoMock
+	defaultExpectation *DBOperationRepoMockCurrentDatabaseNameExpectation
+	expectations       []*DBOperationRepoMockCurrentDatabaseNameExpectation
+}
+
+// DBOperationRepoMockCurrentDatabaseNameExpectation specifies expectation struct of the DBOperationRepo.CurrentDatabaseName
+type DBOperationRepoMockCurrentDatabaseNameExpectation struct {
+	mock *DBOperationRepoMock
+
+	results *DBOperationRepoMockCurrentDatabaseNameResults
+	Counter uint64
+}
+
+// DBOperationRepoMockCurrentDatabaseNameResults contains results of the DBOperationRepo.CurrentDatabaseName
+type DBOperationRepoMockCurrentDatabaseNameResults struct {
+	s1  string
+	err error
+}
+
+// Expect sets up expected params for DBOperationRepo.CurrentDatabaseName
+func (mmCurrentDatabaseName *mDBOperationRepoMockCurrentDatabaseName) Expect() *mDBOperationRepoMockCurrentDatabaseName {
+	if mmCurrentDatabaseName.mock.funcCurrentDatabaseName != nil {
+		mmCurrentDatabaseName.mock.t.Fatalf("DBOperationRepoMock.CurrentDatabaseName mock is already set by Set")
+	}
+
+	if mmCurrentDatabaseName.defaultExpectation == nil {
+		mmCurrentDatabaseName.defaultExpectation = &DBOperationRepoMockCurrentDatabaseNameExpectation{}
+	}
+
+	return mmCurrentDatabaseName
+}
+
+// Inspect accepts an inspector function that has same arguments as the DBOperationRepo.CurrentDatabaseName
+func (mmCurrentDatabaseName *mDBOperationRepoMockCurrentDatabaseName) Inspect(f func()) *mDBOperationRepoMockCurrentDatabaseName {
+	if mmCurrentDatabaseName.mock.inspectFuncCurrentDatabaseName != nil {
+		mmCurrentDatabaseName.mock.t.Fatalf("Inspect function is already set for DBOperationRepoMock.CurrentDatabaseName")
+	}
+
+	mmCurrentDatabaseName.mock.inspectFuncCurrentDatabaseName = f
+
+	return mmCurrentDatabaseName
+}
+
+// Return sets up results that will be returned by DBOperationRepo.CurrentDatabaseName
+func (mmCurrentDatabaseName *mDBOperationRepoMockCurrentDatabaseName) Return(s1 string, err error) *DBOperationRepoMock {
+	if mmCurrentDatabaseName.mock.funcCurrentDatabaseName != nil {
+		mmCurrentDatabaseName.mock.t.Fatalf("DBOperationRepoMock.CurrentDatabaseName mock is already set by Set")
+	}
+
+	if mmCurrentDatabaseName.defaultExpectation == nil {
+		mmCurrentDatabaseName.defaultExpectation = &DBOperationRepoMockCurrentDatabaseNameExpectation{mock: mmCurrentDatabaseName.mock}
+	}
+	mmCurrentDatabaseName.defaultExpectation.results = &DBOperationRepoMockCurrentDatabaseNameResults{s1, err}
+	return mmCurrentDatabaseName.mock
+}
+
+//Set uses given function f to mock the DBOperationRepo.CurrentDatabaseName method
+func (mmCurrentDatabaseName *mDBOperationRepoMockCurrentDatabaseName) Set(f func() (s1 string, err error)) *DBOperationRepoMock {
+	if mmCurrentDatabaseName.defaultExpectation != nil {
+		mmCurrentDatabaseName.mock.t.Fatalf("Default expectation is already set for the DBOperationRepo.CurrentDatabaseName method")
+	}
+
+	if len(mmCurrentDatabaseName.expectations) > 0 {
+		mmCurrentDatabaseName.mock.t.Fatalf("Some expectations are already set for the DBOperationRepo.CurrentDatabaseName method")
+	}
+
+	mmCurrentDatabaseName.mock.funcCurrentDatabaseName = f
+	return mmCurrentDatabaseName.mock
+}
+
+// CurrentDatabaseName implements DBOperationRepo
+func (mmCurrentDatabaseName *DBOperationRepoMock) CurrentDatabaseName() (s1 string, err error) {
+	mm_atomic.AddUint64(&mmCurrentDatabaseName.beforeCurrentDatabaseNameCounter, 1)
+	defer mm_atomic.AddUint64(&mmCurrentDatabaseName.afterCurrentDatabaseNameCounter, 1)
+
+	if mmCurrentDatabaseName.inspectFuncCurrentDatabaseName != nil {
+		mmCurrentDatabaseName.inspectFuncCurrentDatabaseName()
+	}
+
+	if mmCurrentDatabaseName.CurrentDatabaseNameMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmCurrentDatabaseName.CurrentDatabaseNameMock.defaultExpectation.Counter, 1)
+
+		mm_results := mmCurrentDatabaseName.CurrentDatabaseNameMock.defaultExpectation.results
+		if mm_results == nil {
+			mmCurrentDatabaseName.t.Fatal("No results are set for the DBOperationRepoMock.CurrentDatabaseName")
+		}
+		return (*mm_results).s1, (*mm_results).err
+	}
+	if mmCurrentDatabaseName.funcCurrentDatabaseName != nil {
+		return mmCurrentDatabaseName.funcCurrentDatabaseName()
+	}
+	mmCurrentDatabaseName.t.Fatalf("Unexpected call to DBOperationRepoMock.CurrentDatabaseName.")
+	return
+}
+
+// CurrentDatabaseNameAfterCounter returns a count of finished DBOperationRepoMock.CurrentDatabaseName invocations
+func (mmCurrentDatabaseName *DBOperationRepoMock) CurrentDatabaseNameAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmCurrentDatabaseName.afterCurrentDatabaseNameCounter)
+}
+
+// CurrentDatabaseNameBeforeCounter returns a count of DBOperationRepoMock.CurrentDatabaseName invocations
+func (mmCurrentDatabaseName *DBOperationRepoMock) CurrentDatabaseNameBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmCurrentDatabaseName.beforeCurrentDatabaseNameCounter)
+}
+
+// MinimockCurrentDatabaseNameDone returns true if the count of the CurrentDatabaseName invocations corresponds
+// the number of defined expectations
+func (m *DBOperationRepoMock) MinimockCurrentDatabaseNameDone() bool {
+	for _, e := range m.CurrentDatabaseNameMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.CurrentDatabaseNameMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterCurrentDatabaseNameCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcCurrentDatabaseName != nil && mm_atomic.LoadUint64(&m.afterCurrentDatabaseNameCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockCurrentDatabaseNameInspect logs each unmet expectation
+func (m *DBOperationRepoMock) MinimockCurrentDatabaseNameInspect() {
+	for _, e := range m.CurrentDatabaseNameMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Error("Expected call to DBOperationRepoMock.CurrentDatabaseName")
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.CurrentDatabaseNameMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterCurrentDatabaseNameCounter) < 1 {
+		m.t.Error("Expected call to DBOperationRepoMock.CurrentDatabaseName")
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcCurrentDatabaseName != nil && mm_atomic.LoadUint64(&m.afterCurrentDatabaseNameCounter) < 1 {
+		m.t.Error("Expected call to DBOperationRepoMock.CurrentDatabaseName")
+	}
+}
+
 type mDBOperationRepoMockDropForeignKey struct {
 	mock               *DBOperationRepoMock
 	defaultExpectation *DBOperationRepoMockDropForeignKeyExpectation
@@ -1007,6 +1159,8 @@ func (m *DBOperationRepoMock) MinimockFinish() {
 	if !m.minimockDone() {
 		m.MinimockAllTableNamesInspect()
 
+		m.MinimockCurrentDatabaseNameInspect()
+
 		m.MinimockDropForeignKeyInspect()
 
 		m.MinimockDropTableInspect()
@@ -1038,6 +1192,7 @@ func (m *DBOperationRepoMock) minimockDone() bool {
 	done := true
 	return done &&
 		m.MinimockAllTableNamesDone() &&
+		m.MinimockCurrentDatabaseNameDone() &&
 		m.MinimockDropForeignKeyDone() &&
 		m.MinimockDropTableDone() &&
 		m.MinimockGetForeignKeysDone() &&
@@ -125,3 +125,12 @@ func (r *DBOperationsRepository) AllTableNames() ([]string, error) {
 
 	return tableNames, err
 }
+
+func (r *DBOperationsRepository) CurrentDatabaseName() (string, error) {
+	var name string
+	if err := r.db.QueryRow(r.dialect.CurrentDatabaseSQL()).Scan(&name); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// DownScript is parsed down part of applied SQL migration, it reverts migration whose file is missing,
+// e.g. after rollback deploy.
+type DownScript struct {
+	Statements []string
+	UseTx      bool
+}
+
+// EnsureDownScriptsTable creates down scripts table, it is called along with migrations table creation and
+// before migrating, so reads do not run DDL.
+func (r *MigrationsRepository) EnsureDownScriptsTable() error {
+	if _, err := r.exec(r.dialect.CreateDownScriptsTableSQL()); err != nil {
+		return errors.Wrap(err, "failed to create down scripts table")
+	}
+
+	return nil
+}
+
+func (r *MigrationsRepository) SaveDownScript(v string, script *DownScript) error {
+	statements, err := json.Marshal(script.Statements)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode down statements")
+	}
+
+	if _, err := r.exec(r.dialect.SaveDownScriptSQL(), v, string(statements), script.UseTx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetDownScript returns stored down script of version, nil is returned if it is not stored
+// or down scripts table is not created yet.
+func (r *MigrationsRepository) GetDownScript(v string) (*DownScript, error) {
+	rows, err := r.query(r.dialect.DownScriptSQL(), v)
+	if undefinedTable(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var (
+		statements string
+		script     DownScript
+	)
+	if err := rows.Scan(&statements, &script.UseTx); err != nil {
+		return nil, errors.Wrap(err, "failed to scan row")
+	}
+
+	if err := json.Unmarshal([]byte(statements), &script.Statements); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode down statements of %s", v)
+	}
+
+	return &script, nil
+}
+
+func (r *MigrationsRepository) DeleteDownScript(v string) error {
+	if _, err := r.exec(r.dialect.DeleteDownScriptSQL(), v); err != nil {
+		return err
+	}
+
+	return nil
+}
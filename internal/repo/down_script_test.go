@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+)
+
+func TestMigrationsRepository_DownScript(t *testing.T) {
+	history := regexp.QuoteMeta("SELECT version, COALESCE(apply_time, 0) FROM some_table")
+	createTable := regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS some_table_down")
+	save := regexp.QuoteMeta("INSERT INTO some_table_down (version, statements, use_tx) VALUES ($1, $2, $3)")
+	get := regexp.QuoteMeta("SELECT statements, use_tx FROM some_table_down WHERE version=$1;")
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	dialect, err := sqldialect.InitDialect("postgres", "some_table")
+	require.NoError(t, err)
+	r := NewMigrationsRepository(db, dialect)
+
+	// migrations table check does not run DDL if table exists
+	mock.ExpectQuery(history).WillReturnRows(sqlmock.NewRows([]string{"version", "apply_time"}))
+	_, err = r.EnsureDBVersion()
+	require.NoError(t, err)
+
+	// down scripts are not stored before the first migration
+	mock.ExpectQuery(get).WithArgs("m200101_000000_test").WillReturnError(&pq.Error{Code: "42P01"})
+	script, err := r.GetDownScript("m200101_000000_test")
+	require.NoError(t, err)
+	require.Nil(t, script)
+
+	mock.ExpectExec(createTable).WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, r.EnsureDownScriptsTable())
+
+	mock.ExpectExec(save).
+		WithArgs("m200101_000000_test", `["DROP TABLE a;","DROP TABLE b;"]`, false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, r.SaveDownScript("m200101_000000_test", &DownScript{
+		Statements: []string{"DROP TABLE a;", "DROP TABLE b;"},
+	}))
+
+	mock.ExpectQuery(get).WithArgs("m200101_000000_test").
+		WillReturnRows(sqlmock.NewRows([]string{"statements", "use_tx"}).AddRow(`["DROP TABLE a;","DROP TABLE b;"]`, false))
+	script, err = r.GetDownScript("m200101_000000_test")
+	require.NoError(t, err)
+	require.Equal(t, &DownScript{Statements: []string{"DROP TABLE a;", "DROP TABLE b;"}}, script)
+
+	mock.ExpectQuery(get).WithArgs("m200101_000001_test").
+		WillReturnRows(sqlmock.NewRows([]string{"statements", "use_tx"}))
+	script, err = r.GetDownScript("m200101_000001_test")
+	require.NoError(t, err)
+	require.Nil(t, script)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrationsRepository_CreateVersionTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	dialect, err := sqldialect.InitDialect("postgres", "some_table")
+	require.NoError(t, err)
+
+	// tables storing data of applied migrations are created along with migrations table
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, COALESCE(apply_time, 0) FROM some_table")).
+		WillReturnError(&pq.Error{Code: "42P01"})
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE some_table (")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS some_table_down")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS some_table_batch")).WillReturnResult(sqlmock.NewResult(0, 0))
+	_, err = NewMigrationsRepository(db, dialect).EnsureDBVersion()
+	require.NoError(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
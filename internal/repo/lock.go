@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+)
+
+var ErrLocked = errors.New("migrations are being run by another process")
+
+// Lock is global migrations lock, session level lock is held on its own connection until Unlock.
+type Lock struct {
+	conn    *sql.Conn
+	dialect sqldialect.SQLDialect
+}
+
+// TryLock takes global migrations lock, ErrLocked is returned if it is held by another session.
+func TryLock(ctx context.Context, db *sql.DB, dialect sqldialect.SQLDialect) (*Lock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get connection for migrations lock")
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, dialect.TryLockSQL(), dialect.MigrationTable()).Scan(&locked); err != nil {
+		conn.Close()
+
+		return nil, errors.Wrap(err, "cannot take migrations lock")
+	}
+
+	if !locked {
+		conn.Close()
+
+		return nil, ErrLocked
+	}
+
+	return &Lock{conn: conn, dialect: dialect}, nil
+}
+
+// Unlock releases lock and returns its connection to the pool.
+func (l *Lock) Unlock() error {
+	defer l.conn.Close()
+
+	var unlocked bool
+	if err := l.conn.QueryRowContext(context.Background(), l.dialect.UnlockSQL(), l.dialect.MigrationTable()).Scan(&unlocked); err != nil {
+		return errors.Wrap(err, "cannot release migrations lock")
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+)
+
+func TestTryLock(t *testing.T) {
+	tryLock := regexp.QuoteMeta("SELECT pg_try_advisory_lock(hashtext($1));")
+	unlock := regexp.QuoteMeta("SELECT pg_advisory_unlock(hashtext($1));")
+
+	tests := []struct {
+		name    string
+		expect  func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "locked and unlocked",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(tryLock).WithArgs("some_table").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				mock.ExpectQuery(unlock).WithArgs("some_table").WillReturnRows(sqlmock.NewRows([]string{"unlocked"}).AddRow(true))
+			},
+		},
+		{
+			name: "held by another session",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(tryLock).WithArgs("some_table").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+			},
+			wantErr: ErrLocked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			dialect, err := sqldialect.InitDialect("postgres", "some_table")
+			require.NoError(t, err)
+			tt.expect(mock)
+
+			l, err := TryLock(context.Background(), db, dialect)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.NoError(t, l.Unlock())
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
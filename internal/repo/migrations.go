@@ -1,18 +1,23 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/tweety53/gomigrate/internal/log"
 	"github.com/tweety53/gomigrate/internal/sqldialect"
+	"github.com/tweety53/gomigrate/internal/tracing"
 )
 
 type MigrationsRepository struct {
 	db      *sql.DB
+	tx      *sql.Tx
+	ctx     context.Context
 	dialect sqldialect.SQLDialect
 }
 
@@ -20,6 +25,46 @@ func NewMigrationsRepository(db *sql.DB, dialect sqldialect.SQLDialect) *Migrati
 	return &MigrationsRepository{db: db, dialect: dialect}
 }
 
+// WithTx returns repository running its queries in tx, e.g. to write versions along with atomic migrations.
+func (r *MigrationsRepository) WithTx(tx *sql.Tx) MigrationRepo {
+	return &MigrationsRepository{db: r.db, tx: tx, ctx: r.ctx, dialect: r.dialect}
+}
+
+// WithContext returns repository running its queries with ctx, so they are traced as children of its span.
+func (r *MigrationsRepository) WithContext(ctx context.Context) MigrationRepo {
+	return &MigrationsRepository{db: r.db, tx: r.tx, ctx: ctx, dialect: r.dialect}
+}
+
+func (r *MigrationsRepository) context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+
+	return r.ctx
+}
+
+func (r *MigrationsRepository) exec(query string, args ...interface{}) (res sql.Result, err error) {
+	ctx, span := tracing.Start(r.context(), "gomigrate.repo.exec", tracing.StatementKey.String(tracing.Sanitize(query)))
+	defer func() { span.End(err) }()
+
+	if r.tx != nil {
+		return r.tx.ExecContext(ctx, query, args...)
+	}
+
+	return r.db.ExecContext(ctx, query, args...)
+}
+
+func (r *MigrationsRepository) query(query string, args ...interface{}) (rows *sql.Rows, err error) {
+	ctx, span := tracing.Start(r.context(), "gomigrate.repo.query", tracing.StatementKey.String(tracing.Sanitize(query)))
+	defer func() { span.End(err) }()
+
+	if r.tx != nil {
+		return r.tx.QueryContext(ctx, query, args...)
+	}
+
+	return r.db.QueryContext(ctx, query, args...)
+}
+
 func (r *MigrationsRepository) GetDB() (*sql.DB, error) {
 	if r.db == nil {
 		return nil, errors.New("cannot get db, not initialized")
@@ -31,7 +76,7 @@ func (r *MigrationsRepository) GetDB() (*sql.DB, error) {
 func (r *MigrationsRepository) GetMigrationsHistory(limit int) (MigrationRecords, error) {
 	query := buildMigrationsHistoryQuery(limit, r.dialect.MigrationsHistorySQL())
 
-	rows, err := r.db.Query(query)
+	rows, err := r.query(query)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +111,7 @@ func buildMigrationsHistoryQuery(limit int, query string) string {
 }
 
 func (r *MigrationsRepository) InsertVersion(v string) error {
-	if _, err := r.db.Exec(r.dialect.InsertVersionSQL(), v, int(time.Now().Unix())); err != nil {
+	if _, err := r.exec(r.dialect.InsertVersionSQL(), v, int(time.Now().Unix())); err != nil {
 		return err
 	}
 
@@ -74,17 +119,19 @@ func (r *MigrationsRepository) InsertVersion(v string) error {
 }
 
 func (r *MigrationsRepository) DeleteVersion(v string) error {
-	if _, err := r.db.Exec(r.dialect.DeleteVersionSQL(), v); err != nil {
+	if _, err := r.exec(r.dialect.DeleteVersionSQL(), v); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// EnsureDBVersion creates migrations table if it does not exist.
 func (r *MigrationsRepository) EnsureDBVersion() (string, error) {
-	_, err := r.GetMigrationsHistory(1)
-	if err != nil {
-		return "", r.CreateVersionTable()
+	if _, err := r.GetMigrationsHistory(1); err != nil {
+		if err := r.CreateVersionTable(); err != nil {
+			return "", err
+		}
 	}
 
 	return "", nil
@@ -100,18 +147,22 @@ func (r *MigrationsRepository) GetDBVersion() (string, error) {
 }
 
 func (r *MigrationsRepository) CreateVersionTable() error {
-	if _, err := r.db.Exec(r.dialect.CreateVersionTableSQL()); err != nil {
+	if _, err := r.exec(r.dialect.CreateVersionTableSQL()); err != nil {
 		log.Warnf("*** failed to apply (cannot create migrations table)")
 		log.Warn("Maybe version table already created by another app? Please check error below")
 
 		return err
 	}
 
-	return nil
+	if err := r.EnsureDownScriptsTable(); err != nil {
+		return err
+	}
+
+	return r.EnsureBatchesTable()
 }
 
 func (r *MigrationsRepository) InsertUnAppliedVersion(v string) error {
-	if _, err := r.db.Exec(r.dialect.InsertUnAppliedVersionSQL(), v); err != nil {
+	if _, err := r.exec(r.dialect.InsertUnAppliedVersionSQL(), v); err != nil {
 		return err
 	}
 
@@ -119,7 +170,7 @@ func (r *MigrationsRepository) InsertUnAppliedVersion(v string) error {
 }
 
 func (r *MigrationsRepository) UpdateApplyTime(v string) error {
-	if _, err := r.db.Exec(r.dialect.UpdateApplyTimeSQL(), int(time.Now().Unix()), v); err != nil {
+	if _, err := r.exec(r.dialect.UpdateApplyTimeSQL(), int(time.Now().Unix()), v); err != nil {
 		return err
 	}
 
@@ -127,9 +178,17 @@ func (r *MigrationsRepository) UpdateApplyTime(v string) error {
 }
 
 func (r *MigrationsRepository) LockVersion(v string) error {
-	if _, err := r.db.Exec(r.dialect.LockVersionSQL(), v); err != nil {
+	if _, err := r.exec(r.dialect.LockVersionSQL(), v); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// undefinedTable reports whether err is returned for query of table which does not exist, e.g. table storing
+// data of applied migrations is not created before the first migration.
+func undefinedTable(err error) bool {
+	var pqErr *pq.Error
+
+	return errors.As(err, &pqErr) && pqErr.Code == "42P01"
+}
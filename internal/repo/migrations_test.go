@@ -34,7 +34,7 @@ func TestMigrationsRepository_GetMigrationsHistory(t *testing.T) {
 					log.Fatal(err)
 				}
 
-				mock.ExpectQuery("SELECT version, apply_time FROM some_table ORDER BY apply_time DESC, version DESC;").
+				mock.ExpectQuery("SELECT version, COALESCE\\(apply_time, 0\\) FROM some_table ORDER BY apply_time DESC, version DESC;").
 					WillReturnError(errors.New("some error"))
 				dialect, err := sqldialect.InitDialect("postgres", "some_table")
 				if err != nil {
@@ -63,7 +63,7 @@ func TestMigrationsRepository_GetMigrationsHistory(t *testing.T) {
 					AddRow("m000000_000000_q", "12345").
 					AddRow("m000000_000001_w", "12345").
 					RowError(0, errors.New("qwe"))
-				mock.ExpectQuery("SELECT version, apply_time FROM some_table ORDER BY apply_time DESC, version DESC;").
+				mock.ExpectQuery("SELECT version, COALESCE\\(apply_time, 0\\) FROM some_table ORDER BY apply_time DESC, version DESC;").
 					WillReturnRows(rows)
 
 				dialect, err := sqldialect.InitDialect("postgres", "some_table")
@@ -92,7 +92,7 @@ func TestMigrationsRepository_GetMigrationsHistory(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"lol", "kek", "cheburek"}).
 					AddRow("1", "m000000_000000_q", "12345").
 					AddRow("2", "m000000_000001_w", "12345")
-				mock.ExpectQuery("SELECT version, apply_time FROM some_table ORDER BY apply_time DESC, version DESC;").
+				mock.ExpectQuery("SELECT version, COALESCE\\(apply_time, 0\\) FROM some_table ORDER BY apply_time DESC, version DESC;").
 					WillReturnRows(rows)
 
 				dialect, err := sqldialect.InitDialect("postgres", "some_table")
@@ -121,7 +121,7 @@ func TestMigrationsRepository_GetMigrationsHistory(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"version", "apply_time"}).
 					AddRow("m000000_000000_q", "12345").
 					AddRow("m000000_000001_w", "12345")
-				mock.ExpectQuery("SELECT version, apply_time FROM some_table ORDER BY apply_time DESC, version DESC;").
+				mock.ExpectQuery("SELECT version, COALESCE\\(apply_time, 0\\) FROM some_table ORDER BY apply_time DESC, version DESC;").
 					WillReturnRows(rows)
 
 				dialect, err := sqldialect.InitDialect("postgres", "some_table")
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+)
+
+type RepeatableMigrationsRepository struct {
+	db      *sql.DB
+	tx      *sql.Tx
+	dialect sqldialect.SQLDialect
+}
+
+func NewRepeatableMigrationsRepository(db *sql.DB, dialect sqldialect.SQLDialect) *RepeatableMigrationsRepository {
+	return &RepeatableMigrationsRepository{db: db, dialect: dialect}
+}
+
+// WithTx returns repository saving checksums in tx, e.g. along with the statements of the applied migration.
+func (r *RepeatableMigrationsRepository) WithTx(tx *sql.Tx) RepeatableMigrationRepo {
+	return &RepeatableMigrationsRepository{db: r.db, tx: tx, dialect: r.dialect}
+}
+
+func (r *RepeatableMigrationsRepository) EnsureTable() error {
+	if _, err := r.db.Exec(r.dialect.CreateRepeatableTableSQL()); err != nil {
+		return errors.Wrap(err, "failed to create repeatable migrations table")
+	}
+
+	return nil
+}
+
+// GetChecksums returns checksums of the last applied repeatable migrations keyed by name, no checksums
+// are returned if repeatable migrations table is not created yet.
+func (r *RepeatableMigrationsRepository) GetChecksums() (map[string]string, error) {
+	rows, err := r.db.Query(r.dialect.RepeatableChecksumsSQL())
+	if undefinedTable(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checksums := make(map[string]string)
+	for rows.Next() {
+		var name, checksum string
+		if err := rows.Scan(&name, &checksum); err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+
+		checksums[name] = checksum
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return checksums, nil
+}
+
+func (r *RepeatableMigrationsRepository) SaveChecksum(name, checksum string) error {
+	query, args := r.dialect.SaveRepeatableChecksumSQL(), []interface{}{name, checksum, int(time.Now().Unix())}
+	if r.tx != nil {
+		_, err := r.tx.Exec(query, args...)
+
+		return err
+	}
+
+	if _, err := r.db.Exec(query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
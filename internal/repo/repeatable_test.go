@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"log"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+)
+
+func TestRepeatableMigrationsRepository_GetChecksums(t *testing.T) {
+	dialect, err := sqldialect.InitDialect("postgres", "migration")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		mock    func(mock sqlmock.Sqlmock)
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "query error",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT name, checksum FROM migration_repeatable;")).
+					WillReturnError(errors.New("some error"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "success",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT name, checksum FROM migration_repeatable;")).
+					WillReturnRows(sqlmock.NewRows([]string{"name", "checksum"}).
+						AddRow("r_views", "abc").
+						AddRow("r_funcs", "def"))
+			},
+			want:    map[string]string{"r_views": "abc", "r_funcs": "def"},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				log.Fatal(err)
+			}
+			tt.mock(mock)
+
+			r := NewRepeatableMigrationsRepository(db, dialect)
+			got, err := r.GetChecksums()
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.want, got)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
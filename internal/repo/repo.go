@@ -1,6 +1,11 @@
 package repo
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+
+	"github.com/tweety53/gomigrate/internal/schema"
+)
 
 type MigrationRepo interface {
 	GetDB() (*sql.DB, error)
@@ -15,17 +20,61 @@ type MigrationRepo interface {
 	LockVersion(v string) error
 }
 
+// DownScriptRepo stores down scripts of applied SQL migrations.
+type DownScriptRepo interface {
+	EnsureDownScriptsTable() error
+	SaveDownScript(v string, script *DownScript) error
+	GetDownScript(v string) (*DownScript, error)
+	DeleteDownScript(v string) error
+}
+
+// ContextRepo runs queries with context, so they are traced as children of its span.
+type ContextRepo interface {
+	WithContext(ctx context.Context) MigrationRepo
+}
+
+// TxRepo runs queries in tx, so data of migration is written along with its version.
+type TxRepo interface {
+	WithTx(tx *sql.Tx) MigrationRepo
+}
+
+// BatchRepo stores batch numbers of applied versions, every up invocation is a batch.
+type BatchRepo interface {
+	EnsureBatchesTable() error
+	NextBatch() (int, error)
+	SaveBatch(v string, batch int) error
+	GetBatches() (map[string]int, error)
+	DeleteBatch(v string) error
+}
+
 type DBOperationRepo interface {
 	TruncateDatabase() error
 	GetForeignKeys(tableName string) (ForeignKeys, error)
 	DropForeignKey(tableName string, fkName string) error
 	DropTable(tableName string) error
 	AllTableNames() ([]string, error)
+	CurrentDatabaseName() (string, error)
+}
+
+type SchemaRepo interface {
+	GetSchema() (*schema.Schema, error)
+	GetCurrentSchema() (*schema.Schema, error)
+	GetSchemaFromSQL(statements []string) (*schema.Schema, error)
+}
+
+type RepeatableMigrationRepo interface {
+	EnsureTable() error
+	GetChecksums() (map[string]string, error)
+	SaveChecksum(name, checksum string) error
+	WithTx(tx *sql.Tx) RepeatableMigrationRepo
 }
 
 type MigrationRecord struct {
-	Version   string
+	Version string
+	// ApplyTime is 0 for dirty version inserted by migration which has not finished yet or crashed.
 	ApplyTime int
+	// Batch is 0 for versions applied before batches were stored or by mark action.
+	Batch int
 }
 
 type MigrationRecords []*MigrationRecord
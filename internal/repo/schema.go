@@ -0,0 +1,220 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/schema"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+)
+
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type SchemaRepository struct {
+	db      *sql.DB
+	dialect sqldialect.SQLDialect
+	// serviceDialects tables are skipped in addition to the dialect ones, e.g. seeds tables.
+	serviceDialects []sqldialect.SQLDialect
+}
+
+func NewSchemaRepository(db *sql.DB, dialect sqldialect.SQLDialect, serviceDialects ...sqldialect.SQLDialect) *SchemaRepository {
+	return &SchemaRepository{db: db, dialect: dialect, serviceDialects: serviceDialects}
+}
+
+// GetSchema introspects database schema, migrations and other service tables are skipped.
+func (r *SchemaRepository) GetSchema() (*schema.Schema, error) {
+	return r.getSchema(r.db)
+}
+
+// GetCurrentSchema introspects objects of the current db schema only (see search_path),
+// returned names are unqualified.
+func (r *SchemaRepository) GetCurrentSchema() (*schema.Schema, error) {
+	var current string
+	if err := r.db.QueryRow(r.dialect.CurrentSchemaSQL()).Scan(&current); err != nil {
+		return nil, errors.Wrap(err, "cannot get current schema")
+	}
+
+	s, err := r.getSchema(r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Unqualified(current), nil
+}
+
+// GetSchemaFromSQL applies statements to a scratch db schema inside a transaction,
+// introspects it and rolls everything back. Returned names are unqualified.
+func (r *SchemaRepository) GetSchemaFromSQL(statements []string) (*schema.Schema, error) {
+	scratch := fmt.Sprintf("gomigrate_scratch_%d", time.Now().UnixNano())
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(r.dialect.CreateSchemaSQL(scratch)); err != nil {
+		return nil, errors.Wrap(err, "cannot create scratch schema")
+	}
+
+	if _, err := tx.Exec(r.dialect.SetLocalSearchPathSQL(scratch)); err != nil {
+		return nil, errors.Wrap(err, "cannot set search path to scratch schema")
+	}
+
+	for i := range statements {
+		if _, err := tx.Exec(statements[i]); err != nil {
+			return nil, errors.Wrapf(err, "failed to execute SQL query %q in scratch schema", statements[i])
+		}
+	}
+
+	s, err := r.getSchema(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Unqualified(scratch), nil
+}
+
+func (r *SchemaRepository) getSchema(q queryer) (*schema.Schema, error) {
+	s := &schema.Schema{}
+
+	err := r.query(q, r.dialect.SequencesSQL(), func(rows *sql.Rows) error {
+		var seq schema.Sequence
+		if err := rows.Scan(&seq.Schema, &seq.Name, &seq.DataType, &seq.Start, &seq.Increment); err != nil {
+			return err
+		}
+
+		s.Sequences = append(s.Sequences, &seq)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot introspect sequences")
+	}
+
+	tables := make(map[string]*schema.Table)
+	err = r.query(q, r.dialect.TablesSQL(), func(rows *sql.Rows) error {
+		var t schema.Table
+		if err := rows.Scan(&t.Schema, &t.Name); err != nil {
+			return err
+		}
+
+		if r.isMigrationTable(t.Schema, t.Name) {
+			return nil
+		}
+
+		tables[t.FullName()] = &t
+		s.Tables = append(s.Tables, &t)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot introspect tables")
+	}
+
+	err = r.query(q, r.dialect.ColumnsSQL(), func(rows *sql.Rows) error {
+		var (
+			tSchema, tName string
+			c              schema.Column
+		)
+		if err := rows.Scan(&tSchema, &tName, &c.Name, &c.Type, &c.NotNull, &c.Default); err != nil {
+			return err
+		}
+
+		if t, ok := tables[tSchema+"."+tName]; ok {
+			t.Columns = append(t.Columns, &c)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot introspect columns")
+	}
+
+	err = r.query(q, r.dialect.ConstraintsSQL(), func(rows *sql.Rows) error {
+		var c schema.Constraint
+		if err := rows.Scan(&c.Schema, &c.Table, &c.Name, &c.Type, &c.Definition); err != nil {
+			return err
+		}
+
+		if r.isMigrationTable(c.Schema, c.Table) {
+			return nil
+		}
+
+		s.Constraints = append(s.Constraints, &c)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot introspect constraints")
+	}
+
+	err = r.query(q, r.dialect.IndexesSQL(), func(rows *sql.Rows) error {
+		var i schema.Index
+		if err := rows.Scan(&i.Schema, &i.Table, &i.Name, &i.Definition); err != nil {
+			return err
+		}
+
+		if r.isMigrationTable(i.Schema, i.Table) {
+			return nil
+		}
+
+		s.Indexes = append(s.Indexes, &i)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot introspect indexes")
+	}
+
+	err = r.query(q, r.dialect.ViewsSQL(), func(rows *sql.Rows) error {
+		var v schema.View
+		if err := rows.Scan(&v.Schema, &v.Name, &v.Definition); err != nil {
+			return err
+		}
+
+		s.Views = append(s.Views, &v)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot introspect views")
+	}
+
+	return s, nil
+}
+
+func (r *SchemaRepository) isMigrationTable(schemaName, tableName string) bool {
+	tables := r.dialect.ServiceTables()
+	for _, d := range r.serviceDialects {
+		tables = append(tables, d.ServiceTables()...)
+	}
+
+	for _, mt := range tables {
+		if mt == tableName || mt == schemaName+"."+tableName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *SchemaRepository) query(q queryer, query string, scan func(rows *sql.Rows) error) error {
+	rows, err := q.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return errors.Wrap(err, "failed to scan row")
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,89 @@
+package repo
+
+import (
+	"log"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/schema"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+)
+
+func TestSchemaRepository_GetSchema(t *testing.T) {
+	dialect, err := sqldialect.InitDialect("postgres", "migration")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		mock    func(mock sqlmock.Sqlmock)
+		want    *schema.Schema
+		wantErr bool
+	}{
+		{
+			name: "sequences query error",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(dialect.SequencesSQL())).WillReturnError(errors.New("some error"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "migration table skipped",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(dialect.SequencesSQL())).
+					WillReturnRows(sqlmock.NewRows([]string{"schema", "name", "data_type", "start", "increment"}).
+						AddRow("public", "users_id_seq", "integer", "1", "1"))
+				mock.ExpectQuery(regexp.QuoteMeta(dialect.TablesSQL())).
+					WillReturnRows(sqlmock.NewRows([]string{"schema", "name"}).
+						AddRow("public", "migration").
+						AddRow("public", "users"))
+				mock.ExpectQuery(regexp.QuoteMeta(dialect.ColumnsSQL())).
+					WillReturnRows(sqlmock.NewRows([]string{"schema", "table", "name", "type", "not_null", "default"}).
+						AddRow("public", "migration", "version", "text", true, "").
+						AddRow("public", "users", "id", "integer", true, "nextval('users_id_seq'::regclass)"))
+				mock.ExpectQuery(regexp.QuoteMeta(dialect.ConstraintsSQL())).
+					WillReturnRows(sqlmock.NewRows([]string{"schema", "table", "name", "type", "definition"}).
+						AddRow("public", "migration", "migration_pkey", "p", "PRIMARY KEY (version)").
+						AddRow("public", "users", "users_pkey", "p", "PRIMARY KEY (id)"))
+				mock.ExpectQuery(regexp.QuoteMeta(dialect.IndexesSQL())).
+					WillReturnRows(sqlmock.NewRows([]string{"schema", "table", "name", "definition"}))
+				mock.ExpectQuery(regexp.QuoteMeta(dialect.ViewsSQL())).
+					WillReturnRows(sqlmock.NewRows([]string{"schema", "name", "definition"}))
+			},
+			want: &schema.Schema{
+				Sequences: []*schema.Sequence{
+					{Schema: "public", Name: "users_id_seq", DataType: "integer", Start: "1", Increment: "1"},
+				},
+				Tables: []*schema.Table{
+					{Schema: "public", Name: "users", Columns: []*schema.Column{
+						{Name: "id", Type: "integer", NotNull: true, Default: "nextval('users_id_seq'::regclass)"},
+					}},
+				},
+				Constraints: []*schema.Constraint{
+					{Schema: "public", Table: "users", Name: "users_pkey", Type: "p", Definition: "PRIMARY KEY (id)"},
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				log.Fatal(err)
+			}
+			tt.mock(mock)
+
+			r := NewSchemaRepository(db, dialect)
+			got, err := r.GetSchema()
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.want, got)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"net"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/log"
+)
+
+//nolint:gochecknoglobals // overridden in tests
+var (
+	initialBackoff = 250 * time.Millisecond
+	maxBackoff     = 5 * time.Second
+)
+
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// WaitForDB pings database until it is ready, transient errors are retried with exponential backoff
+// during maxWait. Zero maxWait means single ping.
+func WaitForDB(ctx context.Context, db Pinger, maxWait time.Duration) error {
+	deadline := time.Now().Add(maxWait)
+	backoff := initialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if !TransientConnError(err) {
+			return errors.Wrap(err, "database is unavailable")
+		}
+
+		left := time.Until(deadline)
+		if left <= 0 {
+			return errors.Wrapf(err, "database is not ready after %d attempts", attempt)
+		}
+
+		if backoff > left {
+			backoff = left
+		}
+
+		log.Warnf("Database is not ready (attempt %d): %v, retrying in %s\n", attempt, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// TransientConnError reports whether connection error may disappear on retry, e.g. connection refused
+// or database system is starting up. Auth failure or unknown database are not transient.
+func TransientConnError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// cannot_connect_now, too_many_connections and connection_exception class
+		return pqErr.Code == "57P03" || pqErr.Code == "53300" || pqErr.Code.Class() == "08"
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
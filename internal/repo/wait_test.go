@@ -0,0 +1,96 @@
+package repo
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type pingerFunc func() error
+
+func (f pingerFunc) PingContext(_ context.Context) error {
+	return f()
+}
+
+func TestTransientConnError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "connection refused", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: connection refused")}, want: true},
+		{name: "starting up", err: &pq.Error{Code: "57P03", Message: "the database system is starting up"}, want: true},
+		{name: "connection failure", err: &pq.Error{Code: "08006"}, want: true},
+		{name: "auth failed", err: &pq.Error{Code: "28P01", Message: "password authentication failed"}, want: false},
+		{name: "unknown database", err: &pq.Error{Code: "3D000", Message: "database \"some\" does not exist"}, want: false},
+		{name: "unknown error", err: errors.New("some error"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, TransientConnError(errors.Wrap(tt.err, "ping")))
+		})
+	}
+}
+
+func TestWaitForDB(t *testing.T) {
+	defer func(initial, max time.Duration) { initialBackoff, maxBackoff = initial, max }(initialBackoff, maxBackoff)
+	initialBackoff, maxBackoff = time.Millisecond, 2*time.Millisecond
+
+	starting := &pq.Error{Code: "57P03"}
+	tests := []struct {
+		name      string
+		errs      []error
+		maxWait   time.Duration
+		wantPings int
+		wantErr   error
+	}{
+		{
+			name:      "ready",
+			wantPings: 1,
+		},
+		{
+			name:      "ready after retries",
+			errs:      []error{starting, starting, starting},
+			maxWait:   time.Second,
+			wantPings: 4,
+		},
+		{
+			name:      "fatal error is not retried",
+			errs:      []error{&pq.Error{Code: "28P01"}},
+			maxWait:   time.Second,
+			wantPings: 1,
+			wantErr:   &pq.Error{Code: "28P01"},
+		},
+		{
+			name:      "no wait",
+			errs:      []error{starting},
+			wantPings: 1,
+			wantErr:   starting,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pings := 0
+			err := WaitForDB(context.Background(), pingerFunc(func() error {
+				pings++
+				if pings <= len(tt.errs) {
+					return tt.errs[pings-1]
+				}
+
+				return nil
+			}), tt.maxWait)
+
+			require.Equal(t, tt.wantPings, pings)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.Equal(t, tt.wantErr, errors.Cause(err))
+			}
+		})
+	}
+}
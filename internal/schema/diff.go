@@ -0,0 +1,200 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// Diff returns statements which turn `from` schema into `to` schema.
+// Tables, columns, sequences, constraints and indexes are compared, views are ignored.
+func Diff(from, to *Schema) []string {
+	var stmts []string
+
+	fromTables, toTables := from.tablesByName(), to.tablesByName()
+	fromConstraints, toConstraints := from.constraintsByName(), to.constraintsByName()
+	fromIndexes, toIndexes := from.indexesByName(), to.indexesByName()
+
+	// drop removed or changed constraints, foreign keys first
+	constraints := from.sortedConstraints()
+	for i := len(constraints) - 1; i >= 0; i-- {
+		c := constraints[i]
+		if _, ok := toTables[c.TableFullName()]; !ok && c.Type != ConstraintForeignKey {
+			// dropped with table, foreign keys are dropped first as tables may reference each other
+			continue
+		}
+
+		if tc, ok := toConstraints[constraintKey(c)]; ok && tc.Type == c.Type && tc.Definition == c.Definition {
+			continue
+		}
+
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", c.TableFullName(), c.Name))
+	}
+
+	// drop removed or changed indexes
+	for _, i := range from.sortedIndexes() {
+		if _, ok := toTables[i.TableFullName()]; !ok {
+			continue
+		}
+
+		if ti, ok := toIndexes[indexKey(i)]; ok && ti.Definition == i.Definition {
+			continue
+		}
+
+		stmts = append(stmts, fmt.Sprintf("DROP INDEX %s;", qualify(i.Schema, i.Name)))
+	}
+
+	// drop removed tables
+	tables := from.sortedTables()
+	for i := len(tables) - 1; i >= 0; i-- {
+		if _, ok := toTables[tables[i].FullName()]; !ok {
+			stmts = append(stmts, fmt.Sprintf("DROP TABLE %s;", tables[i].FullName()))
+		}
+	}
+
+	// create new sequences
+	fromSequences := from.sequencesByName()
+	for _, seq := range to.sortedSequences() {
+		if _, ok := fromSequences[seq.FullName()]; !ok {
+			stmts = append(stmts, seq.SQL())
+		}
+	}
+
+	// create new tables and alter existing ones
+	for _, t := range to.sortedTables() {
+		ft, ok := fromTables[t.FullName()]
+		if !ok {
+			stmts = append(stmts, t.SQL())
+
+			continue
+		}
+
+		stmts = append(stmts, diffColumns(ft, t)...)
+	}
+
+	// add new or changed constraints, foreign keys last
+	for _, c := range to.sortedConstraints() {
+		if fc, ok := fromConstraints[constraintKey(c)]; ok && fc.Type == c.Type && fc.Definition == c.Definition {
+			if _, ok := fromTables[c.TableFullName()]; ok {
+				continue
+			}
+		}
+
+		stmts = append(stmts, c.SQL())
+	}
+
+	// create new or changed indexes
+	for _, i := range to.sortedIndexes() {
+		if fi, ok := fromIndexes[indexKey(i)]; ok && fi.Definition == i.Definition {
+			if _, ok := fromTables[i.TableFullName()]; ok {
+				continue
+			}
+		}
+
+		stmts = append(stmts, i.SQL())
+	}
+
+	// drop removed sequences, owned ones may be already dropped with their tables
+	toSequences := to.sequencesByName()
+	for _, seq := range from.sortedSequences() {
+		if _, ok := toSequences[seq.FullName()]; !ok {
+			stmts = append(stmts, fmt.Sprintf("DROP SEQUENCE IF EXISTS %s;", seq.FullName()))
+		}
+	}
+
+	return stmts
+}
+
+func diffColumns(from, to *Table) []string {
+	var stmts []string
+
+	fromColumns := make(map[string]*Column, len(from.Columns))
+	for _, c := range from.Columns {
+		fromColumns[c.Name] = c
+	}
+
+	toColumns := make(map[string]*Column, len(to.Columns))
+	for _, c := range to.Columns {
+		toColumns[c.Name] = c
+	}
+
+	for _, c := range from.Columns {
+		if _, ok := toColumns[c.Name]; !ok {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", to.FullName(), c.Name))
+		}
+	}
+
+	for _, c := range to.Columns {
+		fc, ok := fromColumns[c.Name]
+		if !ok {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", to.FullName(), c.SQL()))
+
+			continue
+		}
+
+		alter := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", to.FullName(), c.Name)
+		if fc.Type != c.Type {
+			stmts = append(stmts, fmt.Sprintf("%s TYPE %s;", alter, c.Type))
+		}
+
+		if fc.Default != c.Default {
+			if c.Default == "" {
+				stmts = append(stmts, alter+" DROP DEFAULT;")
+			} else {
+				stmts = append(stmts, fmt.Sprintf("%s SET DEFAULT %s;", alter, c.Default))
+			}
+		}
+
+		if fc.NotNull != c.NotNull {
+			if c.NotNull {
+				stmts = append(stmts, alter+" SET NOT NULL;")
+			} else {
+				stmts = append(stmts, alter+" DROP NOT NULL;")
+			}
+		}
+	}
+
+	return stmts
+}
+
+func (s *Schema) tablesByName() map[string]*Table {
+	res := make(map[string]*Table, len(s.Tables))
+	for _, t := range s.Tables {
+		res[t.FullName()] = t
+	}
+
+	return res
+}
+
+func (s *Schema) sequencesByName() map[string]*Sequence {
+	res := make(map[string]*Sequence, len(s.Sequences))
+	for _, seq := range s.Sequences {
+		res[seq.FullName()] = seq
+	}
+
+	return res
+}
+
+func (s *Schema) constraintsByName() map[string]*Constraint {
+	res := make(map[string]*Constraint, len(s.Constraints))
+	for _, c := range s.Constraints {
+		res[constraintKey(c)] = c
+	}
+
+	return res
+}
+
+func (s *Schema) indexesByName() map[string]*Index {
+	res := make(map[string]*Index, len(s.Indexes))
+	for _, i := range s.Indexes {
+		res[indexKey(i)] = i
+	}
+
+	return res
+}
+
+func constraintKey(c *Constraint) string {
+	return c.TableFullName() + "." + c.Name
+}
+
+func indexKey(i *Index) string {
+	return i.TableFullName() + "." + i.Name
+}
@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	accounts := func(columns ...*Column) *Table {
+		return &Table{Name: "accounts", Columns: append([]*Column{{Name: "id", Type: "bigint", NotNull: true}}, columns...)}
+	}
+	accountsPkey := &Constraint{Table: "accounts", Name: "accounts_pkey", Type: ConstraintPrimaryKey, Definition: "PRIMARY KEY (id)"}
+	users := &Table{Name: "users", Columns: []*Column{
+		{Name: "id", Type: "bigint", NotNull: true},
+		{Name: "account_id", Type: "bigint"},
+	}}
+	usersFkey := &Constraint{Table: "users", Name: "users_account_id_fkey", Type: ConstraintForeignKey, Definition: "FOREIGN KEY (account_id) REFERENCES accounts(id)"}
+	usersIdx := &Index{Table: "users", Name: "users_account_id_idx", Definition: "CREATE INDEX users_account_id_idx ON users USING btree (account_id)"}
+
+	tests := []struct {
+		name string
+		from *Schema
+		to   *Schema
+		want []string
+	}{
+		{
+			name: "no changes",
+			from: &Schema{Tables: []*Table{accounts()}, Constraints: []*Constraint{accountsPkey}},
+			to:   &Schema{Tables: []*Table{accounts()}, Constraints: []*Constraint{accountsPkey}},
+			want: nil,
+		},
+		{
+			name: "new table with fk and index",
+			from: &Schema{Tables: []*Table{accounts()}, Constraints: []*Constraint{accountsPkey}},
+			to: &Schema{
+				Tables:      []*Table{accounts(), users},
+				Constraints: []*Constraint{usersFkey, accountsPkey},
+				Indexes:     []*Index{usersIdx},
+			},
+			want: []string{
+				"CREATE TABLE users (\n    id bigint NOT NULL,\n    account_id bigint\n);",
+				"ALTER TABLE ONLY users ADD CONSTRAINT users_account_id_fkey FOREIGN KEY (account_id) REFERENCES accounts(id);",
+				"CREATE INDEX users_account_id_idx ON users USING btree (account_id);",
+			},
+		},
+		{
+			name: "dropped table with fk and index",
+			from: &Schema{
+				Tables:      []*Table{accounts(), users},
+				Constraints: []*Constraint{usersFkey, accountsPkey},
+				Indexes:     []*Index{usersIdx},
+			},
+			to: &Schema{Tables: []*Table{accounts()}, Constraints: []*Constraint{accountsPkey}},
+			want: []string{
+				"ALTER TABLE users DROP CONSTRAINT users_account_id_fkey;",
+				"DROP TABLE users;",
+			},
+		},
+		{
+			name: "columns changed",
+			from: &Schema{Tables: []*Table{accounts(
+				&Column{Name: "name", Type: "text"},
+				&Column{Name: "balance", Type: "integer", NotNull: true, Default: "0"},
+			)}},
+			to: &Schema{Tables: []*Table{accounts(
+				&Column{Name: "balance", Type: "bigint"},
+				&Column{Name: "email", Type: "text", NotNull: true, Default: "''::text"},
+			)}},
+			want: []string{
+				"ALTER TABLE accounts DROP COLUMN name;",
+				"ALTER TABLE accounts ALTER COLUMN balance TYPE bigint;",
+				"ALTER TABLE accounts ALTER COLUMN balance DROP DEFAULT;",
+				"ALTER TABLE accounts ALTER COLUMN balance DROP NOT NULL;",
+				"ALTER TABLE accounts ADD COLUMN email text DEFAULT ''::text NOT NULL;",
+			},
+		},
+		{
+			name: "changed index and fk are recreated",
+			from: &Schema{
+				Tables:      []*Table{accounts(), users},
+				Constraints: []*Constraint{accountsPkey, usersFkey},
+				Indexes:     []*Index{usersIdx},
+			},
+			to: &Schema{
+				Tables: []*Table{accounts(), users},
+				Constraints: []*Constraint{accountsPkey, {
+					Table: "users", Name: "users_account_id_fkey", Type: ConstraintForeignKey,
+					Definition: "FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE",
+				}},
+				Indexes: []*Index{{
+					Table: "users", Name: "users_account_id_idx",
+					Definition: "CREATE UNIQUE INDEX users_account_id_idx ON users USING btree (account_id)",
+				}},
+			},
+			want: []string{
+				"ALTER TABLE users DROP CONSTRAINT users_account_id_fkey;",
+				"DROP INDEX users_account_id_idx;",
+				"ALTER TABLE ONLY users ADD CONSTRAINT users_account_id_fkey FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE;",
+				"CREATE UNIQUE INDEX users_account_id_idx ON users USING btree (account_id);",
+			},
+		},
+		{
+			name: "sequences",
+			from: &Schema{Sequences: []*Sequence{{Name: "old_seq", DataType: "bigint", Start: "1", Increment: "1"}}},
+			to:   &Schema{Sequences: []*Sequence{{Name: "new_seq", DataType: "bigint", Start: "1", Increment: "1"}}},
+			want: []string{
+				"CREATE SEQUENCE new_seq AS bigint START WITH 1 INCREMENT BY 1;",
+				"DROP SEQUENCE IF EXISTS old_seq;",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Diff(tt.from, tt.to))
+		})
+	}
+}
+
+func TestSchema_Unqualified(t *testing.T) {
+	s := &Schema{
+		Tables: []*Table{
+			{Schema: "scratch", Name: "users", Columns: []*Column{{Name: "id", Type: "integer", Default: "nextval('scratch.users_id_seq'::regclass)"}}},
+			{Schema: "other", Name: "users"},
+		},
+		Indexes: []*Index{
+			{Schema: "scratch", Table: "users", Name: "users_idx", Definition: "CREATE INDEX users_idx ON scratch.users USING btree (id)"},
+		},
+		Constraints: []*Constraint{
+			{
+				Schema: "scratch", Table: "users", Name: "users_note_check", Type: ConstraintCheck,
+				Definition: `CHECK ((note <> 'scratch.users'::text) AND (myscratch.f(id) > 0) AND ("scratch.id" > 0))`,
+			},
+			{
+				Schema: "scratch", Table: "users", Name: "users_account_id_fkey", Type: ConstraintForeignKey,
+				Definition: `FOREIGN KEY (account_id) REFERENCES "scratch".accounts(id)`,
+			},
+		},
+	}
+
+	want := &Schema{
+		Tables: []*Table{
+			{Name: "users", Columns: []*Column{{Name: "id", Type: "integer", Default: "nextval('users_id_seq'::regclass)"}}},
+		},
+		Indexes: []*Index{
+			{Table: "users", Name: "users_idx", Definition: "CREATE INDEX users_idx ON users USING btree (id)"},
+		},
+		Constraints: []*Constraint{
+			{
+				Table: "users", Name: "users_note_check", Type: ConstraintCheck,
+				Definition: `CHECK ((note <> 'scratch.users'::text) AND (myscratch.f(id) > 0) AND ("scratch.id" > 0))`,
+			},
+			{
+				Table: "users", Name: "users_account_id_fkey", Type: ConstraintForeignKey,
+				Definition: `FOREIGN KEY (account_id) REFERENCES accounts(id)`,
+			},
+		},
+	}
+
+	require.Equal(t, want, s.Unqualified("scratch"))
+}
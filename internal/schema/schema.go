@@ -0,0 +1,379 @@
+package schema
+
+import (
+	"sort"
+	"strings"
+)
+
+const dumpHeader = "-- gomigrate schema dump. DO NOT EDIT, regenerate it with 'gomigrate dump' action.\n"
+
+// Constraint types in order of creation, foreign keys must be created after referenced keys.
+const (
+	ConstraintPrimaryKey = "p"
+	ConstraintUnique     = "u"
+	ConstraintCheck      = "c"
+	ConstraintExclusion  = "x"
+	ConstraintForeignKey = "f"
+)
+
+var constraintTypeOrder = map[string]int{
+	ConstraintPrimaryKey: 0,
+	ConstraintUnique:     1,
+	ConstraintCheck:      2,
+	ConstraintExclusion:  3,
+	ConstraintForeignKey: 4,
+}
+
+// Schema is an introspected database schema, all names are already quoted if needed.
+type Schema struct {
+	Sequences   []*Sequence
+	Tables      []*Table
+	Constraints []*Constraint
+	Indexes     []*Index
+	Views       []*View
+}
+
+type Sequence struct {
+	Schema    string
+	Name      string
+	DataType  string
+	Start     string
+	Increment string
+}
+
+type Table struct {
+	Schema  string
+	Name    string
+	Columns []*Column
+}
+
+type Column struct {
+	Name    string
+	Type    string
+	NotNull bool
+	Default string
+}
+
+type Constraint struct {
+	Schema     string
+	Table      string
+	Name       string
+	Type       string
+	Definition string
+}
+
+type Index struct {
+	Schema     string
+	Table      string
+	Name       string
+	Definition string
+}
+
+type View struct {
+	Schema     string
+	Name       string
+	Definition string
+}
+
+func (s *Sequence) FullName() string        { return qualify(s.Schema, s.Name) }
+func (t *Table) FullName() string           { return qualify(t.Schema, t.Name) }
+func (c *Constraint) TableFullName() string { return qualify(c.Schema, c.Table) }
+func (i *Index) TableFullName() string      { return qualify(i.Schema, i.Table) }
+func (v *View) FullName() string            { return qualify(v.Schema, v.Name) }
+
+func qualify(schemaName, name string) string {
+	if schemaName == "" {
+		return name
+	}
+
+	return schemaName + "." + name
+}
+
+// Unqualified returns copy of schema with objects from the given db schema only,
+// names and definitions are stripped from the db schema qualifier.
+func (s *Schema) Unqualified(schemaName string) *Schema {
+	strip := func(v string) string {
+		return stripQualifier(v, schemaName)
+	}
+
+	res := &Schema{}
+	for _, seq := range s.Sequences {
+		if seq.Schema == schemaName {
+			c := *seq
+			c.Schema = ""
+			res.Sequences = append(res.Sequences, &c)
+		}
+	}
+
+	for _, t := range s.Tables {
+		if t.Schema != schemaName {
+			continue
+		}
+
+		c := &Table{Name: t.Name}
+		for _, col := range t.Columns {
+			cc := *col
+			cc.Default = strip(cc.Default)
+			c.Columns = append(c.Columns, &cc)
+		}
+		res.Tables = append(res.Tables, c)
+	}
+
+	for _, con := range s.Constraints {
+		if con.Schema == schemaName {
+			c := *con
+			c.Schema = ""
+			c.Definition = strip(c.Definition)
+			res.Constraints = append(res.Constraints, &c)
+		}
+	}
+
+	for _, i := range s.Indexes {
+		if i.Schema == schemaName {
+			c := *i
+			c.Schema = ""
+			c.Definition = strip(c.Definition)
+			res.Indexes = append(res.Indexes, &c)
+		}
+	}
+
+	for _, v := range s.Views {
+		if v.Schema == schemaName {
+			c := *v
+			c.Schema = ""
+			c.Definition = strip(c.Definition)
+			res.Views = append(res.Views, &c)
+		}
+	}
+
+	return res
+}
+
+// stripQualifier removes schemaName qualifier of identifiers in SQL definition. String literals are kept
+// as is except regclass ones, e.g. nextval('scratch.users_id_seq'::regclass), quoted identifiers too.
+func stripQualifier(def, schemaName string) string {
+	prefixes := []string{schemaName + ".", `"` + schemaName + `".`}
+
+	var b strings.Builder
+	for i := 0; i < len(def); {
+		if p := qualifierAt(def, i, prefixes); p != "" {
+			i += len(p)
+
+			continue
+		}
+
+		switch def[i] {
+		case '\'':
+			end := quotedEnd(def, i)
+			lit := def[i:end]
+			if strings.HasPrefix(def[end:], "::regclass") {
+				if p := qualifierAt(lit, 1, prefixes); p != "" {
+					lit = "'" + lit[1+len(p):]
+				}
+			}
+
+			b.WriteString(lit)
+			i = end
+		case '"':
+			end := quotedEnd(def, i)
+			b.WriteString(def[i:end])
+			i = end
+		default:
+			b.WriteByte(def[i])
+			i++
+		}
+	}
+
+	return b.String()
+}
+
+// qualifierAt returns one of prefixes starting identifier at position i of s.
+func qualifierAt(s string, i int, prefixes []string) string {
+	if i > 0 && (isIdentChar(s[i-1]) || s[i-1] == '.' || s[i-1] == '"') {
+		return ""
+	}
+
+	for _, p := range prefixes {
+		if strings.HasPrefix(s[i:], p) {
+			return p
+		}
+	}
+
+	return ""
+}
+
+// quotedEnd returns position after the quoted literal or identifier starting at i, doubled quotes are escaped ones.
+func quotedEnd(s string, i int) int {
+	q := s[i]
+	for j := i + 1; j < len(s); j++ {
+		if s[j] != q {
+			continue
+		}
+
+		if j+1 < len(s) && s[j+1] == q {
+			j++
+
+			continue
+		}
+
+		return j + 1
+	}
+
+	return len(s)
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// SQL renders schema as deterministic sorted DDL script.
+func (s *Schema) SQL() string {
+	var b strings.Builder
+
+	b.WriteString(dumpHeader)
+
+	for _, stmt := range s.Statements() {
+		b.WriteString("\n" + stmt + "\n")
+	}
+
+	return b.String()
+}
+
+// Statements returns DDL statements creating the schema in deterministic order.
+func (s *Schema) Statements() []string {
+	var stmts []string
+
+	for _, seq := range s.sortedSequences() {
+		stmts = append(stmts, seq.SQL())
+	}
+
+	for _, t := range s.sortedTables() {
+		stmts = append(stmts, t.SQL())
+	}
+
+	for _, c := range s.sortedConstraints() {
+		stmts = append(stmts, c.SQL())
+	}
+
+	for _, i := range s.sortedIndexes() {
+		stmts = append(stmts, i.SQL())
+	}
+
+	for _, v := range s.sortedViews() {
+		stmts = append(stmts, v.SQL())
+	}
+
+	return stmts
+}
+
+// DropStatements returns statements dropping all schema objects.
+func (s *Schema) DropStatements() []string {
+	var stmts []string
+
+	views := s.sortedViews()
+	for i := len(views) - 1; i >= 0; i-- {
+		stmts = append(stmts, "DROP VIEW IF EXISTS "+views[i].FullName()+";")
+	}
+
+	return append(stmts, Diff(s, &Schema{})...)
+}
+
+func (s *Sequence) SQL() string {
+	def := "CREATE SEQUENCE " + s.FullName()
+	if s.DataType != "" {
+		def += " AS " + s.DataType
+	}
+
+	return def + " START WITH " + s.Start + " INCREMENT BY " + s.Increment + ";"
+}
+
+func (t *Table) SQL() string {
+	var b strings.Builder
+
+	b.WriteString("CREATE TABLE " + t.FullName() + " (\n")
+	for i, c := range t.Columns {
+		b.WriteString("    " + c.SQL())
+		if i < len(t.Columns)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(");")
+
+	return b.String()
+}
+
+func (c *Column) SQL() string {
+	def := c.Name + " " + c.Type
+	if c.Default != "" {
+		def += " DEFAULT " + c.Default
+	}
+	if c.NotNull {
+		def += " NOT NULL"
+	}
+
+	return def
+}
+
+func (c *Constraint) SQL() string {
+	return "ALTER TABLE ONLY " + c.TableFullName() + " ADD CONSTRAINT " + c.Name + " " + c.Definition + ";"
+}
+
+func (i *Index) SQL() string {
+	return strings.TrimRight(i.Definition, "; \n") + ";"
+}
+
+func (v *View) SQL() string {
+	return "CREATE VIEW " + v.FullName() + " AS\n" + strings.TrimRight(v.Definition, "; \n") + ";"
+}
+
+func (s *Schema) sortedSequences() []*Sequence {
+	res := append([]*Sequence(nil), s.Sequences...)
+	sort.Slice(res, func(i, j int) bool { return res[i].FullName() < res[j].FullName() })
+
+	return res
+}
+
+func (s *Schema) sortedTables() []*Table {
+	res := append([]*Table(nil), s.Tables...)
+	sort.Slice(res, func(i, j int) bool { return res[i].FullName() < res[j].FullName() })
+
+	return res
+}
+
+func (s *Schema) sortedConstraints() []*Constraint {
+	res := append([]*Constraint(nil), s.Constraints...)
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Type != res[j].Type {
+			return constraintTypeOrder[res[i].Type] < constraintTypeOrder[res[j].Type]
+		}
+
+		if res[i].TableFullName() != res[j].TableFullName() {
+			return res[i].TableFullName() < res[j].TableFullName()
+		}
+
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
+
+func (s *Schema) sortedIndexes() []*Index {
+	res := append([]*Index(nil), s.Indexes...)
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].TableFullName() != res[j].TableFullName() {
+			return res[i].TableFullName() < res[j].TableFullName()
+		}
+
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
+
+func (s *Schema) sortedViews() []*View {
+	res := append([]*View(nil), s.Views...)
+	sort.Slice(res, func(i, j int) bool { return res[i].FullName() < res[j].FullName() })
+
+	return res
+}
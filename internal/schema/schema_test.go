@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_SQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *Schema
+		want   string
+	}{
+		{
+			name:   "empty schema",
+			schema: &Schema{},
+			want:   dumpHeader,
+		},
+		{
+			name: "objects are sorted",
+			schema: &Schema{
+				Sequences: []*Sequence{
+					{Schema: "public", Name: "users_id_seq", DataType: "integer", Start: "1", Increment: "1"},
+					{Schema: "public", Name: "accounts_id_seq", DataType: "bigint", Start: "1", Increment: "1"},
+				},
+				Tables: []*Table{
+					{Schema: "public", Name: "users", Columns: []*Column{
+						{Name: "id", Type: "integer", NotNull: true, Default: "nextval('users_id_seq'::regclass)"},
+						{Name: "account_id", Type: "bigint"},
+					}},
+					{Schema: "public", Name: "accounts", Columns: []*Column{
+						{Name: "id", Type: "bigint", NotNull: true},
+					}},
+				},
+				Constraints: []*Constraint{
+					{Schema: "public", Table: "users", Name: "users_account_id_fkey", Type: ConstraintForeignKey, Definition: "FOREIGN KEY (account_id) REFERENCES accounts(id)"},
+					{Schema: "public", Table: "users", Name: "users_pkey", Type: ConstraintPrimaryKey, Definition: "PRIMARY KEY (id)"},
+					{Schema: "public", Table: "accounts", Name: "accounts_pkey", Type: ConstraintPrimaryKey, Definition: "PRIMARY KEY (id)"},
+				},
+				Indexes: []*Index{
+					{Schema: "public", Table: "users", Name: "users_b_idx", Definition: "CREATE INDEX users_b_idx ON public.users USING btree (account_id)"},
+					{Schema: "public", Table: "users", Name: "users_a_idx", Definition: "CREATE INDEX users_a_idx ON public.users USING btree (id)"},
+				},
+				Views: []*View{
+					{Schema: "public", Name: "all_users", Definition: " SELECT users.id\n   FROM users;"},
+				},
+			},
+			want: dumpHeader + `
+CREATE SEQUENCE public.accounts_id_seq AS bigint START WITH 1 INCREMENT BY 1;
+
+CREATE SEQUENCE public.users_id_seq AS integer START WITH 1 INCREMENT BY 1;
+
+CREATE TABLE public.accounts (
+    id bigint NOT NULL
+);
+
+CREATE TABLE public.users (
+    id integer DEFAULT nextval('users_id_seq'::regclass) NOT NULL,
+    account_id bigint
+);
+
+ALTER TABLE ONLY public.accounts ADD CONSTRAINT accounts_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.users ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.users ADD CONSTRAINT users_account_id_fkey FOREIGN KEY (account_id) REFERENCES accounts(id);
+
+CREATE INDEX users_a_idx ON public.users USING btree (id);
+
+CREATE INDEX users_b_idx ON public.users USING btree (account_id);
+
+CREATE VIEW public.all_users AS
+ SELECT users.id
+   FROM users;
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.schema.SQL())
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Package server exposes migrations status and triggered up runs over HTTP.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/action"
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/service"
+)
+
+var ErrRunInProgress = errors.New("migrations are being run by this server")
+
+// LockFn takes global migrations lock and returns func releasing it.
+type LockFn func() (unlock func() error, err error)
+
+// CheckFn checks whether action is allowed, e.g. by protection policy.
+type CheckFn func(action string) error
+
+type Server struct {
+	// ctx is cancelled on server shutdown, runs are not bound to requests, so client disconnect
+	// does not interrupt migration.
+	ctx   context.Context
+	svc   *service.MigrationService
+	token string
+	lock  LockFn
+	check CheckFn
+	// running is a semaphore of runs started by this server, lock guards the rest.
+	running chan struct{}
+}
+
+// New returns server running up action of svc with ctx cancelled on shutdown, empty token disables POST /up.
+func New(ctx context.Context, svc *service.MigrationService, token string, lock LockFn, check CheckFn) *Server {
+	return &Server{ctx: ctx, svc: svc, token: token, lock: lock, check: check, running: make(chan struct{}, 1)}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/up", s.handleUp)
+
+	return mux
+}
+
+type appliedMigration struct {
+	Version   string `json:"version"`
+	ApplyTime int    `json:"apply_time"`
+}
+
+type status struct {
+	Applied []appliedMigration `json:"applied"`
+	Pending []string           `json:"pending"`
+}
+
+func (s *Server) status() (*status, error) {
+	records, err := s.svc.MigrationsRepo.GetMigrationsHistory(0)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get migrations history from db")
+	}
+
+	st := &status{Applied: []appliedMigration{}, Pending: []string{}}
+	for _, r := range records {
+		if r.Version == migration.BaseMigrationVersion {
+			continue
+		}
+
+		st.Applied = append(st.Applied, appliedMigration{Version: r.Version, ApplyTime: r.ApplyTime})
+	}
+
+	pending, err := s.svc.GetNewMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range pending {
+		st.Pending = append(st.Pending, m.Version)
+	}
+
+	return st, nil
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	st, err := s.status()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(st); err != nil {
+		log.Errf("gomigrate server: cannot write status: %v\n", err)
+	}
+}
+
+// handleHealthz fails while there are pending migrations.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	pending, err := s.svc.GetNewMigrations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if len(pending) > 0 {
+		http.Error(w, fmt.Sprintf("%d pending migrations", len(pending)), http.StatusServiceUnavailable)
+
+		return
+	}
+
+	fmt.Fprintln(w, "ok")
+}
+
+// handleUp applies new migrations holding global lock, progress is streamed as server-sent events.
+// The run goes on if client disconnects, the rest of events is dropped.
+func (s *Server) handleUp(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if s.token == "" {
+		http.Error(w, "triggering migrations is disabled, no token configured", http.StatusForbidden)
+
+		return
+	}
+
+	if !s.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+		return
+	}
+
+	if s.check != nil {
+		if err := s.check("up"); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+
+		return
+	}
+
+	unlock, err := s.tryLock()
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, ErrRunInProgress) || errors.Is(err, repo.ErrLocked) {
+			code = http.StatusConflict
+		}
+
+		http.Error(w, err.Error(), code)
+
+		return
+	}
+	defer unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	stream := &eventStream{w: w, flusher: flusher}
+
+	// svc is copied so the stream observes this run only
+	svc := *s.svc
+	svc.Observers = append(append(migration.Observers{}, s.svc.Observers...), stream)
+
+	err = action.NewUpAction(&svc).Run(s.ctx, &action.UpActionParams{})
+	stream.result(err)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	const prefix = "Bearer "
+
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(header[len(prefix):]), []byte(s.token)) == 1
+}
+
+// tryLock takes lock of this server and then the global one without waiting.
+func (s *Server) tryLock() (func(), error) {
+	select {
+	case s.running <- struct{}{}:
+	default:
+		return nil, ErrRunInProgress
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		<-s.running
+
+		return nil, err
+	}
+
+	return func() {
+		if err := unlock(); err != nil {
+			log.Errf("gomigrate server: %v\n", err)
+		}
+
+		<-s.running
+	}, nil
+}
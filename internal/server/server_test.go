@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gojuno/minimock/v3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/service"
+)
+
+const testToken = "secret"
+
+func newTestService(mc *minimock.Controller, pending migration.Migrations) *service.MigrationService {
+	mRepo := repo.NewMigrationRepoMock(mc).
+		GetDBVersionMock.Return("", nil).
+		GetMigrationsHistoryMock.Return(repo.MigrationRecords{
+		{Version: migration.BaseMigrationVersion, ApplyTime: 1},
+		{Version: "m000000_000001_test", ApplyTime: 2},
+	}, nil).
+		GetDBMock.Return(nil, nil).
+		InsertVersionMock.Return(nil)
+	collector := migration.NewMigrationsCollectorInterfaceMock(mc).
+		CollectMigrationsMock.Return(append(migration.Migrations{{Version: "m000000_000001_test"}}, pending...), nil)
+
+	return service.NewMigrationService(nil, mRepo, nil, collector, "")
+}
+
+func pendingMigration() migration.Migrations {
+	return migration.Migrations{{
+		Version:    "m000000_000002_test",
+		Source:     "m000000_000002_test.go",
+		Registered: true,
+		UpFn:       func(*sql.DB) error { return nil },
+	}}
+}
+
+func noLock() (func() error, error) {
+	return func() error { return nil }, nil
+}
+
+func TestServer_Status(t *testing.T) {
+	mc := minimock.NewController(t)
+	srv := New(context.Background(), newTestService(mc, pendingMigration()), testToken, noLock, nil)
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"applied":[{"version":"m000000_000001_test","apply_time":2}],"pending":["m000000_000002_test"]}`, rec.Body.String())
+}
+
+func TestServer_Healthz(t *testing.T) {
+	tests := []struct {
+		name     string
+		pending  migration.Migrations
+		wantCode int
+	}{
+		{
+			name:     "up-to-date",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "pending migrations",
+			pending:  pendingMigration(),
+			wantCode: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := minimock.NewController(t)
+			srv := New(context.Background(), newTestService(mc, tt.pending), testToken, noLock, nil)
+
+			rec := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			require.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestServer_Up(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		token      string
+		auth       string
+		lock       LockFn
+		check      CheckFn
+		busy       bool
+		wantCode   int
+		wantEvents []string
+	}{
+		{
+			name:     "not POST",
+			method:   http.MethodGet,
+			token:    testToken,
+			auth:     "Bearer " + testToken,
+			lock:     noLock,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:     "no token configured",
+			method:   http.MethodPost,
+			lock:     noLock,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "bad token",
+			method:   http.MethodPost,
+			token:    testToken,
+			auth:     "Bearer kek",
+			lock:     noLock,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "token without Bearer prefix",
+			method:   http.MethodPost,
+			token:    testToken,
+			auth:     testToken,
+			lock:     noLock,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "forbidden by policy",
+			method:   http.MethodPost,
+			token:    testToken,
+			auth:     "Bearer " + testToken,
+			lock:     noLock,
+			check:    func(string) error { return errors.New("forbidden") },
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "locked by another process",
+			method:   http.MethodPost,
+			token:    testToken,
+			auth:     "Bearer " + testToken,
+			lock:     func() (func() error, error) { return nil, repo.ErrLocked },
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "run in progress",
+			method:   http.MethodPost,
+			token:    testToken,
+			auth:     "Bearer " + testToken,
+			lock:     noLock,
+			busy:     true,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "migrated up",
+			method:   http.MethodPost,
+			token:    testToken,
+			auth:     "Bearer " + testToken,
+			lock:     noLock,
+			wantCode: http.StatusOK,
+			wantEvents: []string{
+				"run_started",
+				"migration_started",
+				"migration_applied",
+				"run_finished",
+				"result",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := minimock.NewController(t)
+			srv := New(context.Background(), newTestService(mc, pendingMigration()), tt.token, tt.lock, tt.check)
+			if tt.busy {
+				srv.running <- struct{}{}
+			}
+
+			req := httptest.NewRequest(tt.method, "/up", nil)
+			req.Header.Set("Authorization", tt.auth)
+			rec := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantEvents == nil {
+				return
+			}
+
+			require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+			var events []string
+			for _, line := range strings.Split(rec.Body.String(), "\n") {
+				if strings.HasPrefix(line, "event: ") {
+					events = append(events, strings.TrimPrefix(line, "event: "))
+				}
+			}
+			require.Equal(t, tt.wantEvents, events)
+			require.NotContains(t, rec.Body.String(), `"error"`)
+			// run is over, the next one may be started
+			require.Len(t, srv.running, 0)
+		})
+	}
+}
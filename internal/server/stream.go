@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/migration"
+)
+
+// eventResult is the name of the last event of the stream, it is sent even if there was nothing to apply.
+const eventResult = "result"
+
+type streamEvent struct {
+	Action    string  `json:"action,omitempty"`
+	Version   string  `json:"version,omitempty"`
+	Direction string  `json:"direction,omitempty"`
+	TxMode    string  `json:"tx_mode,omitempty"`
+	Statement string  `json:"statement,omitempty"`
+	Duration  float64 `json:"duration,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// eventStream is migration.Observer writing events as server-sent events.
+type eventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (s *eventStream) Notify(e *migration.Event) {
+	se := &streamEvent{
+		Action:    e.Action,
+		Version:   e.Version,
+		Direction: string(e.Direction),
+		TxMode:    string(e.TxMode),
+		Statement: e.Statement,
+		Duration:  e.Duration.Seconds(),
+	}
+	if e.Err != nil {
+		se.Error = e.Err.Error()
+	}
+
+	s.send(string(e.Type), se)
+}
+
+// result sends the outcome of the run.
+func (s *eventStream) result(err error) {
+	se := &streamEvent{Action: "up"}
+	if err != nil {
+		se.Error = err.Error()
+	}
+
+	s.send(eventResult, se)
+}
+
+func (s *eventStream) send(name string, se *streamEvent) {
+	data, err := json.Marshal(se)
+	if err != nil {
+		log.Errf("gomigrate server: cannot marshal event: %v\n", err)
+
+		return
+	}
+
+	// client may be gone, the run goes on anyway
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+		return
+	}
+
+	s.flusher.Flush()
+}
@@ -1,19 +1,47 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/log"
 	"github.com/tweety53/gomigrate/internal/migration"
 	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/schema"
+	"github.com/tweety53/gomigrate/internal/tracing"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
 )
 
+var (
+	ErrSquashedPartiallyApplied  = errors.New("migrations squashed into baseline are applied partially, apply them with original files first")
+	ErrMissingDownScript         = errors.New("migration file is missing and its down script is not stored")
+	ErrBaselinePartiallyReverted = errors.New("baseline reverts all migrations squashed into it, revert all its applied versions at once")
+	ErrNoBatch                   = errors.New("last applied version has no batch, it was applied before batches were stored or marked")
+)
+
+// DefaultSchemaFile is the path schema is dumped to if it is not configured.
+const DefaultSchemaFile = "schema.sql"
+
 type MigrationService struct {
 	DB                  *sql.DB
 	MigrationsRepo      repo.MigrationRepo
 	DBOperationRepo     repo.DBOperationRepo
 	MigrationsPath      string
 	MigrationsCollector migration.MigrationsCollectorInterface
+	SchemaRepo          repo.SchemaRepo
+	RepeatableRepo      repo.RepeatableMigrationRepo
+	// SchemaFile is the path schema is dumped to after up/down, empty value disables auto dump.
+	SchemaFile string
+	Hooks      *migration.Hooks
+	Observers  migration.Observers
+	// Parser parses SQL files read by service, e.g. desired schema, nil uses default one.
+	Parser *migration.SQLParser
 }
 
 func NewMigrationService(
@@ -36,6 +64,12 @@ func (s *MigrationService) GetNewMigrations() (migration.Migrations, error) {
 		return nil, errors.Wrap(err, "cannot get db version")
 	}
 
+	return s.GetPendingMigrations()
+}
+
+// GetPendingMigrations returns migrations which are not applied, unlike GetNewMigrations it does not create
+// migrations table, so it is used by actions which only read database.
+func (s *MigrationService) GetPendingMigrations() (migration.Migrations, error) {
 	records, err := s.MigrationsRepo.GetMigrationsHistory(0)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot get migrations history from db")
@@ -56,14 +90,424 @@ func (s *MigrationService) GetNewMigrations() (migration.Migrations, error) {
 		return nil, errors.Wrapf(err, "cannot collect migration files from path: %s", s.MigrationsPath)
 	}
 
-	newMigrations := make(migration.Migrations, 0, len(allMigrations)-len(applied))
+	newMigrations := make(migration.Migrations, 0, len(allMigrations))
 	for _, row := range allMigrations {
 		if _, ok := applied[row.Version]; ok {
 			continue
 		}
 
+		squashedApplied, err := squashedMigrationApplied(row, applied)
+		if err != nil {
+			return nil, err
+		}
+
+		if squashedApplied {
+			continue
+		}
+
 		newMigrations = append(newMigrations, row)
 	}
 
 	return newMigrations, nil
 }
+
+// GetUnknownAppliedVersions returns applied versions which are neither collected nor squashed into collected baseline,
+// e.g. applied by newer code before rollback deploy.
+func (s *MigrationService) GetUnknownAppliedVersions() ([]string, error) {
+	records, err := s.MigrationsRepo.GetMigrationsHistory(0)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get migrations history from db")
+	}
+
+	allMigrations, err := s.MigrationsCollector.CollectMigrations(s.MigrationsPath, 0, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot collect migration files from path: %s", s.MigrationsPath)
+	}
+
+	known := make(map[string]struct{}, len(allMigrations))
+	for _, m := range allMigrations {
+		known[m.Version] = struct{}{}
+		for _, v := range m.Squashed {
+			known[v] = struct{}{}
+		}
+	}
+
+	var unknown []string
+	for _, record := range records {
+		if record.Version == migration.BaseMigrationVersion {
+			continue
+		}
+
+		if _, ok := known[record.Version]; !ok {
+			unknown = append(unknown, record.Version)
+		}
+	}
+
+	return unknown, nil
+}
+
+// GetDownMigrations returns migrations reverting history records in their order, versions squashed into
+// collected baseline are reverted along with it. Down scripts stored at apply time are used for versions
+// whose migration files are missing.
+func (s *MigrationService) GetDownMigrations(records repo.MigrationRecords) (migration.Migrations, error) {
+	history := migration.Convert(records)
+	if len(history) == 0 {
+		return nil, nil
+	}
+
+	allMigrations, err := s.MigrationsCollector.CollectMigrations(s.MigrationsPath, 0, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot collect migration files from path: %s", s.MigrationsPath)
+	}
+
+	collected := make(map[string]*migration.Migration, len(allMigrations))
+	for _, m := range allMigrations {
+		collected[m.Version] = m
+		for _, v := range m.Squashed {
+			collected[v] = m
+		}
+	}
+
+	downMigrations := make(migration.Migrations, 0, len(history))
+	added := make(map[string]struct{}, len(history))
+	for _, h := range history {
+		if m, ok := collected[h.Version]; ok {
+			if _, ok := added[m.Version]; !ok {
+				added[m.Version] = struct{}{}
+				downMigrations = append(downMigrations, m)
+			}
+
+			continue
+		}
+
+		m, err := s.storedMigration(h.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		downMigrations = append(downMigrations, m)
+	}
+
+	if err := s.ensureBaselinesReverted(downMigrations, history); err != nil {
+		return nil, err
+	}
+
+	return downMigrations, nil
+}
+
+// ensureBaselinesReverted refuses to revert baseline unless all its applied versions are reverted, because
+// baseline down drops the whole schema squashed into it, e.g. on database migrated with original files.
+func (s *MigrationService) ensureBaselinesReverted(downMigrations, history migration.Migrations) error {
+	var applied map[string]struct{}
+
+	reverted := make(map[string]struct{}, len(history))
+	for _, h := range history {
+		reverted[h.Version] = struct{}{}
+	}
+
+	for _, m := range downMigrations {
+		if len(m.Squashed) == 0 {
+			continue
+		}
+
+		if applied == nil {
+			records, err := s.MigrationsRepo.GetMigrationsHistory(0)
+			if err != nil {
+				return errors.Wrap(err, "cannot get migrations history from db")
+			}
+
+			applied = make(map[string]struct{}, len(records))
+			for _, record := range records {
+				applied[record.Version] = struct{}{}
+			}
+		}
+
+		for _, v := range append([]string{m.Version}, m.Squashed...) {
+			_, isApplied := applied[v]
+			_, isReverted := reverted[v]
+			if isApplied && !isReverted {
+				return errors.Wrapf(ErrBaselinePartiallyReverted, "%s is not reverted along with %s", v, m.Version)
+			}
+		}
+	}
+
+	return nil
+}
+
+// storedMigration returns migration reverting version with its stored down script.
+func (s *MigrationService) storedMigration(v string) (*migration.Migration, error) {
+	store, ok := s.MigrationsRepo.(repo.DownScriptRepo)
+	if !ok {
+		return nil, errors.Wrap(ErrMissingDownScript, v)
+	}
+
+	script, err := store.GetDownScript(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get down script of %s", v)
+	}
+
+	if script == nil {
+		return nil, errors.Wrap(ErrMissingDownScript, v)
+	}
+
+	log.Warnf("Migration file of %s is missing, its down script stored at apply time will be used.\n", v)
+
+	return migration.NewStoredMigration(v, script), nil
+}
+
+// EnsureDataTables creates tables storing data of applied migrations if repo stores it, e.g. down scripts
+// and batches.
+// It is called before migrating, so actions which only read database do not run DDL.
+func (s *MigrationService) EnsureDataTables() error {
+	if store, ok := s.MigrationsRepo.(repo.DownScriptRepo); ok {
+		if err := store.EnsureDownScriptsTable(); err != nil {
+			return err
+		}
+	}
+
+	if store, ok := s.MigrationsRepo.(repo.BatchRepo); ok {
+		if err := store.EnsureBatchesTable(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NextBatch returns batch number of migrations applied by up invocation, 0 is returned if repo does not store batches.
+func (s *MigrationService) NextBatch() (int, error) {
+	store, ok := s.MigrationsRepo.(repo.BatchRepo)
+	if !ok {
+		return 0, nil
+	}
+
+	batch, err := store.NextBatch()
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot get next batch from db")
+	}
+
+	return batch, nil
+}
+
+// GetBatchedHistory returns migrations history with batch numbers of versions.
+func (s *MigrationService) GetBatchedHistory(limit int) (repo.MigrationRecords, error) {
+	records, err := s.MigrationsRepo.GetMigrationsHistory(limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get migrations history from db")
+	}
+
+	store, ok := s.MigrationsRepo.(repo.BatchRepo)
+	if !ok {
+		return records, nil
+	}
+
+	batches, err := store.GetBatches()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get batches from db")
+	}
+
+	for _, record := range records {
+		record.Batch = batches[record.Version]
+	}
+
+	return records, nil
+}
+
+// GetLastBatch returns history records of versions applied by the last up invocation.
+func (s *MigrationService) GetLastBatch() (repo.MigrationRecords, error) {
+	records, err := s.GetBatchedHistory(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 || records[0].Version == migration.BaseMigrationVersion {
+		return nil, nil
+	}
+
+	if records[0].Batch == 0 {
+		return nil, errors.Wrap(ErrNoBatch, records[0].Version)
+	}
+
+	n := 0
+	for n < len(records) && records[n].Batch == records[0].Batch {
+		n++
+	}
+
+	return records[:n], nil
+}
+
+// GetChangedRepeatableMigrations returns repeatable migrations which are new or changed since last apply,
+// repeatable migrations table is created by up before applying them.
+func (s *MigrationService) GetChangedRepeatableMigrations() (migration.RepeatableMigrations, error) {
+	if s.RepeatableRepo == nil {
+		return nil, nil
+	}
+
+	allMigrations, err := s.MigrationsCollector.CollectRepeatableMigrations(s.MigrationsPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot collect repeatable migration files from path: %s", s.MigrationsPath)
+	}
+
+	if len(allMigrations) == 0 {
+		return nil, nil
+	}
+
+	checksums, err := s.RepeatableRepo.GetChecksums()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get repeatable migrations checksums from db")
+	}
+
+	changed := make(migration.RepeatableMigrations, 0, len(allMigrations))
+	for _, m := range allMigrations {
+		if checksums[m.Name] != m.Checksum {
+			changed = append(changed, m)
+		}
+	}
+
+	return changed, nil
+}
+
+// squashedMigrationApplied reports whether all migrations squashed into baseline m are applied.
+func squashedMigrationApplied(m *migration.Migration, applied map[string]int) (bool, error) {
+	if len(m.Squashed) == 0 {
+		return false, nil
+	}
+
+	var missing []string
+	for _, v := range m.Squashed {
+		if _, ok := applied[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+
+	switch len(missing) {
+	case 0:
+		return true, nil
+	case len(m.Squashed):
+		return false, nil
+	default:
+		return false, errors.Wrapf(
+			ErrSquashedPartiallyApplied,
+			"%s, not applied: %s", m.Version, strings.Join(missing, ", "))
+	}
+}
+
+// RunWithHooks calls run between before_all and after_all hooks, both run on the same pinned connection,
+// so session settings and locks taken by before_all are kept until after_all. Migrations run on other
+// connections of the pool, so session settings of before_all do not apply to them, only before_each hook
+// running in the tx of the migration can change its settings, e.g. with SET LOCAL.
+func (s *MigrationService) RunWithHooks(ctx context.Context, before, after migration.Direction, run func() error) error {
+	if !s.Hooks.Has(migration.HookBeforeAll) && !s.Hooks.Has(migration.HookAfterAll) {
+		return run()
+	}
+
+	conn, err := s.DB.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err, "cannot get connection for hooks")
+	}
+	defer conn.Close()
+
+	if err := s.Hooks.Run(ctx, conn, &migration.HookEvent{Point: migration.HookBeforeAll, Direction: before}); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := run(); err != nil {
+		return err
+	}
+
+	return s.Hooks.Run(ctx, conn, &migration.HookEvent{Point: migration.HookAfterAll, Direction: after, Duration: time.Since(start)})
+}
+
+// ObserveRun notifies observers about start and finish of the run of migrating action and traces it,
+// the run span is a child of the span of ctx and run gets ctx carrying the run span.
+func (s *MigrationService) ObserveRun(
+	ctx context.Context,
+	action string,
+	direction migration.Direction,
+	run func(ctx context.Context) error,
+) error {
+	s.Observers.Notify(&migration.Event{Type: migration.EventRunStarted, Action: action, Direction: direction})
+	ctx, span := tracing.Start(ctx, "gomigrate.run", tracing.ActionKey.String(action), tracing.DirectionKey.String(string(direction)))
+
+	start := time.Now()
+	err := run(ctx)
+	span.End(err)
+	s.Observers.Notify(&migration.Event{
+		Type:      migration.EventRunFinished,
+		Action:    action,
+		Direction: direction,
+		Duration:  time.Since(start),
+		Err:       err,
+	})
+
+	return err
+}
+
+// DumpSchema writes introspected database schema to the given path.
+func (s *MigrationService) DumpSchema(path string) error {
+	if s.SchemaRepo == nil {
+		return errors.New("schema repo not initialized")
+	}
+
+	dbSchema, err := s.SchemaRepo.GetSchema()
+	if err != nil {
+		return errors.Wrap(err, "cannot get db schema")
+	}
+
+	if err := ioutil.WriteFile(path, []byte(dbSchema.SQL()), 0644); err != nil { //nolint:gosec
+		log.Errf("Failed to dump schema to %s.\n", path)
+
+		return &errorsInternal.GoMigrateError{
+			Err:      err,
+			ExitCode: exitcode.IoErr,
+		}
+	}
+
+	log.Infof("Schema dumped to %s\n", path)
+
+	return nil
+}
+
+// AutoDumpSchema dumps schema to SchemaFile if auto dump is enabled.
+func (s *MigrationService) AutoDumpSchema() error {
+	if s.SchemaFile == "" {
+		return nil
+	}
+
+	return s.DumpSchema(s.SchemaFile)
+}
+
+// DiffSchema compares current db schema with the desired one described by SQL file
+// and returns statements for up and down migration.
+func (s *MigrationService) DiffSchema(desiredPath string) (up []string, down []string, err error) {
+	if s.SchemaRepo == nil {
+		return nil, nil, errors.New("schema repo not initialized")
+	}
+
+	f, err := os.Open(desiredPath)
+	if err != nil {
+		return nil, nil, &errorsInternal.GoMigrateError{
+			Err:      errors.Wrap(err, "cannot open desired schema file"),
+			ExitCode: exitcode.IoErr,
+		}
+	}
+	defer f.Close()
+
+	statements, err := s.Parser.ParseStatements(f)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse desired schema file: %s", desiredPath)
+	}
+
+	desired, err := s.SchemaRepo.GetSchemaFromSQL(statements)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot load desired schema")
+	}
+
+	current, err := s.SchemaRepo.GetCurrentSchema()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot get current db schema")
+	}
+
+	return schema.Diff(current, desired), schema.Diff(desired, current), nil
+}
@@ -1,12 +1,19 @@
 package service
 
 import (
+	"context"
 	"database/sql"
-	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gojuno/minimock/v3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
 	"github.com/tweety53/gomigrate/internal/migration"
 	"github.com/tweety53/gomigrate/internal/repo"
 )
@@ -168,6 +175,128 @@ func TestMigrationService_GetNewMigrations(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "squashed migrations applied",
+			fields: fields{
+				Db: nil,
+				MigrationsRepo: func() *repo.MigrationRepoMock {
+					mc := minimock.NewController(t)
+					mRepoMock := repo.NewMigrationRepoMock(mc).
+						GetDBVersionMock.Return("", nil).
+						GetMigrationsHistoryMock.Return(repo.MigrationRecords{
+						&repo.MigrationRecord{
+							Version:   "m200101_000000_test",
+							ApplyTime: 1,
+						},
+						&repo.MigrationRecord{
+							Version:   "m200101_000001_test1",
+							ApplyTime: 1,
+						},
+					}, nil)
+					return mRepoMock
+				}(),
+				DbOperationRepo: nil,
+				MigrationsPath:  "",
+				MigrationsCollector: func() *migration.MigrationsCollectorInterfaceMock {
+					mc := minimock.NewController(t)
+					cMock := migration.NewMigrationsCollectorInterfaceMock(mc).
+						CollectMigrationsMock.Return(migration.Migrations{
+						&migration.Migration{
+							Version:  "m200101_000001_baseline",
+							Squashed: []string{"m200101_000000_test", "m200101_000001_test1"},
+						},
+						&migration.Migration{
+							Version: "m200101_000002_test2",
+						},
+					}, nil)
+
+					return cMock
+				}(),
+			},
+			want: migration.Migrations{
+				&migration.Migration{
+					Version: "m200101_000002_test2",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "squashed migrations not applied",
+			fields: fields{
+				Db: nil,
+				MigrationsRepo: func() *repo.MigrationRepoMock {
+					mc := minimock.NewController(t)
+					mRepoMock := repo.NewMigrationRepoMock(mc).
+						GetDBVersionMock.Return("", nil).
+						GetMigrationsHistoryMock.Return(repo.MigrationRecords{}, nil)
+					return mRepoMock
+				}(),
+				DbOperationRepo: nil,
+				MigrationsPath:  "",
+				MigrationsCollector: func() *migration.MigrationsCollectorInterfaceMock {
+					mc := minimock.NewController(t)
+					cMock := migration.NewMigrationsCollectorInterfaceMock(mc).
+						CollectMigrationsMock.Return(migration.Migrations{
+						&migration.Migration{
+							Version:  "m200101_000001_baseline",
+							Squashed: []string{"m200101_000000_test", "m200101_000001_test1"},
+						},
+						&migration.Migration{
+							Version: "m200101_000002_test2",
+						},
+					}, nil)
+
+					return cMock
+				}(),
+			},
+			want: migration.Migrations{
+				&migration.Migration{
+					Version:  "m200101_000001_baseline",
+					Squashed: []string{"m200101_000000_test", "m200101_000001_test1"},
+				},
+				&migration.Migration{
+					Version: "m200101_000002_test2",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "squashed migrations applied partially",
+			fields: fields{
+				Db: nil,
+				MigrationsRepo: func() *repo.MigrationRepoMock {
+					mc := minimock.NewController(t)
+					mRepoMock := repo.NewMigrationRepoMock(mc).
+						GetDBVersionMock.Return("", nil).
+						GetMigrationsHistoryMock.Return(repo.MigrationRecords{
+						&repo.MigrationRecord{
+							Version:   "m200101_000000_test",
+							ApplyTime: 1,
+						},
+					}, nil)
+					return mRepoMock
+				}(),
+				DbOperationRepo: nil,
+				MigrationsPath:  "",
+				MigrationsCollector: func() *migration.MigrationsCollectorInterfaceMock {
+					mc := minimock.NewController(t)
+					cMock := migration.NewMigrationsCollectorInterfaceMock(mc).
+						CollectMigrationsMock.Return(migration.Migrations{
+						&migration.Migration{
+							Version:  "m200101_000001_baseline",
+							Squashed: []string{"m200101_000000_test", "m200101_000001_test1"},
+						},
+						&migration.Migration{
+							Version: "m200101_000002_test2",
+						},
+					}, nil)
+
+					return cMock
+				}(),
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -189,3 +318,307 @@ func TestMigrationService_GetNewMigrations(t *testing.T) {
 		})
 	}
 }
+
+type repeatableRepoStub struct {
+	checksums map[string]string
+	err       error
+}
+
+func (r *repeatableRepoStub) EnsureTable() error                            { return nil }
+func (r *repeatableRepoStub) GetChecksums() (map[string]string, error)      { return r.checksums, r.err }
+func (r *repeatableRepoStub) SaveChecksum(_, _ string) error                { return nil }
+func (r *repeatableRepoStub) WithTx(_ *sql.Tx) repo.RepeatableMigrationRepo { return r }
+
+func TestMigrationService_GetChangedRepeatableMigrations(t *testing.T) {
+	views := &migration.RepeatableMigration{Name: "r_views", Checksum: "1"}
+	funcs := &migration.RepeatableMigration{Name: "r_funcs", Checksum: "2"}
+
+	tests := []struct {
+		name           string
+		repeatableRepo repo.RepeatableMigrationRepo
+		collected      migration.RepeatableMigrations
+		want           migration.RepeatableMigrations
+		wantErr        bool
+	}{
+		{
+			name:           "repeatable repo not set",
+			repeatableRepo: nil,
+			want:           nil,
+			wantErr:        false,
+		},
+		{
+			name:           "no repeatable migrations",
+			repeatableRepo: &repeatableRepoStub{},
+			collected:      migration.RepeatableMigrations{},
+			want:           nil,
+			wantErr:        false,
+		},
+		{
+			name:           "new and changed migrations",
+			repeatableRepo: &repeatableRepoStub{checksums: map[string]string{"r_views": "0"}},
+			collected:      migration.RepeatableMigrations{views, funcs},
+			want:           migration.RepeatableMigrations{views, funcs},
+			wantErr:        false,
+		},
+		{
+			name:           "unchanged migration skipped",
+			repeatableRepo: &repeatableRepoStub{checksums: map[string]string{"r_views": "1", "r_funcs": "0"}},
+			collected:      migration.RepeatableMigrations{views, funcs},
+			want:           migration.RepeatableMigrations{funcs},
+			wantErr:        false,
+		},
+		{
+			name:           "checksums err",
+			repeatableRepo: &repeatableRepoStub{err: errors.New("some error")},
+			collected:      migration.RepeatableMigrations{views},
+			want:           nil,
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &MigrationService{
+				RepeatableRepo: tt.repeatableRepo,
+				MigrationsCollector: migration.NewMigrationsCollectorInterfaceMock(t).
+					CollectRepeatableMigrationsMock.Return(tt.collected, nil),
+			}
+			got, err := s.GetChangedRepeatableMigrations()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetChangedRepeatableMigrations() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetChangedRepeatableMigrations() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// downScriptRepoStub is migrations repository storing down scripts.
+type downScriptRepoStub struct {
+	*repo.MigrationRepoMock
+	scripts map[string]*repo.DownScript
+}
+
+func (r *downScriptRepoStub) EnsureDownScriptsTable() error                     { return nil }
+func (r *downScriptRepoStub) SaveDownScript(_ string, _ *repo.DownScript) error { return nil }
+func (r *downScriptRepoStub) GetDownScript(v string) (*repo.DownScript, error) {
+	return r.scripts[v], nil
+}
+func (r *downScriptRepoStub) DeleteDownScript(_ string) error { return nil }
+
+func TestMigrationService_GetDownMigrations(t *testing.T) {
+	first := &migration.Migration{Version: "m200101_000000_test", Source: "m200101_000000_test.sql"}
+	baseline := &migration.Migration{
+		Version:  "m200101_000002_baseline",
+		Source:   "m200101_000002_baseline.sql",
+		Squashed: []string{"m200101_000001_test", "m200101_000002_test"},
+	}
+	script := &repo.DownScript{Statements: []string{"DROP TABLE zulul;"}, UseTx: true}
+	squashedHistory := repo.MigrationRecords{
+		&repo.MigrationRecord{Version: "m200101_000002_test", ApplyTime: 2},
+		&repo.MigrationRecord{Version: "m200101_000001_test", ApplyTime: 2},
+		&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+		&repo.MigrationRecord{Version: migration.BaseMigrationVersion, ApplyTime: 1},
+	}
+
+	tests := []struct {
+		name      string
+		repo      repo.MigrationRepo
+		records   repo.MigrationRecords
+		collected migration.Migrations
+		want      migration.Migrations
+		wantErr   error
+	}{
+		{
+			name:    "no history",
+			records: repo.MigrationRecords{},
+			want:    nil,
+		},
+		{
+			name: "collected migrations",
+			records: repo.MigrationRecords{
+				&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+				&repo.MigrationRecord{Version: migration.BaseMigrationVersion, ApplyTime: 1},
+			},
+			collected: migration.Migrations{first},
+			want:      migration.Migrations{first},
+		},
+		{
+			name: "squashed versions reverted with baseline",
+			repo: repo.NewMigrationRepoMock(t).GetMigrationsHistoryMock.Return(squashedHistory, nil),
+			records: repo.MigrationRecords{
+				&repo.MigrationRecord{Version: "m200101_000002_test", ApplyTime: 2},
+				&repo.MigrationRecord{Version: "m200101_000001_test", ApplyTime: 2},
+				&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+			},
+			collected: migration.Migrations{first, baseline},
+			want:      migration.Migrations{baseline, first},
+		},
+		{
+			name: "baseline is not reverted partially",
+			repo: repo.NewMigrationRepoMock(t).GetMigrationsHistoryMock.Return(squashedHistory, nil),
+			records: repo.MigrationRecords{
+				&repo.MigrationRecord{Version: "m200101_000002_test", ApplyTime: 2},
+			},
+			collected: migration.Migrations{first, baseline},
+			wantErr:   ErrBaselinePartiallyReverted,
+		},
+		{
+			name: "stored down script of missing file",
+			repo: &downScriptRepoStub{scripts: map[string]*repo.DownScript{"m200101_000003_test": script}},
+			records: repo.MigrationRecords{
+				&repo.MigrationRecord{Version: "m200101_000003_test", ApplyTime: 2},
+				&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+			},
+			collected: migration.Migrations{first},
+			want:      migration.Migrations{migration.NewStoredMigration("m200101_000003_test", script), first},
+		},
+		{
+			name: "missing file without down script",
+			repo: &downScriptRepoStub{},
+			records: repo.MigrationRecords{
+				&repo.MigrationRecord{Version: "m200101_000003_test", ApplyTime: 2},
+			},
+			collected: migration.Migrations{first},
+			wantErr:   ErrMissingDownScript,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &MigrationService{
+				MigrationsRepo: tt.repo,
+				MigrationsCollector: migration.NewMigrationsCollectorInterfaceMock(t).
+					CollectMigrationsMock.Return(tt.collected, nil),
+			}
+			got, err := s.GetDownMigrations(tt.records)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetDownMigrations() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetDownMigrations() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// batchRepoStub is migrations repository storing batches.
+type batchRepoStub struct {
+	*repo.MigrationRepoMock
+	batches map[string]int
+}
+
+func (r *batchRepoStub) EnsureBatchesTable() error       { return nil }
+func (r *batchRepoStub) NextBatch() (int, error)         { return 0, nil }
+func (r *batchRepoStub) SaveBatch(_ string, _ int) error { return nil }
+func (r *batchRepoStub) GetBatches() (map[string]int, error) {
+	return r.batches, nil
+}
+func (r *batchRepoStub) DeleteBatch(_ string) error { return nil }
+
+func TestMigrationService_GetLastBatch(t *testing.T) {
+	history := func() repo.MigrationRecords {
+		return repo.MigrationRecords{
+			&repo.MigrationRecord{Version: "m200101_000003_test", ApplyTime: 3},
+			&repo.MigrationRecord{Version: "m200101_000002_test", ApplyTime: 3},
+			&repo.MigrationRecord{Version: "m200101_000001_test", ApplyTime: 2},
+			&repo.MigrationRecord{Version: "m200101_000000_test", ApplyTime: 1},
+			&repo.MigrationRecord{Version: migration.BaseMigrationVersion, ApplyTime: 1},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		batches  map[string]int
+		history  repo.MigrationRecords
+		versions []string
+		wantErr  error
+	}{
+		{
+			name: "last batch",
+			batches: map[string]int{
+				"m200101_000003_test": 3,
+				"m200101_000002_test": 3,
+				"m200101_000001_test": 2,
+			},
+			history:  history(),
+			versions: []string{"m200101_000003_test", "m200101_000002_test"},
+		},
+		{
+			name:     "last batch is the only one",
+			batches:  map[string]int{"m200101_000003_test": 1, "m200101_000002_test": 1, "m200101_000001_test": 1, "m200101_000000_test": 1},
+			history:  history(),
+			versions: []string{"m200101_000003_test", "m200101_000002_test", "m200101_000001_test", "m200101_000000_test"},
+		},
+		{
+			name:    "last version without batch",
+			batches: map[string]int{"m200101_000001_test": 1},
+			history: history(),
+			wantErr: ErrNoBatch,
+		},
+		{
+			name:    "only base migration",
+			history: repo.MigrationRecords{&repo.MigrationRecord{Version: migration.BaseMigrationVersion, ApplyTime: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &MigrationService{
+				MigrationsRepo: &batchRepoStub{
+					MigrationRepoMock: repo.NewMigrationRepoMock(t).GetMigrationsHistoryMock.Return(tt.history, nil),
+					batches:           tt.batches,
+				},
+			}
+			got, err := s.GetLastBatch()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetLastBatch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var versions []string
+			for _, record := range got {
+				versions = append(versions, record.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.versions) {
+				t.Errorf("GetLastBatch() got = %v, want %v", versions, tt.versions)
+			}
+		})
+	}
+}
+
+func TestMigrationService_RunWithHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomigrate_hooks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "_before_all.sql"), []byte("SELECT pg_advisory_lock(1);\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "_after_all.sql"), []byte("SELECT pg_advisory_unlock(1);\n"), 0600))
+
+	hooks, err := migration.CollectHooks(dir, nil)
+	require.NoError(t, err)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock(1);")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock(1);")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	var ran bool
+	s := &MigrationService{DB: db, Hooks: hooks}
+	require.NoError(t, s.RunWithHooks(context.Background(), migration.DirectionUp, migration.DirectionUp, func() error {
+		ran = true
+
+		return nil
+	}))
+	require.True(t, ran)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// run error skips after_all hooks
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock(1);")).WillReturnResult(sqlmock.NewResult(0, 0))
+	errSome := errors.New("some error")
+	require.ErrorIs(t, s.RunWithHooks(context.Background(), migration.DirectionUp, migration.DirectionUp, func() error {
+		return errSome
+	}), errSome)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -79,5 +79,214 @@ func (pd PostgresDialect) LockVersionSQL() string {
 }
 
 func (pd PostgresDialect) MigrationsHistorySQL() string {
-	return fmt.Sprintf("SELECT version, apply_time FROM %s ORDER BY apply_time DESC, version DESC;", pd.migrationTable)
+	return fmt.Sprintf("SELECT version, COALESCE(apply_time, 0) FROM %s ORDER BY apply_time DESC, version DESC;", pd.migrationTable)
+}
+
+func (pd PostgresDialect) CurrentDatabaseSQL() string {
+	return "SELECT current_database();"
+}
+
+// RepeatableTable returns the table with checksums of applied repeatable migrations.
+func (pd PostgresDialect) RepeatableTable() string {
+	return pd.migrationTable + "_repeatable"
+}
+
+func (pd PostgresDialect) CreateRepeatableTableSQL() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			name TEXT NOT NULL
+				CONSTRAINT %s
+					PRIMARY KEY,
+			checksum TEXT NOT NULL,
+			apply_time INTEGER NOT NULL
+            );`, pd.RepeatableTable(), pd.RepeatableTable()+"_pkey")
+}
+
+func (pd PostgresDialect) RepeatableChecksumsSQL() string {
+	return fmt.Sprintf("SELECT name, checksum FROM %s;", pd.RepeatableTable())
+}
+
+func (pd PostgresDialect) SaveRepeatableChecksumSQL() string {
+	return fmt.Sprintf(`INSERT INTO %s (name, checksum, apply_time) VALUES ($1, $2, $3)
+ON CONFLICT (name) DO UPDATE SET checksum = EXCLUDED.checksum, apply_time = EXCLUDED.apply_time;`, pd.RepeatableTable())
+}
+
+// DownScriptsTable returns the table with down statements of applied SQL migrations.
+func (pd PostgresDialect) DownScriptsTable() string {
+	return pd.migrationTable + "_down"
+}
+
+func (pd PostgresDialect) CreateDownScriptsTableSQL() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			version TEXT NOT NULL
+				CONSTRAINT %s
+					PRIMARY KEY,
+			statements TEXT NOT NULL,
+			use_tx BOOLEAN NOT NULL
+            );`, pd.DownScriptsTable(), pd.DownScriptsTable()+"_pkey")
+}
+
+func (pd PostgresDialect) SaveDownScriptSQL() string {
+	return fmt.Sprintf(`INSERT INTO %s (version, statements, use_tx) VALUES ($1, $2, $3)
+ON CONFLICT (version) DO UPDATE SET statements = EXCLUDED.statements, use_tx = EXCLUDED.use_tx;`, pd.DownScriptsTable())
+}
+
+func (pd PostgresDialect) DownScriptSQL() string {
+	return fmt.Sprintf("SELECT statements, use_tx FROM %s WHERE version=$1;", pd.DownScriptsTable())
+}
+
+func (pd PostgresDialect) DeleteDownScriptSQL() string {
+	return fmt.Sprintf("DELETE FROM %s WHERE version=$1;", pd.DownScriptsTable())
+}
+
+// BatchesTable returns the table with batch numbers of applied versions.
+func (pd PostgresDialect) BatchesTable() string {
+	return pd.migrationTable + "_batch"
+}
+
+func (pd PostgresDialect) CreateBatchesTableSQL() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			version TEXT NOT NULL
+				CONSTRAINT %s
+					PRIMARY KEY,
+			batch INTEGER NOT NULL
+            );`, pd.BatchesTable(), pd.BatchesTable()+"_pkey")
+}
+
+func (pd PostgresDialect) NextBatchSQL() string {
+	return fmt.Sprintf("SELECT COALESCE(MAX(batch), 0) + 1 FROM %s;", pd.BatchesTable())
+}
+
+func (pd PostgresDialect) SaveBatchSQL() string {
+	return fmt.Sprintf(`INSERT INTO %s (version, batch) VALUES ($1, $2)
+ON CONFLICT (version) DO UPDATE SET batch = EXCLUDED.batch;`, pd.BatchesTable())
+}
+
+func (pd PostgresDialect) BatchesSQL() string {
+	return fmt.Sprintf("SELECT version, batch FROM %s;", pd.BatchesTable())
+}
+
+func (pd PostgresDialect) DeleteBatchSQL() string {
+	return fmt.Sprintf("DELETE FROM %s WHERE version=$1;", pd.BatchesTable())
+}
+
+func (pd PostgresDialect) ServiceTables() []string {
+	return []string{pd.migrationTable, pd.RepeatableTable(), pd.DownScriptsTable(), pd.BatchesTable()}
+}
+
+func (pd PostgresDialect) TryLockSQL() string {
+	return "SELECT pg_try_advisory_lock(hashtext($1));"
+}
+
+func (pd PostgresDialect) UnlockSQL() string {
+	return "SELECT pg_advisory_unlock(hashtext($1));"
+}
+
+const pgUserSchemasCond = "NOT IN ('pg_catalog', 'information_schema') AND %s NOT LIKE 'pg_toast%%'"
+
+func (pd PostgresDialect) MigrationTable() string {
+	return pd.migrationTable
+}
+
+func (pd PostgresDialect) SequencesSQL() string {
+	return fmt.Sprintf(`
+SELECT
+    quote_ident(sequence_schema), quote_ident(sequence_name), data_type, start_value, increment
+FROM
+    information_schema.sequences
+WHERE
+    sequence_schema `+pgUserSchemasCond+`
+ORDER BY 1, 2;
+`, "sequence_schema")
+}
+
+func (pd PostgresDialect) TablesSQL() string {
+	return fmt.Sprintf(`
+SELECT
+    quote_ident(n.nspname), quote_ident(c.relname)
+FROM
+    pg_catalog.pg_class c
+    JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE
+    c.relkind IN ('r', 'p')
+AND
+    n.nspname `+pgUserSchemasCond+`
+ORDER BY 1, 2;
+`, "n.nspname")
+}
+
+func (pd PostgresDialect) ColumnsSQL() string {
+	return fmt.Sprintf(`
+SELECT
+    quote_ident(n.nspname), quote_ident(c.relname), quote_ident(a.attname),
+    pg_catalog.format_type(a.atttypid, a.atttypmod), a.attnotnull,
+    COALESCE(pg_catalog.pg_get_expr(d.adbin, d.adrelid), '')
+FROM
+    pg_catalog.pg_attribute a
+    JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+    JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+    LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE
+    c.relkind IN ('r', 'p')
+AND
+    a.attnum > 0 AND NOT a.attisdropped
+AND
+    n.nspname `+pgUserSchemasCond+`
+ORDER BY n.nspname, c.relname, a.attnum;
+`, "n.nspname")
+}
+
+func (pd PostgresDialect) ConstraintsSQL() string {
+	return fmt.Sprintf(`
+SELECT
+    quote_ident(n.nspname), quote_ident(c.relname), quote_ident(con.conname),
+    con.contype::text, pg_catalog.pg_get_constraintdef(con.oid, true)
+FROM
+    pg_catalog.pg_constraint con
+    JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+    JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE
+    n.nspname `+pgUserSchemasCond+`
+ORDER BY 1, 2, 3;
+`, "n.nspname")
+}
+
+func (pd PostgresDialect) IndexesSQL() string {
+	return fmt.Sprintf(`
+SELECT
+    quote_ident(n.nspname), quote_ident(t.relname), quote_ident(i.relname), pg_catalog.pg_get_indexdef(i.oid)
+FROM
+    pg_catalog.pg_index x
+    JOIN pg_catalog.pg_class i ON i.oid = x.indexrelid
+    JOIN pg_catalog.pg_class t ON t.oid = x.indrelid
+    JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+WHERE
+    n.nspname `+pgUserSchemasCond+`
+AND
+    NOT EXISTS (SELECT 1 FROM pg_catalog.pg_constraint con WHERE con.conindid = x.indexrelid AND con.contype IN ('p', 'u', 'x'))
+ORDER BY 1, 2, 3;
+`, "n.nspname")
+}
+
+func (pd PostgresDialect) ViewsSQL() string {
+	return fmt.Sprintf(`
+SELECT
+    quote_ident(schemaname), quote_ident(viewname), definition
+FROM
+    pg_catalog.pg_views
+WHERE
+    schemaname `+pgUserSchemasCond+`
+ORDER BY 1, 2;
+`, "schemaname")
+}
+
+func (pd PostgresDialect) CurrentSchemaSQL() string {
+	return "SELECT current_schema();"
+}
+
+func (pd PostgresDialect) CreateSchemaSQL(name string) string {
+	return fmt.Sprintf("CREATE SCHEMA %s;", name)
+}
+
+func (pd PostgresDialect) SetLocalSearchPathSQL(name string) string {
+	return fmt.Sprintf("SET LOCAL search_path TO %s;", name)
 }
@@ -18,6 +18,51 @@ type SQLDialect interface {
 	DropFkSQL(tableName string, fkName string) string
 	DropTableSQL(tableName string) string
 	MigrationsHistorySQL() string
+	CurrentDatabaseSQL() string
+	RepeatableTable() string
+	CreateRepeatableTableSQL() string
+	RepeatableChecksumsSQL() string
+	SaveRepeatableChecksumSQL() string
+	// DownScriptsTable stores down statements of applied SQL migrations to revert them when files are missing.
+	DownScriptsTable() string
+	CreateDownScriptsTableSQL() string
+	SaveDownScriptSQL() string
+	DownScriptSQL() string
+	DeleteDownScriptSQL() string
+	// BatchesTable stores number of up invocation which applied the version.
+	BatchesTable() string
+	CreateBatchesTableSQL() string
+	NextBatchSQL() string
+	SaveBatchSQL() string
+	BatchesSQL() string
+	DeleteBatchSQL() string
+	// ServiceTables returns migrations table and tables storing data of its migrations.
+	ServiceTables() []string
+	// TryLockSQL takes session level lock of migrations table without waiting, returns whether lock is taken.
+	TryLockSQL() string
+	UnlockSQL() string
+	SchemaIntrospector
+}
+
+// SchemaIntrospector provides queries for the schema dump, every query
+// must return already quoted identifiers in deterministic order.
+type SchemaIntrospector interface {
+	MigrationTable() string
+	// SequencesSQL columns: schema, name, data type, start value, increment.
+	SequencesSQL() string
+	// TablesSQL columns: schema, name.
+	TablesSQL() string
+	// ColumnsSQL columns: schema, table, name, type, not null, default.
+	ColumnsSQL() string
+	// ConstraintsSQL columns: schema, table, name, type, definition.
+	ConstraintsSQL() string
+	// IndexesSQL columns: schema, table, name, definition. Indexes backing constraints are skipped.
+	IndexesSQL() string
+	// ViewsSQL columns: schema, name, definition.
+	ViewsSQL() string
+	CurrentSchemaSQL() string
+	CreateSchemaSQL(name string) string
+	SetLocalSearchPathSQL(name string) string
 }
 
 func InitDialect(v, migrationTable string) (SQLDialect, error) {
@@ -0,0 +1,148 @@
+// Package tracing creates OpenTelemetry spans of runs, migrations, statements and repository queries.
+//
+// Spans are children of the span carried by the context passed to Start, so library callers
+// may attach runs to their own traces.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/tweety53/gomigrate"
+
+const (
+	ActionKey    = attribute.Key("gomigrate.action")
+	VersionKey   = attribute.Key("gomigrate.version")
+	DirectionKey = attribute.Key("gomigrate.direction")
+	TxModeKey    = attribute.Key("gomigrate.tx_mode")
+	StatementKey = attribute.Key("db.statement")
+)
+
+var (
+	mu sync.Mutex
+	// provider is nil until set, global otel provider is used then.
+	provider trace.TracerProvider
+)
+
+// SetTracerProvider sets provider of gomigrate spans, nil restores global otel provider.
+func SetTracerProvider(tp trace.TracerProvider) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	provider = tp
+}
+
+type Span struct {
+	span trace.Span
+}
+
+// Start starts span as a child of the span of ctx and returns ctx carrying the new span.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, *Span) {
+	mu.Lock()
+	tp := provider
+	mu.Unlock()
+
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	ctx, span := tp.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+
+	return ctx, &Span{span: span}
+}
+
+// End records err if any and ends span. Error messages may quote failed statements,
+// so they are recorded with literals replaced.
+func (s *Span) End(err error) {
+	if err != nil {
+		msg := Sanitize(err.Error())
+		s.span.RecordError(errors.New(msg))
+		s.span.SetStatus(codes.Error, msg)
+	}
+
+	s.span.End()
+}
+
+var (
+	// dollar quote tag is matched at the start of the rest of statement, e.g. $$ or $body$
+	matchDollarTag = regexp.MustCompile(`^\$(?:[A-Za-z_][A-Za-z0-9_]*)?\$`)
+	// placeholders like $1 are kept
+	matchNumbers = regexp.MustCompile(`(^|[^\w$])\d+(?:\.\d+)?\b`)
+)
+
+// Sanitize replaces literals of SQL statement with '?' so span does not carry data.
+func Sanitize(statement string) string {
+	return matchNumbers.ReplaceAllString(replaceQuoted(statement), "${1}?")
+}
+
+// replaceQuoted replaces dollar quoted bodies and string literals, regexp cannot match closing tag equal
+// to the opening one, e.g. $body$...$body$, or tell E'...' backslash escapes from the end of literal.
+func replaceQuoted(statement string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(statement); {
+		c := statement[i]
+		prevIdent := i > 0 && isIdentChar(statement[i-1])
+
+		if c == '$' && !prevIdent {
+			if tag := matchDollarTag.FindString(statement[i:]); tag != "" {
+				b.WriteString(tag + "?" + tag)
+
+				body := statement[i+len(tag):]
+				end := strings.Index(body, tag)
+				if end < 0 {
+					return b.String()
+				}
+
+				i += len(tag) + end + len(tag)
+
+				continue
+			}
+		}
+
+		if c == '\'' {
+			escapes := i > 0 && (statement[i-1] == 'E' || statement[i-1] == 'e') && (i < 2 || !isIdentChar(statement[i-2]))
+			b.WriteString("'?'")
+			i = literalEnd(statement, i+1, escapes)
+
+			continue
+		}
+
+		b.WriteByte(c)
+		i++
+	}
+
+	return b.String()
+}
+
+// literalEnd returns position after the end of string literal starting at i, backslash escapes
+// quote in E'...' literal.
+func literalEnd(statement string, i int, escapes bool) int {
+	for i < len(statement) {
+		switch {
+		case escapes && statement[i] == '\\':
+			i += 2
+		case statement[i] == '\'' && i+1 < len(statement) && statement[i+1] == '\'':
+			i += 2
+		case statement[i] == '\'':
+			return i + 1
+		default:
+			i++
+		}
+	}
+
+	return len(statement)
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStart(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer SetTracerProvider(nil)
+
+	ctx, run := Start(context.Background(), "run", ActionKey.String("up"))
+	_, first := Start(ctx, "first")
+	first.End(nil)
+	_, second := Start(ctx, "second")
+	second.End(errors.New(`failed to execute SQL query "INSERT INTO users VALUES ('secret', 42);"`))
+	run.End(nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	require.Equal(t, "first", spans[0].Name)
+	require.Equal(t, "second", spans[1].Name)
+	require.Equal(t, "run", spans[2].Name)
+	require.Equal(t, spans[2].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	require.Equal(t, spans[2].SpanContext.SpanID(), spans[1].Parent.SpanID())
+	require.False(t, spans[2].Parent.IsValid())
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+	require.Equal(t, `failed to execute SQL query "INSERT INTO users VALUES ('?', ?);"`, spans[1].Status.Description)
+	require.Len(t, spans[1].Events, 1)
+	require.NotContains(t, fmt.Sprint(spans[1].Events[0].Attributes), "secret")
+
+	// span of the caller is the parent
+	_, next := Start(ctx, "next")
+	next.End(nil)
+	require.Equal(t, spans[2].SpanContext.SpanID(), exporter.GetSpans()[3].Parent.SpanID())
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		want      string
+	}{
+		{
+			name:      "string literals",
+			statement: "INSERT INTO users (name, note) VALUES ('john', 'it''s me');",
+			want:      "INSERT INTO users (name, note) VALUES ('?', '?');",
+		},
+		{
+			name:      "numbers",
+			statement: "UPDATE t1 SET amount = 10.5 WHERE id = 42;",
+			want:      "UPDATE t1 SET amount = ? WHERE id = ?;",
+		},
+		{
+			name:      "dollar quoted body",
+			statement: "CREATE FUNCTION f() RETURNS int AS $$ SELECT 'secret', 1 $$ LANGUAGE sql;",
+			want:      "CREATE FUNCTION f() RETURNS int AS $$?$$ LANGUAGE sql;",
+		},
+		{
+			name:      "tagged dollar quoted body",
+			statement: "DO $body$ BEGIN RAISE NOTICE '$$secret$$ 1'; END $body$; SELECT $fn$x$fn$;",
+			want:      "DO $body$?$body$; SELECT $fn$?$fn$;",
+		},
+		{
+			name:      "backslash escapes",
+			statement: `INSERT INTO t1 (note, code) VALUES (E'it\'s \\ secret', 'x\'), 'y';`,
+			want:      "INSERT INTO t1 (note, code) VALUES (E'?', '?'), '?';",
+		},
+		{
+			name:      "unterminated dollar quoted body",
+			statement: "CREATE FUNCTION f() AS $$ SELECT 'secret'",
+			want:      "CREATE FUNCTION f() AS $$?$$",
+		},
+		{
+			name:      "placeholders",
+			statement: "DELETE FROM migration WHERE version=$1 AND apply_time>0;",
+			want:      "DELETE FROM migration WHERE version=$1 AND apply_time>?;",
+		},
+		{
+			name:      "no literals",
+			statement: "ALTER TABLE some_table ADD COLUMN col_2 int;",
+			want:      "ALTER TABLE some_table ADD COLUMN col_2 int;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Sanitize(tt.statement))
+		})
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"os"
 
 	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/policy"
 	"github.com/tweety53/gomigrate/internal/repo"
 	"github.com/tweety53/gomigrate/internal/sqldialect"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
 	"gopkg.in/yaml.v2"
 )
 
@@ -18,6 +21,47 @@ type GoMigrateConfig struct {
 	MigrationTable string `yaml:"gomigrate_migration_table"`
 	SQLDialect     string `yaml:"gomigrate_sql_dialect"`
 	DataSourceName string `yaml:"gomigrate_dsn"`
+	Environment    string `yaml:"gomigrate_env"`
+	SchemaFile     string `yaml:"gomigrate_schema_file"`
+	SchemaAutoDump bool   `yaml:"gomigrate_schema_auto_dump"`
+	SeedsPath      string `yaml:"gomigrate_seeds_path"`
+	SeedsTable     string `yaml:"gomigrate_seeds_table"`
+	TablePrefix    string `yaml:"gomigrate_table_prefix"`
+	// Vars are substituted into ${var} placeholders of SQL migrations declaring '-- +gomigrate Substitute'.
+	Vars map[string]string `yaml:"gomigrate_vars"`
+	// LintRules override lint rules severities: off, warning or error.
+	LintRules map[string]string `yaml:"gomigrate_lint_rules"`
+	// MetricsFile is node_exporter textfile metrics are written to after run.
+	MetricsFile string `yaml:"gomigrate_metrics_file"`
+	// MetricsPushURL is Pushgateway-compatible endpoint metrics are pushed to after run.
+	MetricsPushURL string `yaml:"gomigrate_metrics_push_url"`
+	// ServeToken authenticates POST /up of serve action, GOMIGRATE_SERVE_TOKEN env is used if empty.
+	ServeToken string `yaml:"gomigrate_serve_token"`
+	// Protection contains policies keyed by environment name.
+	Protection map[string]*policy.Policy `yaml:"gomigrate_protection"`
+}
+
+// EnvironmentPolicy returns protection policy for the configured environment or nil if there is no one.
+func (c *GoMigrateConfig) EnvironmentPolicy() *policy.Policy {
+	return c.Protection[c.Environment]
+}
+
+// SeedsPathOrDefault returns seeds directory, "seeds" if not configured.
+func (c *GoMigrateConfig) SeedsPathOrDefault() string {
+	if c.SeedsPath == "" {
+		return "seeds"
+	}
+
+	return c.SeedsPath
+}
+
+// SeedsTableOrDefault returns seeds tracking table, "<migration table>_seed" if not configured.
+func (c *GoMigrateConfig) SeedsTableOrDefault() string {
+	if c.SeedsTable == "" {
+		return c.MigrationTable + "_seed"
+	}
+
+	return c.SeedsTable
 }
 
 func (c *GoMigrateConfig) IsValid() bool {
@@ -49,6 +93,12 @@ func BuildFromArgs(
 	compact bool,
 	sqlDialect string,
 	dataSourceName string,
+	environment string,
+	schemaFile string,
+	schemaAutoDump bool,
+	seedsPath string,
+	metricsFile string,
+	metricsPushURL string,
 ) *GoMigrateConfig {
 	return &GoMigrateConfig{
 		MigrationsPath: migrationsPath,
@@ -56,17 +106,33 @@ func BuildFromArgs(
 		Compact:        compact,
 		SQLDialect:     sqlDialect,
 		DataSourceName: dataSourceName,
+		Environment:    environment,
+		SchemaFile:     schemaFile,
+		SchemaAutoDump: schemaAutoDump,
+		SeedsPath:      seedsPath,
+		MetricsFile:    metricsFile,
+		MetricsPushURL: metricsPushURL,
 	}
 }
 
 func Validate(conf *GoMigrateConfig, db *sql.DB) error {
 	if _, err := os.Stat(conf.MigrationsPath); err != nil {
-		return errors.Wrap(err, "gomigrate config: bad migrations path")
+		return configError(errors.Wrap(err, "gomigrate config: bad migrations path"))
 	}
 
 	dialect, err := sqldialect.InitDialect(conf.SQLDialect, conf.MigrationTable)
 	if err != nil {
-		return errors.Wrap(err, "gomigrate config: unknown sql dialect")
+		return configError(errors.Wrap(err, "gomigrate config: unknown sql dialect"))
+	}
+
+	for env, p := range conf.Protection {
+		if p == nil {
+			continue
+		}
+
+		if err := p.Validate(); err != nil {
+			return configError(errors.Wrapf(err, "gomigrate config: bad protection policy for %s env", env))
+		}
 	}
 
 	mRepo := repo.NewMigrationsRepository(db, dialect)
@@ -77,3 +143,8 @@ func Validate(conf *GoMigrateConfig, db *sql.DB) error {
 
 	return nil
 }
+
+// configError sets exit code to errors of invalid configuration.
+func configError(err error) error {
+	return &errorsInternal.GoMigrateError{Err: err, ExitCode: exitcode.ConfigError}
+}
@@ -10,6 +10,7 @@ var (
 	ErrInvalidActionParamsType = errors.New("invalid action params type")
 	ErrInvalidVersionFormat    = errors.New("invalid version format")
 	ErrConfigNotValidated      = errors.New("config not validated, please add config.Validate() call before Run()")
+	ErrCancelled               = errors.New("action was cancelled by user")
 )
 
 type GoMigrateError struct {
@@ -25,6 +26,10 @@ func (e *GoMigrateError) Error() string {
 	return e.Err.Error()
 }
 
+func (e *GoMigrateError) Unwrap() error {
+	return e.Err
+}
+
 func ErrorExitCode(err error) exitcode.ExitCode {
 	if err == nil {
 		return exitcode.OK
@@ -2,9 +2,23 @@ package exitcode
 
 type ExitCode int
 
+// Exit codes are documented in README, values must not be changed.
+//
 //nolint:gochecknoglobals // because its like https://github.com/leighmcculloch/gochecknoglobals#exceptions
 const (
-	OK          ExitCode = 0
-	Unspecified ExitCode = 1
-	IoErr       ExitCode = 74
+	OK              ExitCode = 0
+	Unspecified     ExitCode = 1
+	LintFailed      ExitCode = 3
+	CheckFailed     ExitCode = 4
+	MigrationFailed ExitCode = 5
+	UserCancelled   ExitCode = 6
+	VersionNotFound ExitCode = 7
+	WaitTimeout     ExitCode = 8
+	DirtyVersion    ExitCode = 9
+	Irreversible    ExitCode = 65
+	ConnectionError ExitCode = 69
+	IoErr           ExitCode = 74
+	LockConflict    ExitCode = 75
+	PolicyViolation ExitCode = 77
+	ConfigError     ExitCode = 78
 )
@@ -0,0 +1,268 @@
+package gomigrate
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/action"
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/policy"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/service"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+	"github.com/tweety53/gomigrate/pkg/config"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+// SchemaPolicy is what EnsureSchema does when database does not match migrations of the code.
+type SchemaPolicy string
+
+const (
+	SchemaFail        SchemaPolicy = "fail"
+	SchemaWarn        SchemaPolicy = "warn"
+	SchemaAutoMigrate SchemaPolicy = "auto-migrate"
+)
+
+var (
+	ErrDBBehindCode            = errors.New("database is behind code, pending migrations")
+	ErrDBAheadOfCode           = errors.New("database is ahead of code, applied versions are unknown")
+	ErrUnsupportedSchemaPolicy = errors.New("unsupported schema policy")
+)
+
+// SchemaPolicies are policies of database behind code (pending migrations) and ahead of code
+// (applied versions unknown to code, e.g. after rollback deploy), SchemaFail is used if not set.
+// SchemaAutoMigrate reverts unknown versions with their down scripts stored at apply time.
+type SchemaPolicies struct {
+	Behind SchemaPolicy
+	Ahead  SchemaPolicy
+}
+
+// schemaLockPollInterval is the interval of migrations lock polling while it is held by another process.
+//
+//nolint:gochecknoglobals // overridden in tests
+var schemaLockPollInterval = time.Second
+
+// EnsureSchema compares registered and migrations path migrations with migrations table, it is meant
+// to be called at app startup. For SchemaAutoMigrate policies database is synced under migrations lock,
+// if it is held by another process, e.g. other replica, it is waited for until ctx is done.
+func EnsureSchema(ctx context.Context, db *sql.DB, config *config.GoMigrateConfig, policies SchemaPolicies) error {
+	log.SetVerbose(!config.Compact)
+	if !config.IsValid() {
+		return &errorsInternal.GoMigrateError{Err: errorsInternal.ErrConfigNotValidated, ExitCode: exitcode.ConfigError}
+	}
+
+	behind, ahead := policies.Behind, policies.Ahead
+	if behind == "" {
+		behind = SchemaFail
+	}
+	if ahead == "" {
+		ahead = SchemaFail
+	}
+
+	for _, p := range []SchemaPolicy{behind, ahead} {
+		if p != SchemaFail && p != SchemaWarn && p != SchemaAutoMigrate {
+			return &errorsInternal.GoMigrateError{Err: errors.Wrap(ErrUnsupportedSchemaPolicy, string(p)), ExitCode: exitcode.ConfigError}
+		}
+	}
+
+	migrationsSvc, dialect, err := newMigrationService(db, config)
+	if err != nil {
+		return err
+	}
+
+	unknown, pending, err := schemaDiff(migrationsSvc)
+	if err != nil {
+		return err
+	}
+
+	var autoMigrate bool
+	for _, d := range []struct {
+		policy SchemaPolicy
+		err    error
+	}{
+		{policy: ahead, err: aheadError(unknown)},
+		{policy: behind, err: behindError(pending)},
+	} {
+		switch {
+		case d.err == nil:
+		case d.policy == SchemaFail:
+			return &errorsInternal.GoMigrateError{Err: d.err, ExitCode: exitcode.CheckFailed}
+		case d.policy == SchemaWarn:
+			log.Warnf("%v\n", d.err)
+		default:
+			autoMigrate = true
+		}
+	}
+
+	if !autoMigrate {
+		return nil
+	}
+
+	lock, err := lockWhenFree(ctx, db, dialect)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	// database may be synced by another process while lock was waited for
+	unknown, pending, err = schemaDiff(migrationsSvc)
+	if err != nil {
+		return err
+	}
+
+	if ahead == SchemaAutoMigrate && len(unknown) > 0 {
+		if err := revertUnknown(ctx, config, migrationsSvc, unknown); err != nil {
+			return err
+		}
+	}
+
+	if behind == SchemaAutoMigrate && len(pending) > 0 {
+		return run(ctx, "up", db, config, nil, false)
+	}
+
+	return nil
+}
+
+// EnsureSchemaFS is EnsureSchema with migrations read from fsys, e.g. embed.FS compiled into the app,
+// instead of config.MigrationsPath. Migrations are expected in the root of fsys, use fs.Sub otherwise.
+// Go migrations registered with AddMigration are used as well.
+func EnsureSchemaFS(ctx context.Context, db *sql.DB, config *config.GoMigrateConfig, fsys fs.FS, policies SchemaPolicies) error {
+	dir, err := ioutil.TempDir("", "gomigrate")
+	if err != nil {
+		return &errorsInternal.GoMigrateError{Err: errors.Wrap(err, "cannot create migrations dir"), ExitCode: exitcode.IoErr}
+	}
+	defer os.RemoveAll(dir)
+
+	if err := copyFS(fsys, dir); err != nil {
+		return &errorsInternal.GoMigrateError{Err: errors.Wrap(err, "cannot copy migrations"), ExitCode: exitcode.IoErr}
+	}
+
+	conf := *config
+	conf.MigrationsPath = dir
+
+	return EnsureSchema(ctx, db, &conf, policies)
+}
+
+// copyFS copies files of fsys to dir, migrations are collected from files of migrations path.
+func copyFS(fsys fs.FS, dir string) error {
+	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755) //nolint:gosec
+		}
+
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+
+		return ioutil.WriteFile(target, content, 0600)
+	})
+}
+
+// schemaDiff returns applied versions unknown to code and pending migrations.
+func schemaDiff(svc *service.MigrationService) ([]string, migration.Migrations, error) {
+	unknown, err := svc.GetUnknownAppliedVersions()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pending, err := svc.GetNewMigrations()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return unknown, pending, nil
+}
+
+func aheadError(unknown []string) error {
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	return errors.Wrap(ErrDBAheadOfCode, strings.Join(unknown, ", "))
+}
+
+func behindError(pending migration.Migrations) error {
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return errors.Wrapf(ErrDBBehindCode, "%d pending", len(pending))
+}
+
+// lockWhenFree takes migrations lock, it is waited for while held by another process until ctx is done.
+func lockWhenFree(ctx context.Context, db *sql.DB, dialect sqldialect.SQLDialect) (*repo.Lock, error) {
+	for {
+		lock, err := repo.TryLock(ctx, db, dialect)
+		if !errors.Is(err, repo.ErrLocked) {
+			return lock, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, &errorsInternal.GoMigrateError{Err: errors.Wrap(err, ctx.Err().Error()), ExitCode: exitcode.LockConflict}
+		case <-time.After(schemaLockPollInterval):
+		}
+	}
+}
+
+// revertUnknown reverts unknown versions, they must be the last applied ones.
+func revertUnknown(ctx context.Context, config *config.GoMigrateConfig, svc *service.MigrationService, unknown []string) error {
+	records, err := svc.MigrationsRepo.GetMigrationsHistory(0)
+	if err != nil {
+		return err
+	}
+
+	isUnknown := make(map[string]struct{}, len(unknown))
+	for _, v := range unknown {
+		isUnknown[v] = struct{}{}
+	}
+
+	n := 0
+	for _, record := range records {
+		if _, ok := isUnknown[record.Version]; !ok {
+			break
+		}
+		n++
+	}
+
+	if n != len(unknown) {
+		return &errorsInternal.GoMigrateError{
+			Err:      errors.Wrap(aheadError(unknown), "unknown versions are not the last applied ones"),
+			ExitCode: exitcode.CheckFailed,
+		}
+	}
+
+	// app startup cannot answer prompts, so down is run directly and policy needing typed database name
+	// refuses it
+	args := []string{strconv.Itoa(n)}
+	guard := policy.NewGuard(config.Environment, config.EnvironmentPolicy(), svc.DBOperationRepo.CurrentDatabaseName)
+	if err := guard.CheckNonInteractive("down", args); err != nil {
+		return err
+	}
+
+	params := new(action.DownActionParams)
+	if err := params.ValidateAndFill(args); err != nil {
+		return err
+	}
+
+	err = action.NewDownAction(svc).Run(ctx, params)
+	exportMetrics("down", err, svc, config)
+
+	return err
+}
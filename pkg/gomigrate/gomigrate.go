@@ -1,50 +1,119 @@
 package gomigrate
 
 import (
+	"context"
 	"database/sql"
 	"runtime"
+	"time"
 
 	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tweety53/gomigrate/internal/action"
+	"github.com/tweety53/gomigrate/internal/lint"
 	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/metrics"
 	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/policy"
 	"github.com/tweety53/gomigrate/internal/repo"
 	"github.com/tweety53/gomigrate/internal/service"
 	"github.com/tweety53/gomigrate/internal/sqldialect"
+	"github.com/tweety53/gomigrate/internal/tracing"
 	"github.com/tweety53/gomigrate/pkg/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	HookPoint    = migration.HookPoint
+	HookEvent    = migration.HookEvent
+	HookFn       = migration.HookFn
+	HookExecutor = migration.HookExecutor
+	Direction    = migration.Direction
+
+	Event        = migration.Event
+	EventType    = migration.EventType
+	TxMode       = migration.TxMode
+	Observer     = migration.Observer
+	ObserverFunc = migration.ObserverFunc
+)
+
+const (
+	HookBeforeAll  = migration.HookBeforeAll
+	HookAfterAll   = migration.HookAfterAll
+	HookBeforeEach = migration.HookBeforeEach
+	HookAfterEach  = migration.HookAfterEach
+
+	EventRunStarted        = migration.EventRunStarted
+	EventMigrationStarted  = migration.EventMigrationStarted
+	EventStatementExecuted = migration.EventStatementExecuted
+	EventMigrationApplied  = migration.EventMigrationApplied
+	EventMigrationReverted = migration.EventMigrationReverted
+	EventMigrationFailed   = migration.EventMigrationFailed
+	EventRunFinished       = migration.EventRunFinished
+
+	TxModeNone   = migration.TxModeNone
+	TxModeSingle = migration.TxModeSingle
+	TxModeAtomic = migration.TxModeAtomic
+)
+
+var (
+	DirectionUp   = migration.DirectionUp
+	DirectionDown = migration.DirectionDown
+)
+
+var (
+	// ErrIrreversible may be returned from Go migration down func to refuse reverting it.
+	ErrIrreversible = migration.ErrIrreversible
+	// IrreversibleSafeDown and IrreversibleDown may be passed as down func of irreversible migration
+	// (as well as nil), so down, redo and to refuse to revert it before touching anything.
+	IrreversibleSafeDown = migration.IrreversibleSafeDown
+	IrreversibleDown     = migration.IrreversibleDown
 )
 
 func Run(a string, db *sql.DB, config *config.GoMigrateConfig, args []string) error {
+	return RunContext(context.Background(), a, db, config, args)
+}
+
+// RunContext is Run with ctx passed to database calls, run, migration and statement spans are started
+// as children of span of ctx.
+// Actions changing database are run under global migrations lock, error with LockConflict exit code
+// is returned if it is held by another process.
+func RunContext(ctx context.Context, a string, db *sql.DB, config *config.GoMigrateConfig, args []string) error {
+	return run(ctx, a, db, config, args, true)
+}
+
+// run runs action a, lock tells whether global migrations lock is taken for actions changing database,
+// it is false when the lock is already held by the caller.
+func run(ctx context.Context, a string, db *sql.DB, config *config.GoMigrateConfig, args []string, lock bool) error {
 	log.SetVerbose(!config.Compact)
 	if !config.IsValid() {
-		return errorsInternal.ErrConfigNotValidated
+		return &errorsInternal.GoMigrateError{Err: errorsInternal.ErrConfigNotValidated, ExitCode: exitcode.ConfigError}
 	}
 
-	dialect, err := sqldialect.InitDialect(config.SQLDialect, config.MigrationTable)
+	migrationsSvc, dialect, err := newMigrationService(db, config)
 	if err != nil {
 		return err
 	}
 
-	migrationsSvc := service.NewMigrationService(
-		db,
-		repo.NewMigrationsRepository(db, dialect),
-		repo.NewDBOperationsRepository(db, dialect),
-		&migration.MigrationsCollector{},
-		config.MigrationsPath)
-
 	var (
 		act    action.Action
 		params action.Params
 	)
 	switch a {
+	case "check":
+		act = action.NewCheckAction(migrationsSvc)
+		params = new(action.CheckActionParams)
 	case "create":
-		act = action.NewCreateAction(config.MigrationsPath)
+		act = action.NewCreateAction(config.MigrationsPath, migrationsSvc)
 		params = new(action.CreateActionParams)
 	case "down":
 		act = action.NewDownAction(migrationsSvc)
 		params = new(action.DownActionParams)
+	case "dump":
+		act = action.NewDumpAction(migrationsSvc, config.SchemaFile)
+		params = new(action.DumpActionParams)
 	case "fresh":
 		act = action.NewFreshAction(migrationsSvc)
 		params = new(action.FreshActionParams)
@@ -60,12 +129,25 @@ func Run(a string, db *sql.DB, config *config.GoMigrateConfig, args []string) er
 	case "redo":
 		act = action.NewRedoAction(migrationsSvc)
 		params = new(action.RedoActionParams)
+	case "seed":
+		seedsSvc, err := newSeedsService(db, config)
+		if err != nil {
+			return err
+		}
+		act = action.NewSeedAction(seedsSvc)
+		params = new(action.SeedActionParams)
+	case "squash":
+		act = action.NewSquashAction(migrationsSvc)
+		params = new(action.SquashActionParams)
 	case "to":
 		act = action.NewToAction(migrationsSvc)
 		params = new(action.ToActionParams)
 	case "up":
 		act = action.NewUpAction(migrationsSvc)
 		params = new(action.UpActionParams)
+	case "wait":
+		act = action.NewWaitAction(migrationsSvc)
+		params = new(action.WaitActionParams)
 	default:
 		return errors.Wrap(errors.New("no such action, run with -h flag to see help"), a)
 	}
@@ -73,13 +155,216 @@ func Run(a string, db *sql.DB, config *config.GoMigrateConfig, args []string) er
 	if err := params.ValidateAndFill(args); err != nil {
 		return err
 	}
-	if err := act.Run(params); err != nil {
+
+	guard := policy.NewGuard(config.Environment, config.EnvironmentPolicy(), migrationsSvc.DBOperationRepo.CurrentDatabaseName)
+	if err := guard.Check(a, args); err != nil {
+		return err
+	}
+
+	// typed database name confirms seeds reset, so it is asked once
+	if p, ok := params.(*action.SeedActionParams); ok && guard.ConfirmsDBName(a, args) {
+		p.Confirm()
+	}
+
+	if lock && changesDatabase(a) {
+		l, err := repo.TryLock(ctx, db, dialect)
+		if err != nil {
+			return lockError(err)
+		}
+		defer l.Unlock()
+	}
+
+	err = act.Run(ctx, params)
+	if changesDatabase(a) {
+		exportMetrics(a, err, migrationsSvc, config)
+	}
+
+	return err
+}
+
+// changesDatabase reports whether action a applies, reverts or marks migrations.
+func changesDatabase(a string) bool {
+	switch a {
+	case "down", "fresh", "mark", "redo", "seed", "to", "up":
+		return true
+	}
+
+	return false
+}
+
+// lockError sets exit code to error of global migrations lock held by another process.
+func lockError(err error) error {
+	if errors.Is(err, repo.ErrLocked) {
+		return &errorsInternal.GoMigrateError{Err: err, ExitCode: exitcode.LockConflict}
+	}
+
+	return err
+}
+
+// newMigrationService returns service working with migrations of config.
+func newMigrationService(db *sql.DB, config *config.GoMigrateConfig) (*service.MigrationService, sqldialect.SQLDialect, error) {
+	dialect, err := sqldialect.InitDialect(config.SQLDialect, config.MigrationTable)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seedsDialect, err := sqldialect.InitDialect(config.SQLDialect, config.SeedsTableOrDefault())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parser := sqlParser(config)
+	migrationsSvc := service.NewMigrationService(
+		db,
+		repo.NewMigrationsRepository(db, dialect),
+		repo.NewDBOperationsRepository(db, dialect),
+		&migration.MigrationsCollector{Parser: parser},
+		config.MigrationsPath)
+	migrationsSvc.Parser = parser
+	migrationsSvc.SchemaRepo = repo.NewSchemaRepository(db, dialect, seedsDialect)
+	migrationsSvc.RepeatableRepo = repo.NewRepeatableMigrationsRepository(db, dialect)
+	if config.SchemaAutoDump {
+		migrationsSvc.SchemaFile = config.SchemaFile
+		if migrationsSvc.SchemaFile == "" {
+			migrationsSvc.SchemaFile = service.DefaultSchemaFile
+		}
+	}
+
+	hooks, err := migration.CollectHooks(config.MigrationsPath, parser)
+	if err != nil {
+		return nil, nil, err
+	}
+	migrationsSvc.Hooks = hooks
+	migrationsSvc.Observers = observers()
+
+	return migrationsSvc, dialect, nil
+}
+
+// sqlParser returns parser of SQL files using dialect and placeholders of config.
+func sqlParser(config *config.GoMigrateConfig) *migration.SQLParser {
+	return migration.NewSQLParser(config.SQLDialect, config.Vars, config.TablePrefix)
+}
+
+// MetricsRegistry returns Prometheus registry with metrics of runs: applied, reverted and failed migrations
+// counters, migrations duration histogram, pending migrations count and last successful run time.
+func MetricsRegistry() *prometheus.Registry {
+	return runMetrics.Registry()
+}
+
+// SetTracerProvider sets OpenTelemetry provider of runs, migrations, statements and repository queries spans,
+// global otel provider is used by default.
+func SetTracerProvider(tp trace.TracerProvider) {
+	tracing.SetTracerProvider(tp)
+}
+
+// exportMetrics updates pending migrations count after action a changing database and writes or pushes metrics
+// if configured, runErr is the error returned by the action.
+func exportMetrics(a string, runErr error, svc *service.MigrationService, config *config.GoMigrateConfig) {
+	if migrations, err := svc.GetPendingMigrations(); err != nil {
+		log.Errf("Cannot count pending migrations for metrics: %v\n", err)
+	} else {
+		runMetrics.SetPending(len(migrations))
+	}
+
+	// up with nothing to apply does not start a run, but the database is up-to-date as well
+	if a == "up" && runErr == nil {
+		runMetrics.SetLastSuccess(time.Now())
+	}
+
+	if config.MetricsFile != "" {
+		if err := runMetrics.WriteTextfile(config.MetricsFile); err != nil {
+			log.Errf("%v\n", err)
+		}
+	}
+
+	if config.MetricsPushURL != "" {
+		if err := runMetrics.Push(config.MetricsPushURL); err != nil {
+			log.Errf("%v\n", err)
+		}
+	}
+}
+
+// Lint checks SQL migrations for risky patterns, unlike Run it needs neither database connection
+// nor validated config.
+func Lint(config *config.GoMigrateConfig, args []string) error {
+	log.SetVerbose(!config.Compact)
+
+	linter, err := lint.NewLinter(config.LintRules)
+	if err != nil {
+		return err
+	}
+
+	params := new(action.LintActionParams)
+	if err := params.ValidateAndFill(args); err != nil {
 		return err
 	}
 
+	return action.NewLintAction(config.MigrationsPath, &migration.MigrationsCollector{Parser: sqlParser(config)}, linter).Run(context.Background(), params)
+}
+
+// WaitForDB pings database until it is ready, transient errors (connection refused, database system is starting up)
+// are retried with exponential backoff during maxWait. Errors have ConnectionError exit code.
+func WaitForDB(ctx context.Context, db *sql.DB, maxWait time.Duration) error {
+	if err := repo.WaitForDB(ctx, db, maxWait); err != nil {
+		return &errorsInternal.GoMigrateError{Err: err, ExitCode: exitcode.ConnectionError}
+	}
+
 	return nil
 }
 
+// WaitForVersion blocks until version is applied, empty or "latest" version waits for all migrations known
+// from migrations path. Use ctx deadline to limit waiting, e.g. at app startup.
+func WaitForVersion(ctx context.Context, db *sql.DB, config *config.GoMigrateConfig, version string) error {
+	log.SetVerbose(!config.Compact)
+	if !config.IsValid() {
+		return &errorsInternal.GoMigrateError{Err: errorsInternal.ErrConfigNotValidated, ExitCode: exitcode.ConfigError}
+	}
+
+	migrationsSvc, _, err := newMigrationService(db, config)
+	if err != nil {
+		return err
+	}
+
+	if version == "" {
+		version = action.WaitLatest
+	}
+
+	return action.NewWaitAction(migrationsSvc).Wait(ctx, version)
+}
+
+// runMetrics are updated by all runs, see MetricsRegistry.
+var runMetrics = metrics.New()
+
+// observers returns console output and metrics observers followed by ones added with AddObserver.
+func observers() migration.Observers {
+	return append(migration.Observers{migration.ConsoleObserver{}, runMetrics}, migration.RegisteredObservers()...)
+}
+
+// seedsObservers returns observers of seeds runs, seeds are not counted by migrations metrics.
+func seedsObservers() migration.Observers {
+	return append(migration.Observers{migration.ConsoleObserver{}}, migration.RegisteredObservers()...)
+}
+
+// newSeedsService returns service working with seeds instead of migrations.
+func newSeedsService(db *sql.DB, config *config.GoMigrateConfig) (*service.MigrationService, error) {
+	dialect, err := sqldialect.InitDialect(config.SQLDialect, config.SeedsTableOrDefault())
+	if err != nil {
+		return nil, err
+	}
+
+	seedsCollector := migration.NewSeedsCollector(config.Environment)
+	seedsCollector.Parser = sqlParser(config)
+	svc := service.NewMigrationService(
+		db,
+		repo.NewMigrationsRepository(db, dialect),
+		repo.NewDBOperationsRepository(db, dialect),
+		seedsCollector,
+		config.SeedsPathOrDefault())
+	svc.Observers = seedsObservers()
+
+	return svc, nil
+}
+
 func AddSafeMigration(up func(*sql.Tx) error, down func(*sql.Tx) error) {
 	_, filename, _, _ := runtime.Caller(1) //nolint:dogsled
 	migration.AddSafeNamedMigration(filename, up, down)
@@ -89,3 +374,23 @@ func AddMigration(up func(*sql.DB) error, down func(*sql.DB) error) {
 	_, filename, _, _ := runtime.Caller(1) //nolint:dogsled
 	migration.AddNamedMigration(filename, up, down)
 }
+
+// AddHook registers Go hook called at the given point of up, down and redo runs.
+func AddHook(point HookPoint, fn HookFn) {
+	migration.AddHook(point, fn)
+}
+
+// AddObserver registers observer notified about migrations lifecycle events of up, down, redo and to runs.
+func AddObserver(o Observer) {
+	migration.AddObserver(o)
+}
+
+func AddSafeSeed(up func(*sql.Tx) error, down func(*sql.Tx) error) {
+	_, filename, _, _ := runtime.Caller(1) //nolint:dogsled
+	migration.AddSafeNamedSeed(filename, up, down)
+}
+
+func AddSeed(up func(*sql.DB) error, down func(*sql.DB) error) {
+	_, filename, _, _ := runtime.Caller(1) //nolint:dogsled
+	migration.AddNamedSeed(filename, up, down)
+}
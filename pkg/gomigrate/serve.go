@@ -0,0 +1,97 @@
+package gomigrate
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tweety53/gomigrate/internal/log"
+	"github.com/tweety53/gomigrate/internal/policy"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/server"
+	"github.com/tweety53/gomigrate/pkg/config"
+	errorsInternal "github.com/tweety53/gomigrate/pkg/errors"
+	"github.com/tweety53/gomigrate/pkg/exitcode"
+)
+
+const (
+	defaultServeAddr = ":8080"
+	// serveTokenEnv is used if gomigrate_serve_token is not configured.
+	serveTokenEnv        = "GOMIGRATE_SERVE_TOKEN"
+	serveShutdownTimeout = 30 * time.Second
+)
+
+// Serve runs HTTP server with /status, /healthz and POST /up endpoints until SIGINT or SIGTERM,
+// args may contain listen address (:8080 by default).
+func Serve(db *sql.DB, config *config.GoMigrateConfig, args []string) error {
+	log.SetVerbose(!config.Compact)
+	if !config.IsValid() {
+		return &errorsInternal.GoMigrateError{Err: errorsInternal.ErrConfigNotValidated, ExitCode: exitcode.ConfigError}
+	}
+
+	addr := defaultServeAddr
+	if len(args) > 0 {
+		addr = args[0]
+	}
+
+	token := config.ServeToken
+	if token == "" {
+		token = os.Getenv(serveTokenEnv)
+	}
+
+	migrationsSvc, dialect, err := newMigrationService(db, config)
+	if err != nil {
+		return err
+	}
+
+	lock := func() (func() error, error) {
+		l, err := repo.TryLock(context.Background(), db, dialect)
+		if err != nil {
+			return nil, err
+		}
+
+		return l.Unlock, nil
+	}
+
+	guard := policy.NewGuard(config.Environment, config.EnvironmentPolicy(), migrationsSvc.DBOperationRepo.CurrentDatabaseName)
+	check := func(action string) error {
+		return guard.Check(action, nil)
+	}
+
+	// runs triggered by requests are cancelled after shutdown timeout only
+	runCtx, cancelRuns := context.WithCancel(context.Background())
+	defer cancelRuns()
+
+	srv := &http.Server{Addr: addr, Handler: server.New(runCtx, migrationsSvc, token, lock, check).Handler()}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	if token == "" {
+		log.Warnf("No serve token configured, POST /up is disabled.\n")
+	}
+	log.Infof("Serving on %s\n", addr)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	select {
+	case err := <-errCh:
+		return errors.Wrap(err, "gomigrate server")
+	case <-stop:
+	}
+
+	// running migrations are waited for until timeout
+	ctx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+
+	return errors.Wrap(srv.Shutdown(ctx), "gomigrate server shutdown")
+}
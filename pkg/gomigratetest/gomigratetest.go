@@ -0,0 +1,104 @@
+// Package gomigratetest checks migrations from Go tests.
+package gomigratetest
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/tweety53/gomigrate/internal/migration"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/schema"
+	"github.com/tweety53/gomigrate/internal/service"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+	"github.com/tweety53/gomigrate/pkg/config"
+)
+
+// RoundTrip applies new migrations of conf.MigrationsPath one at a time. Every applied migration
+// is reverted, schema is compared with the one before Up and migration is applied again,
+// so broken Down fails the test with the offending version. Irreversible migrations are
+// only applied. db should point to a scratch database, it is left migrated up.
+func RoundTrip(t testing.TB, db *sql.DB, conf *config.GoMigrateConfig) {
+	t.Helper()
+
+	dialect, err := sqldialect.InitDialect(conf.SQLDialect, conf.MigrationTable)
+	if err != nil {
+		t.Fatalf("gomigratetest: %v", err)
+	}
+
+	mRepo := repo.NewMigrationsRepository(db, dialect)
+	if _, err := mRepo.EnsureDBVersion(); err != nil {
+		t.Fatalf("gomigratetest: cannot check/create migrations table: %v", err)
+	}
+
+	parser := migration.NewSQLParser(conf.SQLDialect, conf.Vars, conf.TablePrefix)
+	svc := service.NewMigrationService(
+		db,
+		mRepo,
+		repo.NewDBOperationsRepository(db, dialect),
+		&migration.MigrationsCollector{Parser: parser},
+		conf.MigrationsPath)
+	seedsDialect, err := sqldialect.InitDialect(conf.SQLDialect, conf.SeedsTableOrDefault())
+	if err != nil {
+		t.Fatalf("gomigratetest: %v", err)
+	}
+	schemaRepo := repo.NewSchemaRepository(db, dialect, seedsDialect)
+
+	hooks, err := migration.CollectHooks(conf.MigrationsPath, parser)
+	if err != nil {
+		t.Fatalf("gomigratetest: %v", err)
+	}
+	runner := migration.NewRunner(hooks)
+
+	migrations, err := svc.GetNewMigrations()
+	if err != nil {
+		t.Fatalf("gomigratetest: cannot collect migrations: %v", err)
+	}
+
+	for _, m := range migrations {
+		roundTrip(t, mRepo, schemaRepo, runner, m)
+	}
+}
+
+func roundTrip(t testing.TB, mRepo repo.MigrationRepo, schemaRepo repo.SchemaRepo, runner migration.RunnerInterface, m *migration.Migration) {
+	t.Helper()
+
+	before, err := schemaRepo.GetSchema()
+	if err != nil {
+		t.Fatalf("gomigratetest: %s: cannot get schema before Up: %v", m.Version, err)
+	}
+
+	if err := m.Up(context.Background(), mRepo, runner); err != nil {
+		t.Fatalf("gomigratetest: %s: Up failed: %v", m.Version, err)
+	}
+
+	irreversible, err := m.IsIrreversible()
+	if err != nil {
+		t.Fatalf("gomigratetest: %s: %v", m.Version, err)
+	}
+
+	if irreversible {
+		t.Logf("gomigratetest: %s: irreversible, Down is not checked", m.Version)
+
+		return
+	}
+
+	if err := m.Down(context.Background(), mRepo, runner); err != nil {
+		t.Fatalf("gomigratetest: %s: Down failed: %v", m.Version, err)
+	}
+
+	after, err := schemaRepo.GetSchema()
+	if err != nil {
+		t.Fatalf("gomigratetest: %s: cannot get schema after Down: %v", m.Version, err)
+	}
+
+	if before.SQL() != after.SQL() {
+		t.Errorf("gomigratetest: %s: schema after Down differs from schema before Up, statements restoring it:\n%s",
+			m.Version, strings.Join(schema.Diff(after, before), "\n"))
+	}
+
+	if err := m.Up(context.Background(), mRepo, runner); err != nil {
+		t.Fatalf("gomigratetest: %s: Up after Down failed: %v", m.Version, err)
+	}
+}
@@ -0,0 +1,73 @@
+// +build test_integration
+
+package tests
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+	"github.com/tweety53/gomigrate/pkg/config"
+	"github.com/tweety53/gomigrate/pkg/gomigrate"
+)
+
+func Test_DownLastBatch(t *testing.T) {
+	// prepare
+	conf, err := config.BuildFromFile(downActionConfPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db := getDb(conf)
+	defer db.Close()
+	dialect, err := sqldialect.InitDialect(conf.SQLDialect, conf.MigrationTable)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = repo.NewDBOperationsRepository(db, dialect).TruncateDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	require.NoError(t, config.Validate(conf, db))
+	mRepo := repo.NewMigrationsRepository(db, dialect)
+
+	// two batches
+	require.NoError(t, gomigrate.Run("up", db, conf, []string{"1"}))
+	require.NoError(t, gomigrate.Run("up", db, conf, nil))
+
+	batches, err := mRepo.GetBatches()
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{
+		"m000000_000001_add_some_table":          1,
+		"m000000_000002_alter_some_table_column": 2,
+		"m000000_000003_add_another_table":       2,
+	}, batches)
+
+	history, err := mRepo.GetMigrationsHistory(0)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	require.NoError(t, gomigrate.Run("history", db, conf, nil))
+
+	// the last batch is reverted only, both of its migrations
+	require.NoError(t, gomigrate.Run("down", db, conf, []string{"--batch"}))
+
+	history, err = mRepo.GetMigrationsHistory(0)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, "m000000_000001_add_some_table", history[0].Version)
+
+	require.NoError(t, gomigrate.Run("down", db, conf, []string{"--batch"}))
+
+	history, err = mRepo.GetMigrationsHistory(0)
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	batches, err = mRepo.GetBatches()
+	require.NoError(t, err)
+	require.Empty(t, batches)
+}
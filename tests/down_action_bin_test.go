@@ -120,7 +120,8 @@ func Test_DownAction(t *testing.T) {
 			}
 			newTables := make([]string, 0, len(tables))
 			for i := range tables {
-				if tables[i] == dbSchemaPrefix+conf.MigrationTable {
+				if tables[i] == dbSchemaPrefix+conf.MigrationTable || tables[i] == dbSchemaPrefix+conf.MigrationTable+"_down" ||
+					tables[i] == dbSchemaPrefix+conf.MigrationTable+"_batch" {
 					continue
 				}
 
@@ -0,0 +1,114 @@
+// +build test_integration
+
+package tests
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/service"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+	"github.com/tweety53/gomigrate/pkg/config"
+	"github.com/tweety53/gomigrate/pkg/gomigrate"
+)
+
+func Test_EnsureSchema(t *testing.T) {
+	// prepare
+	conf, err := config.BuildFromFile(downActionConfPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db := getDb(conf)
+	defer db.Close()
+	dialect, err := sqldialect.InitDialect(conf.SQLDialect, conf.MigrationTable)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = repo.NewDBOperationsRepository(db, dialect).TruncateDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	require.NoError(t, config.Validate(conf, db))
+	ctx := context.Background()
+
+	// db behind code
+	err = gomigrate.EnsureSchema(ctx, db, conf, gomigrate.SchemaPolicies{})
+	require.ErrorIs(t, err, gomigrate.ErrDBBehindCode)
+
+	err = gomigrate.EnsureSchema(ctx, db, conf, gomigrate.SchemaPolicies{Behind: gomigrate.SchemaWarn})
+	require.NoError(t, err)
+
+	err = gomigrate.EnsureSchema(ctx, db, conf, gomigrate.SchemaPolicies{Behind: gomigrate.SchemaAutoMigrate})
+	require.NoError(t, err)
+
+	mRepo := repo.NewMigrationsRepository(db, dialect)
+	history, err := mRepo.GetMigrationsHistory(0)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	// db ahead of code
+	require.NoError(t, mRepo.InsertVersion("m990101_000000_unknown"))
+
+	err = gomigrate.EnsureSchema(ctx, db, conf, gomigrate.SchemaPolicies{})
+	require.ErrorIs(t, err, gomigrate.ErrDBAheadOfCode)
+
+	err = gomigrate.EnsureSchema(ctx, db, conf, gomigrate.SchemaPolicies{Ahead: gomigrate.SchemaWarn})
+	require.NoError(t, err)
+
+	// no down script is stored for version inserted by hand
+	err = gomigrate.EnsureSchema(ctx, db, conf, gomigrate.SchemaPolicies{Ahead: gomigrate.SchemaAutoMigrate})
+	require.ErrorIs(t, err, service.ErrMissingDownScript)
+
+	require.NoError(t, mRepo.SaveDownScript("m990101_000000_unknown", &repo.DownScript{UseTx: true}))
+
+	err = gomigrate.EnsureSchema(ctx, db, conf, gomigrate.SchemaPolicies{Ahead: gomigrate.SchemaAutoMigrate})
+	require.NoError(t, err)
+
+	history, err = mRepo.GetMigrationsHistory(0)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	err = gomigrate.EnsureSchema(ctx, db, conf, gomigrate.SchemaPolicies{Ahead: "revert"})
+	require.ErrorIs(t, err, gomigrate.ErrUnsupportedSchemaPolicy)
+}
+
+func Test_EnsureSchemaFS(t *testing.T) {
+	// prepare
+	conf, err := config.BuildFromFile(downActionConfPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db := getDb(conf)
+	defer db.Close()
+	dialect, err := sqldialect.InitDialect(conf.SQLDialect, conf.MigrationTable)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = repo.NewDBOperationsRepository(db, dialect).TruncateDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	require.NoError(t, config.Validate(conf, db))
+	ctx := context.Background()
+	fsys := os.DirFS(conf.MigrationsPath)
+
+	err = gomigrate.EnsureSchemaFS(ctx, db, conf, fsys, gomigrate.SchemaPolicies{})
+	require.ErrorIs(t, err, gomigrate.ErrDBBehindCode)
+
+	err = gomigrate.EnsureSchemaFS(ctx, db, conf, fsys, gomigrate.SchemaPolicies{Behind: gomigrate.SchemaAutoMigrate})
+	require.NoError(t, err)
+
+	history, err := repo.NewMigrationsRepository(db, dialect).GetMigrationsHistory(0)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+}
@@ -0,0 +1,42 @@
+// +build test_integration
+
+package tests
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tweety53/gomigrate/internal/repo"
+	"github.com/tweety53/gomigrate/internal/sqldialect"
+	"github.com/tweety53/gomigrate/pkg/config"
+	"github.com/tweety53/gomigrate/pkg/gomigratetest"
+)
+
+func Test_RoundTrip(t *testing.T) {
+	// prepare
+	conf, err := config.BuildFromFile(downActionConfPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db := getDb(conf)
+	defer db.Close()
+	dialect, err := sqldialect.InitDialect(conf.SQLDialect, conf.MigrationTable)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dboRepo := repo.NewDBOperationsRepository(db, dialect)
+	err = dboRepo.TruncateDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	gomigratetest.RoundTrip(t, db, conf)
+
+	// every migration stays applied after round trip
+	history, err := repo.NewMigrationsRepository(db, dialect).GetMigrationsHistory(0)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+}
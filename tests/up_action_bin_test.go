@@ -125,7 +125,8 @@ func Test_UpAction(t *testing.T) {
 			}
 			var newTables []string
 			for i := range tables {
-				if tables[i] == dbSchemaPrefix+conf.MigrationTable {
+				if tables[i] == dbSchemaPrefix+conf.MigrationTable || tables[i] == dbSchemaPrefix+conf.MigrationTable+"_down" ||
+					tables[i] == dbSchemaPrefix+conf.MigrationTable+"_batch" {
 					continue
 				}
 